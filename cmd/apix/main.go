@@ -8,10 +8,13 @@ import (
 
 	cf "github.com/Esa824/apix/internal/cli-forms"
 	cc "github.com/Esa824/apix/internal/cobra-commands"
+	hc "github.com/Esa824/apix/internal/http-client"
 )
 
 var (
-	cliMode bool
+	cliMode     bool
+	configDir   string
+	environment string
 )
 
 var rootCmd = &cobra.Command{
@@ -21,6 +24,25 @@ var rootCmd = &cobra.Command{
 Unlike curl's verbose syntax and complex flag management, Apix provides an 
 intuitive interface for making HTTP requests, managing authentication, 
 and handling responses with built-in formatting and error handling.`,
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		if isHelpOrCompletion(cmd) {
+			return nil
+		}
+
+		if err := hc.InitConfigDir(hc.ResolveConfigDir(configDir)); err != nil {
+			return err
+		}
+		if !isConfigCommand(cmd) {
+			migrateLegacyConfig()
+		}
+
+		if err := hc.LoadSettings(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\nUsing default settings\n", err)
+		}
+		return hc.UseEnvironment(environment)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		if cliMode {
 			cf.RunInteractiveMode()
@@ -32,15 +54,68 @@ and handling responses with built-in formatting and error handling.`,
 
 func init() {
 	rootCmd.PersistentFlags().BoolVar(&cliMode, "cli", false, "Enable interactive CLI mode")
+	rootCmd.PersistentFlags().StringVar(&configDir, "config-dir", "", "Config directory (defaults to $APIX_CONFIG_DIR or $XDG_CONFIG_HOME/apix)")
+	rootCmd.PersistentFlags().StringVar(&environment, "env", "", "Environment whose variables are substituted into {{placeholders}}")
 	rootCmd.AddCommand(cc.GetCmd)
 	rootCmd.AddCommand(cc.PostCmd)
 	rootCmd.AddCommand(cc.PutCmd)
 	rootCmd.AddCommand(cc.DeleteCmd)
+	rootCmd.AddCommand(cc.PatchCmd)
+	rootCmd.AddCommand(cc.HeadCmd)
+	rootCmd.AddCommand(cc.OptionsCmd)
+	rootCmd.AddCommand(cc.RequestCmd)
+	rootCmd.AddCommand(cc.SettingsCmd)
+	rootCmd.AddCommand(cc.EnvCmd)
+	rootCmd.AddCommand(cc.TestCmd)
+	rootCmd.AddCommand(cc.RunCmd)
+	rootCmd.AddCommand(cc.ImportCmd)
+	rootCmd.AddCommand(cc.ExportCmd)
+	rootCmd.AddCommand(cc.TemplateCmd)
+	rootCmd.AddCommand(cc.HistoryCmd)
+	rootCmd.AddCommand(cc.AuthCmd)
+	rootCmd.AddCommand(cc.ConfigCmd)
+}
+
+// isHelpOrCompletion reports whether cmd only prints help or shell completions,
+// which must not touch the config directory
+func isHelpOrCompletion(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		switch c.Name() {
+		case "help", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+			return true
+		}
+	}
+	return false
+}
+
+// isConfigCommand reports whether cmd is one of the config commands, which migrate on request
+func isConfigCommand(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == cc.ConfigCmd {
+			return true
+		}
+	}
+	return false
+}
+
+// migrateLegacyConfig moves the files of earlier versions into a new, empty config directory
+// and tells the user what was moved
+func migrateLegacyConfig() {
+	moved, skipped, err := hc.AutoMigrateLegacyConfig()
+	if len(moved) > 0 {
+		fmt.Fprintf(os.Stderr, "Moved %d file(s) from the config locations of earlier versions to %s\n", len(moved), hc.ConfigPath)
+		if len(skipped) > 0 {
+			fmt.Fprintf(os.Stderr, "Left %d file(s) in place; run 'apix config migrate --dry-run' to list them\n", len(skipped))
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
 }
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(cc.ExitCode(err))
 	}
 }
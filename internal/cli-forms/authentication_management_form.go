@@ -1,12 +1,13 @@
 package cliforms
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"strconv"
 	"strings"
 
+	hc "github.com/Esa824/apix/internal/http-client"
 	"github.com/Esa824/apix/internal/model"
 	"github.com/Esa824/apix/internal/utils"
 )
@@ -15,78 +16,35 @@ import (
 var AuthProfiles = make(map[string]*model.AuthProfile)
 var ActiveProfile string
 
-// initAuthProfilesDir ensures the auth-profiles directory exists
-func initAuthProfilesDir() error {
-	authProfilesDir := filepath.Join(ConfigPath, "auth-profiles")
-	return os.MkdirAll(authProfilesDir, 0755)
-}
-
 // saveAuthProfile saves a single auth profile to a JSON file
 func saveAuthProfile(profile *model.AuthProfile) error {
-	if err := initAuthProfilesDir(); err != nil {
-		return fmt.Errorf("failed to create auth-profiles directory: %w", err)
-	}
-
-	authProfilesDir := filepath.Join(ConfigPath, "auth-profiles")
-	filename := fmt.Sprintf("%s.json", profile.Name)
-	filepath := filepath.Join(authProfilesDir, filename)
-
-	data, err := json.MarshalIndent(profile, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal auth profile: %w", err)
-	}
-
-	return os.WriteFile(filepath, data, 0600)
+	return hc.SaveAuthProfile(profile)
 }
 
 // deleteAuthProfileFile deletes the JSON file for an auth profile
 func deleteAuthProfileFile(profileName string) error {
-	authProfilesDir := filepath.Join(ConfigPath, "auth-profiles")
-	filename := fmt.Sprintf("%s.json", profileName)
-	filepath := filepath.Join(authProfilesDir, filename)
-
-	if err := os.Remove(filepath); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to delete auth profile file: %w", err)
-	}
-	return nil
+	return hc.DeleteAuthProfile(profileName)
 }
 
 // loadAuthProfiles loads all auth profiles from the auth-profiles directory
 func loadAuthProfiles() error {
-	authProfilesDir := filepath.Join(ConfigPath, "auth-profiles")
-
-	// Check if directory exists
-	if _, err := os.Stat(authProfilesDir); os.IsNotExist(err) {
-		return nil // No profiles directory yet, that's okay
+	if err := unlockAuthProfiles(); err != nil {
+		return err
 	}
 
-	entries, err := os.ReadDir(authProfilesDir)
+	profiles, err := hc.GetAuthProfiles()
+	if err != nil && profiles == nil {
+		return err
+	}
 	if err != nil {
-		return fmt.Errorf("failed to read auth-profiles directory: %w", err)
+		utils.ShowWarning(err.Error())
 	}
 
 	AuthProfiles = make(map[string]*model.AuthProfile)
 	ActiveProfile = ""
 
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
-			continue
-		}
-
-		filepath := filepath.Join(authProfilesDir, entry.Name())
-		data, err := os.ReadFile(filepath)
-		if err != nil {
-			utils.ShowWarning(fmt.Sprintf("Failed to read auth profile file %s: %v", entry.Name(), err))
-			continue
-		}
-
-		var profile model.AuthProfile
-		if err := json.Unmarshal(data, &profile); err != nil {
-			utils.ShowWarning(fmt.Sprintf("Failed to parse auth profile file %s: %v", entry.Name(), err))
-			continue
-		}
-
-		AuthProfiles[profile.Name] = &profile
+	for _, profile := range profiles {
+		AuthProfiles[profile.Name] = profile
 		if profile.Active {
 			if ActiveProfile != "" {
 				// Multiple active profiles found, deactivate others
@@ -99,6 +57,38 @@ func loadAuthProfiles() error {
 	return nil
 }
 
+// unlockAuthProfiles prompts for the passphrase when auth profiles are encrypted and not yet unlocked
+func unlockAuthProfiles() error {
+	if !hc.AuthProfilesLocked() {
+		return nil
+	}
+
+	const attempts = 3
+	for i := 0; i < attempts; i++ {
+		passphrase, err := utils.AskInput(utils.InputConfig{
+			Title:       "Passphrase:",
+			Description: "Auth profiles are encrypted. Enter the passphrase to unlock them",
+			Password:    true,
+			Required:    true,
+		})
+		if err != nil {
+			return hc.ErrAuthProfilesLocked
+		}
+
+		err = hc.UnlockAuthProfiles(passphrase)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, hc.ErrWrongPassphrase) {
+			return err
+		}
+		if i < attempts-1 {
+			utils.ShowWarning("Wrong passphrase, please try again")
+		}
+	}
+	return hc.ErrWrongPassphrase
+}
+
 func HandleAuthenticationManagement() {
 	// Load existing profiles when starting auth management
 	if err := loadAuthProfiles(); err != nil {
@@ -106,12 +96,13 @@ func HandleAuthenticationManagement() {
 	}
 
 	options := []utils.SelectionOption{
-		{"Create New Auth Profile", "create-profile"},
-		{"Select Active Profile", "select-profile"},
-		{"Edit Existing Profile", "edit-profile"},
-		{"Delete Profile", "delete-profile"},
-		{"View All Profiles", "view-profiles"},
-		{"Back to Main Menu", "back"},
+		{Label: "Create New Auth Profile", Value: "create-profile"},
+		{Label: "Select Active Profile", Value: "select-profile"},
+		{Label: "Edit Existing Profile", Value: "edit-profile"},
+		{Label: "Delete Profile", Value: "delete-profile"},
+		{Label: "View All Profiles", Value: "view-profiles"},
+		{Label: "Profile Encryption", Value: "encryption"},
+		{Label: "Back to Main Menu", Value: "back"},
 	}
 
 	selectedOption, err := utils.AskSelection("Authentication Management:", options)
@@ -135,6 +126,8 @@ func handleAuthSelection(selection string) {
 		handleDeleteProfile()
 	case "view-profiles":
 		handleViewProfiles()
+	case "encryption":
+		handleProfileEncryption()
 	case "back":
 		RunInteractiveMode()
 	default:
@@ -169,10 +162,13 @@ func handleCreateProfile() {
 
 	// Step 2: Select auth type
 	authOptions := []utils.SelectionOption{
-		{"Bearer Token", "bearer"},
-		{"API Key", "apikey"},
-		{"Basic Authentication", "basic"},
-		{"OAuth 2.0 (Coming Soon)", "oauth"},
+		{Label: "Bearer Token", Value: "bearer"},
+		{Label: "API Key", Value: "apikey"},
+		{Label: "Basic Authentication", Value: "basic"},
+		{Label: "Digest Authentication", Value: "digest"},
+		{Label: "OAuth 2.0", Value: "oauth"},
+		{Label: "AWS Signature V4", Value: "aws"},
+		{Label: "HMAC Signature", Value: "hmac"},
 	}
 
 	authType, err := utils.AskSelection("Authentication Type:", authOptions)
@@ -181,12 +177,6 @@ func handleCreateProfile() {
 		return
 	}
 
-	if authType == "oauth" {
-		utils.ShowMessage("OAuth 2.0 support is coming soon!")
-		askContinueOrReturnAuth()
-		return
-	}
-
 	// Step 3: Setup auth-specific details
 	profile := &model.AuthProfile{
 		Name: profileName,
@@ -201,9 +191,17 @@ func handleCreateProfile() {
 		success = handleAPIKeySetup(profile)
 	case "basic":
 		success = handleBasicAuthSetup(profile)
+	case "digest":
+		success = handleDigestSetup(profile)
+	case "oauth":
+		success = handleOAuthSetup(profile)
+	case "aws":
+		success = handleAWSSetup(profile)
+	case "hmac":
+		success = handleHMACSetup(profile)
 	}
 
-	if !success {
+	if !success || !handleProfileHostsSetup(profile) {
 		return
 	}
 
@@ -259,6 +257,13 @@ func handleBearerTokenSetup(profile *model.AuthProfile) bool {
 	}
 
 	profile.Token = token
+	profile.Expiry = nil
+
+	// JWTs carry their expiry in the exp claim
+	if expiry := hc.JWTExpiry(token); expiry != nil {
+		utils.ShowMessage(fmt.Sprintf("This token expires at %s (from its exp claim)", utils.FormatTimeForDisplay(expiry)))
+		return handleLoginTemplateSetup(profile)
+	}
 
 	// Ask about expiry
 	hasExpiry, err := utils.AskConfirmation(
@@ -290,10 +295,65 @@ func handleBearerTokenSetup(profile *model.AuthProfile) bool {
 		}
 	}
 
+	return handleLoginTemplateSetup(profile)
+}
+
+// handleLoginTemplateSetup lets a bearer profile name a template that logs in again when its token expires
+func handleLoginTemplateSetup(profile *model.AuthProfile) bool {
+	templates, err := hc.GetTemplates()
+	if err != nil || len(templates) == 0 {
+		return true
+	}
+
+	useLogin, err := utils.AskConfirmation(
+		"Login Template",
+		"Run a saved template to get a new token when this one expires?",
+		"Yes", "No",
+	)
+	if err != nil {
+		utils.ShowError("Error asking about login template", err)
+		return false
+	}
+	if !useLogin {
+		profile.LoginTemplate = ""
+		profile.LoginCapture = ""
+		return true
+	}
+
+	options := make([]utils.SelectionOption, 0, len(templates))
+	for _, template := range templates {
+		options = append(options, utils.SelectionOption{Label: fmt.Sprintf("%s %s", template.Method, template.Name), Value: template.Name})
+	}
+	templateName, err := utils.AskSelection("Login Template:", options)
+	if err != nil {
+		utils.ShowError("Error selecting login template", err)
+		return false
+	}
+
+	capture, err := utils.AskInput(utils.InputConfig{
+		Title:       "Token Location:",
+		Description: "Where the response holds the token: body.<path> or header.<name>",
+		Placeholder: "body.access_token",
+		Value:       profile.LoginCapture,
+	})
+	if err != nil {
+		utils.ShowError("Error setting up login template", err)
+		return false
+	}
+
+	profile.LoginTemplate = templateName
+	profile.LoginCapture = strings.TrimSpace(capture)
 	return true
 }
 
 func handleAPIKeySetup(profile *model.AuthProfile) bool {
+	location, err := askAPIKeyLocation()
+	if err != nil {
+		utils.ShowError("Error selecting API key location", err)
+		return false
+	}
+	label := apiKeyNameLabel(location)
+
 	configs := []utils.InputConfig{
 		{
 			Title:       "API Key:",
@@ -303,9 +363,9 @@ func handleAPIKeySetup(profile *model.AuthProfile) bool {
 			Password:    true,
 		},
 		{
-			Title:       "Header Name:",
-			Description: "The header name for the API key",
-			Placeholder: "X-API-Key",
+			Title:       label + " Name:",
+			Description: "The " + strings.ToLower(label) + " name for the API key",
+			Placeholder: hc.DefaultAPIKeyName(location),
 			Required:    false,
 		},
 	}
@@ -318,10 +378,11 @@ func handleAPIKeySetup(profile *model.AuthProfile) bool {
 
 	profile.APIKey = values[0]
 	if values[1] == "" {
-		profile.Header = "X-API-Key"
+		profile.Header = hc.DefaultAPIKeyName(location)
 	} else {
 		profile.Header = values[1]
 	}
+	profile.Location = location
 
 	return true
 }
@@ -351,6 +412,226 @@ func handleBasicAuthSetup(profile *model.AuthProfile) bool {
 	return true
 }
 
+func handleDigestSetup(profile *model.AuthProfile) bool {
+	configs := []utils.InputConfig{
+		{
+			Title:       "Username:",
+			Placeholder: "admin",
+			Value:       profile.Username,
+			Required:    true,
+		},
+		{
+			Title:    "Password:",
+			Value:    profile.Password,
+			Required: true,
+			Password: true,
+		},
+	}
+
+	values, err := utils.AskMultipleInputs(configs)
+	if err != nil {
+		utils.ShowError("Error setting up digest authentication", err)
+		return false
+	}
+
+	profile.Username = values[0]
+	profile.Password = values[1]
+	return true
+}
+
+func handleAWSSetup(profile *model.AuthProfile) bool {
+	auth, err := askAWSSigning(model.Auth{Primary: profile.AccessKey, Secondary: profile.SecretKey, AWS: profile.AWS})
+	if err != nil {
+		utils.ShowError("Error setting up AWS Signature V4", err)
+		return false
+	}
+
+	profile.AccessKey = auth.Primary
+	profile.SecretKey = auth.Secondary
+	profile.AWS = auth.AWS
+	return true
+}
+
+func handleHMACSetup(profile *model.AuthProfile) bool {
+	auth, err := askHMACSigning(model.Auth{Primary: profile.AccessKey, Secondary: profile.SecretKey, HMAC: profile.HMAC})
+	if err != nil {
+		utils.ShowError("Error setting up HMAC signature", err)
+		return false
+	}
+
+	profile.AccessKey = auth.Primary
+	profile.SecretKey = auth.Secondary
+	profile.HMAC = auth.HMAC
+	return true
+}
+
+func handleOAuthSetup(profile *model.AuthProfile) bool {
+	config := profile.OAuth
+	if config == nil {
+		config = &model.OAuthConfig{}
+	}
+
+	grantOptions := []utils.SelectionOption{
+		{Label: "Client Credentials", Value: hc.GrantClientCredentials},
+		{Label: "Authorization Code + PKCE (browser login)", Value: hc.GrantAuthorizationCode},
+		{Label: "Password", Value: hc.GrantPassword},
+	}
+	grant, err := utils.AskSelection("OAuth 2.0 Grant:", grantOptions)
+	if err != nil {
+		utils.ShowError("Error selecting OAuth grant", err)
+		return false
+	}
+
+	configs := []utils.InputConfig{
+		{
+			Title:       "Token URL:",
+			Description: "The token endpoint of the authorization server",
+			Placeholder: "https://auth.example.com/oauth/token",
+			Value:       config.TokenURL,
+			Required:    true,
+		},
+		{
+			Title:    "Client ID:",
+			Value:    config.ClientID,
+			Required: true,
+		},
+		{
+			Title:       "Client Secret:",
+			Description: "Leave empty for public clients",
+			Value:       config.ClientSecret,
+			Password:    true,
+		},
+		{
+			Title:       "Scopes:",
+			Description: "Space-separated, optional",
+			Placeholder: "read:users write:users",
+			Value:       strings.Join(config.Scopes, " "),
+		},
+		{
+			Title:       "Audience:",
+			Description: "Optional, required by some providers such as Auth0",
+			Placeholder: "https://api.example.com",
+			Value:       config.Audience,
+		},
+	}
+	switch grant {
+	case hc.GrantAuthorizationCode:
+		configs = append(configs, utils.InputConfig{
+			Title:       "Authorization URL:",
+			Description: "The authorization endpoint opened in the browser",
+			Placeholder: "https://auth.example.com/authorize",
+			Value:       config.AuthURL,
+			Required:    true,
+		}, utils.InputConfig{
+			Title:       "Redirect Port:",
+			Description: "Port of the http://127.0.0.1:<port>/callback redirect URI, empty for any free port",
+			Placeholder: "8085",
+			Value:       portValue(config.RedirectPort),
+		})
+	case hc.GrantPassword:
+		configs = append(configs, utils.InputConfig{
+			Title:    "Username:",
+			Value:    profile.Username,
+			Required: true,
+		}, utils.InputConfig{
+			Title:    "Password:",
+			Value:    profile.Password,
+			Password: true,
+			Required: true,
+		})
+	}
+
+	values, err := utils.AskMultipleInputs(configs)
+	if err != nil {
+		utils.ShowError("Error setting up OAuth 2.0", err)
+		return false
+	}
+
+	updated := &model.OAuthConfig{
+		Grant:        grant,
+		TokenURL:     strings.TrimSpace(values[0]),
+		ClientID:     strings.TrimSpace(values[1]),
+		ClientSecret: values[2],
+		ClientAuth:   config.ClientAuth,
+		Scopes:       strings.Fields(values[3]),
+		Audience:     strings.TrimSpace(values[4]),
+	}
+	switch grant {
+	case hc.GrantAuthorizationCode:
+		updated.AuthURL = strings.TrimSpace(values[5])
+		if values[6] != "" {
+			port, err := strconv.Atoi(values[6])
+			if err != nil {
+				utils.ShowError("Invalid redirect port", err)
+				return false
+			}
+			updated.RedirectPort = port
+		}
+	case hc.GrantPassword:
+		profile.Username = values[5]
+		profile.Password = values[6]
+	}
+
+	if err := hc.ValidateOAuthConfig(updated); err != nil {
+		utils.ShowError("Invalid OAuth 2.0 settings", err)
+		return false
+	}
+
+	// Tokens issued under the old settings no longer apply
+	profile.OAuth = updated
+	profile.Token = ""
+	profile.Expiry = nil
+
+	fetchNow, err := utils.AskConfirmation(
+		"Fetch Token",
+		"Fetch an access token now? Otherwise it is fetched when the profile is first used.",
+		"Yes", "No",
+	)
+	if err == nil && fetchNow {
+		openURL := func(url string) {
+			fmt.Printf("Open this URL in your browser to log in:\n\n  %s\n\nWaiting for the redirect...\n", url)
+			utils.OpenBrowser(url)
+		}
+		if err := hc.LoginOAuth(context.Background(), profile, openURL); err != nil {
+			utils.ShowWarning(fmt.Sprintf("Could not fetch a token: %v", err))
+		} else {
+			utils.ShowSuccess("Access token fetched")
+		}
+	}
+
+	return true
+}
+
+func portValue(port int) string {
+	if port == 0 {
+		return ""
+	}
+	return strconv.Itoa(port)
+}
+
+// handleProfileHostsSetup limits the hosts a profile is sent to, so its credentials don't
+// leak to third-party hosts when it is the active profile
+func handleProfileHostsSetup(profile *model.AuthProfile) bool {
+	hosts, err := utils.AskInput(utils.InputConfig{
+		Title:       "Allowed Hosts:",
+		Description: "Comma-separated host patterns this profile is sent to, such as api.example.com or *.example.com. Leave empty for any host",
+		Placeholder: "api.example.com, *.staging.example.com",
+		Value:       strings.Join(profile.Hosts, ", "),
+	})
+	if err != nil {
+		utils.ShowError("Error setting allowed hosts", err)
+		return false
+	}
+
+	profile.Hosts = nil
+	for _, host := range strings.Split(hosts, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			profile.Hosts = append(profile.Hosts, host)
+		}
+	}
+	return true
+}
+
 func handleSelectProfile() {
 	if err := loadAuthProfiles(); err != nil {
 		utils.ShowError("Error loading auth profiles", err)
@@ -428,9 +709,17 @@ func handleEditProfile() {
 		success = handleAPIKeySetup(profile)
 	case "basic":
 		success = handleBasicAuthSetup(profile)
+	case "digest":
+		success = handleDigestSetup(profile)
+	case "oauth":
+		success = handleOAuthSetup(profile)
+	case "aws":
+		success = handleAWSSetup(profile)
+	case "hmac":
+		success = handleHMACSetup(profile)
 	}
 
-	if success {
+	if success && handleProfileHostsSetup(profile) {
 		if err := saveAuthProfile(profile); err != nil {
 			utils.ShowError("Failed to save updated profile", err)
 			return
@@ -493,6 +782,80 @@ func handleDeleteProfile() {
 	askContinueOrReturnAuth()
 }
 
+func handleProfileEncryption() {
+	if !hc.AuthProfilesEncrypted() {
+		confirm, err := utils.AskConfirmation(
+			"Encrypt auth profiles?",
+			"Tokens, passwords and API keys are encrypted with a passphrase. Scripts supply it in $"+hc.PassphraseEnv,
+			"Encrypt", "Cancel",
+		)
+		if err != nil || !confirm {
+			askContinueOrReturnAuth()
+			return
+		}
+		encryptAuthProfiles("Encrypted %d auth profile(s)")
+		return
+	}
+
+	if err := unlockAuthProfiles(); err != nil {
+		utils.ShowError("Error unlocking auth profiles", err)
+		askContinueOrReturnAuth()
+		return
+	}
+
+	options := []utils.SelectionOption{
+		{Label: "Change Passphrase", Value: "rotate"},
+		{Label: "Remove Encryption", Value: "decrypt"},
+		{Label: "Back", Value: "back"},
+	}
+	selection, err := utils.AskSelection("Auth profiles are encrypted:", options)
+	if err != nil {
+		utils.ShowError("Error selecting option", err)
+		return
+	}
+
+	switch selection {
+	case "rotate":
+		encryptAuthProfiles("Re-encrypted %d auth profile(s) with the new passphrase")
+	case "decrypt":
+		count, err := hc.DecryptAuthProfiles()
+		if err != nil {
+			utils.ShowError("Error decrypting auth profiles", err)
+		} else {
+			utils.ShowSuccess(fmt.Sprintf("Decrypted %d auth profile(s)", count))
+		}
+		askContinueOrReturnAuth()
+	default:
+		HandleAuthenticationManagement()
+	}
+}
+
+// encryptAuthProfiles asks for a new passphrase and encrypts every profile with it
+func encryptAuthProfiles(successFormat string) {
+	values, err := utils.AskMultipleInputs([]utils.InputConfig{
+		{Title: "New passphrase:", Password: true, Required: true},
+		{Title: "Confirm passphrase:", Password: true, Required: true},
+	})
+	if err != nil {
+		utils.ShowError("Error reading passphrase", err)
+		askContinueOrReturnAuth()
+		return
+	}
+	if values[0] != values[1] {
+		utils.ShowError("Error encrypting auth profiles", errors.New("passphrases do not match"))
+		askContinueOrReturnAuth()
+		return
+	}
+
+	count, err := hc.EncryptAuthProfiles(values[0])
+	if err != nil {
+		utils.ShowError("Error encrypting auth profiles", err)
+	} else {
+		utils.ShowSuccess(fmt.Sprintf(successFormat, count))
+	}
+	askContinueOrReturnAuth()
+}
+
 func handleViewProfiles() {
 	if err := loadAuthProfiles(); err != nil {
 		utils.ShowError("Error loading auth profiles", err)
@@ -517,25 +880,82 @@ func handleViewProfiles() {
 
 		profilesText.WriteString(fmt.Sprintf("%s%s\n", name, status))
 		profilesText.WriteString(fmt.Sprintf("   Type: %s\n", strings.ToUpper(profile.Type)))
+		if len(profile.Hosts) > 0 {
+			profilesText.WriteString(utils.FormatKeyValue("Hosts", strings.Join(profile.Hosts, ", "), false))
+			profilesText.WriteString("\n")
+		}
 
 		switch profile.Type {
 		case "bearer":
 			profilesText.WriteString(utils.FormatKeyValue("Token", profile.Token, true))
 			profilesText.WriteString("\n")
-			if profile.Expiry != nil {
-				profilesText.WriteString(utils.FormatKeyValue("Expires", utils.FormatTimeForDisplay(profile.Expiry), false))
+			if expiry := hc.TokenExpiry(profile); expiry != nil {
+				profilesText.WriteString(utils.FormatKeyValue("Expires", utils.FormatTimeForDisplay(expiry), false))
+				profilesText.WriteString("\n")
+			}
+			if profile.LoginTemplate != "" {
+				profilesText.WriteString(utils.FormatKeyValue("Login Template", profile.LoginTemplate, false))
 				profilesText.WriteString("\n")
 			}
 		case "apikey":
 			profilesText.WriteString(utils.FormatKeyValue("API Key", profile.APIKey, true))
 			profilesText.WriteString("\n")
-			profilesText.WriteString(utils.FormatKeyValue("Header", profile.Header, false))
+			profilesText.WriteString(utils.FormatKeyValue(apiKeyNameLabel(profile.Location), profile.Header, false))
 			profilesText.WriteString("\n")
-		case "basic":
+		case "basic", "digest":
 			profilesText.WriteString(utils.FormatKeyValue("Username", profile.Username, false))
 			profilesText.WriteString("\n")
 			profilesText.WriteString(utils.FormatKeyValue("Password", profile.Password, true))
 			profilesText.WriteString("\n")
+		case "aws":
+			profilesText.WriteString(utils.FormatKeyValue("Access Key ID", profile.AccessKey, false))
+			profilesText.WriteString("\n")
+			profilesText.WriteString(utils.FormatKeyValue("Secret Access Key", profile.SecretKey, true))
+			profilesText.WriteString("\n")
+			if profile.AWS != nil {
+				profilesText.WriteString(utils.FormatKeyValue("Region", profile.AWS.Region, false))
+				profilesText.WriteString("\n")
+				profilesText.WriteString(utils.FormatKeyValue("Service", profile.AWS.Service, false))
+				profilesText.WriteString("\n")
+			}
+		case "hmac":
+			if profile.AccessKey != "" {
+				profilesText.WriteString(utils.FormatKeyValue("Key ID", profile.AccessKey, false))
+				profilesText.WriteString("\n")
+			}
+			profilesText.WriteString(utils.FormatKeyValue("Secret", profile.SecretKey, true))
+			profilesText.WriteString("\n")
+			if profile.HMAC != nil {
+				profilesText.WriteString(utils.FormatKeyValue("Algorithm", profile.HMAC.Algorithm, false))
+				profilesText.WriteString("\n")
+				if profile.HMAC.Header != "" {
+					profilesText.WriteString(utils.FormatKeyValue("Header", profile.HMAC.Header, false))
+					profilesText.WriteString("\n")
+				}
+			}
+		case "oauth":
+			if profile.OAuth != nil {
+				profilesText.WriteString(utils.FormatKeyValue("Grant", profile.OAuth.Grant, false))
+				profilesText.WriteString("\n")
+				profilesText.WriteString(utils.FormatKeyValue("Token URL", profile.OAuth.TokenURL, false))
+				profilesText.WriteString("\n")
+				profilesText.WriteString(utils.FormatKeyValue("Client ID", profile.OAuth.ClientID, false))
+				profilesText.WriteString("\n")
+				if len(profile.OAuth.Scopes) > 0 {
+					profilesText.WriteString(utils.FormatKeyValue("Scopes", strings.Join(profile.OAuth.Scopes, " "), false))
+					profilesText.WriteString("\n")
+				}
+			}
+			if profile.Token == "" {
+				profilesText.WriteString(utils.FormatKeyValue("Token", "not fetched yet", false))
+			} else {
+				profilesText.WriteString(utils.FormatKeyValue("Token", profile.Token, true))
+			}
+			profilesText.WriteString("\n")
+			if profile.Expiry != nil {
+				profilesText.WriteString(utils.FormatKeyValue("Expires", utils.FormatTimeForDisplay(profile.Expiry), false))
+				profilesText.WriteString("\n")
+			}
 		}
 		profilesText.WriteString("\n")
 	}
@@ -0,0 +1,248 @@
+package cliforms
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	hc "github.com/Esa824/apix/internal/http-client"
+	"github.com/Esa824/apix/internal/model"
+	"github.com/Esa824/apix/internal/utils"
+)
+
+func HandleEnvironments() {
+	options := []utils.SelectionOption{
+		{Label: "Select Active Environment", Value: "select-environment"},
+		{Label: "Create New Environment", Value: "create-environment"},
+		{Label: "Edit Environment Variables", Value: "edit-environment"},
+		{Label: "Delete Environment", Value: "delete-environment"},
+		{Label: "View All Environments", Value: "view-environments"},
+		{Label: "Back to Main Menu", Value: "back"},
+	}
+
+	selectedOption, err := utils.AskSelection("Environments:", options)
+	if err != nil {
+		utils.ShowError("Error running environments form", err)
+		return
+	}
+
+	handleEnvironmentSelection(selectedOption)
+}
+
+func handleEnvironmentSelection(selection string) {
+	switch selection {
+	case "select-environment":
+		handleSelectEnvironment()
+	case "create-environment":
+		handleCreateEnvironment()
+	case "edit-environment":
+		handleEditEnvironment()
+	case "delete-environment":
+		handleDeleteEnvironment()
+	case "view-environments":
+		handleViewEnvironments()
+	case "back":
+		RunInteractiveMode()
+	default:
+		utils.ShowMessage("Unknown environment option")
+	}
+}
+
+// askEnvironment lets the user pick a saved environment, returning nil if there are none
+func askEnvironment(title string) *model.Environment {
+	environments, err := hc.GetEnvironments()
+	if err != nil {
+		utils.ShowError("Error loading environments", err)
+		return nil
+	}
+
+	if len(environments) == 0 {
+		utils.ShowMessage("No environments found. Create one first.")
+		return nil
+	}
+
+	options := make([]utils.SelectionOption, 0, len(environments))
+	for _, environment := range environments {
+		label := fmt.Sprintf("%s (%d variables)", environment.Name, len(environment.Variables))
+		if environment.Active {
+			label += " [ACTIVE]"
+		}
+		options = append(options, utils.SelectionOption{Label: label, Value: environment.Name})
+	}
+
+	selected, err := utils.AskSelection(title, options)
+	if err != nil {
+		utils.ShowError("Error selecting environment", err)
+		return nil
+	}
+
+	for _, environment := range environments {
+		if environment.Name == selected {
+			return &environment
+		}
+	}
+	return nil
+}
+
+func handleSelectEnvironment() {
+	environment := askEnvironment("Select Active Environment:")
+	if environment == nil {
+		askContinueOrReturnEnvironments()
+		return
+	}
+
+	if err := hc.SetActiveEnvironment(environment.Name); err != nil {
+		utils.ShowError("Failed to set active environment", err)
+	} else {
+		utils.ShowSuccess(fmt.Sprintf("'%s' is now the active environment", environment.Name))
+	}
+	askContinueOrReturnEnvironments()
+}
+
+func handleCreateEnvironment() {
+	name, err := utils.AskInput(utils.InputConfig{
+		Title:       "Environment Name:",
+		Description: "For example dev, staging or prod",
+		Placeholder: "staging",
+		Required:    true,
+	})
+	if err != nil {
+		utils.ShowError("Error creating environment", err)
+		return
+	}
+
+	name = strings.TrimSpace(name)
+	if _, err := hc.GetEnvironment(name); err == nil {
+		utils.ShowMessage(fmt.Sprintf("Environment '%s' already exists. Choose a different name.", name))
+		askContinueOrReturnEnvironments()
+		return
+	}
+
+	environment := model.Environment{
+		Name:      name,
+		Variables: utils.CollectKeyValuePairs("Variable", "base_url", "https://staging.example.com"),
+	}
+
+	if err := hc.SaveEnvironment(environment); err != nil {
+		utils.ShowError("Failed to save environment", err)
+		return
+	}
+	utils.ShowSuccess(fmt.Sprintf("Environment '%s' created. Use {{name}} in URLs, headers, query parameters, bodies and auth to reference its variables.", name))
+
+	setAsActive, err := utils.AskConfirmation(
+		"Set as Active Environment",
+		fmt.Sprintf("Make '%s' the active environment?", name),
+		"Yes", "No",
+	)
+	if err == nil && setAsActive {
+		if err := hc.SetActiveEnvironment(name); err != nil {
+			utils.ShowWarning("Failed to set active environment")
+		}
+	}
+
+	askContinueOrReturnEnvironments()
+}
+
+func handleEditEnvironment() {
+	environment := askEnvironment("Select Environment to Edit:")
+	if environment == nil {
+		askContinueOrReturnEnvironments()
+		return
+	}
+
+	if len(environment.Variables) > 0 {
+		environment.Variables = utils.CollectKeyValuePairs("Variable", "", "", environment.Variables)
+	}
+
+	addMore, err := utils.AskConfirmation("Add Variables", "Add new variables to this environment?", "Yes", "No")
+	if err == nil && addMore {
+		for key, value := range utils.CollectKeyValuePairs("Variable", "token", "secret") {
+			environment.Variables[key] = value
+		}
+	}
+
+	if err := hc.SaveEnvironment(*environment); err != nil {
+		utils.ShowError("Failed to save environment", err)
+	} else {
+		utils.ShowSuccess(fmt.Sprintf("Environment '%s' updated successfully!", environment.Name))
+	}
+	askContinueOrReturnEnvironments()
+}
+
+func handleDeleteEnvironment() {
+	environment := askEnvironment("Select Environment to Delete:")
+	if environment == nil {
+		askContinueOrReturnEnvironments()
+		return
+	}
+
+	confirmDelete, err := utils.AskDangerousConfirmation(
+		"Delete Environment",
+		"Are you sure you want to delete environment",
+		environment.Name,
+	)
+	if err != nil {
+		utils.ShowError("Error confirming deletion", err)
+		return
+	}
+
+	if confirmDelete {
+		if err := hc.DeleteEnvironment(environment.Name); err != nil {
+			utils.ShowError("Failed to delete environment", err)
+		} else {
+			utils.ShowSuccess(fmt.Sprintf("Environment '%s' deleted successfully!", environment.Name))
+		}
+	} else {
+		utils.ShowMessage("Deletion cancelled.")
+	}
+	askContinueOrReturnEnvironments()
+}
+
+func handleViewEnvironments() {
+	environments, err := hc.GetEnvironments()
+	if err != nil {
+		utils.ShowError("Error loading environments", err)
+		return
+	}
+
+	if len(environments) == 0 {
+		utils.ShowMessage("No environments configured.")
+		askContinueOrReturnEnvironments()
+		return
+	}
+
+	var environmentsText strings.Builder
+	environmentsText.WriteString("Environments:\n")
+	environmentsText.WriteString("─────────────────────────────────\n\n")
+
+	for _, environment := range environments {
+		status := ""
+		if environment.Active {
+			status = " [ACTIVE]"
+		}
+		environmentsText.WriteString(fmt.Sprintf("%s%s\n", environment.Name, status))
+
+		names := make([]string, 0, len(environment.Variables))
+		for name := range environment.Variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			environmentsText.WriteString(fmt.Sprintf("   %s = %s\n", name, environment.Variables[name]))
+		}
+		environmentsText.WriteString("\n")
+	}
+
+	utils.DisplayFormattedText("Environments", environmentsText.String())
+	askContinueOrReturnEnvironments()
+}
+
+func askContinueOrReturnEnvironments() {
+	utils.AskContinueOrReturn(
+		HandleEnvironments,
+		RunInteractiveMode,
+		"Continue with Environments",
+		"Return to Main Menu",
+	)
+}
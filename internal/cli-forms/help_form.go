@@ -178,13 +178,13 @@ Review error messages carefully`,
 
 func HandleHelpAndDocumentation() {
 	options := []utils.SelectionOption{
-		{"Quick Start Guide", "quick-start"},
-		{"Command Examples", "commands"},
-		{"Keyboard Shortcuts", "shortcuts"},
-		{"Common API Patterns", "api-patterns"},
-		{"Troubleshooting Guide", "troubleshooting"},
-		{"View All Documentation", "view-all"},
-		{"Back to Main Menu", "back"},
+		{Label: "Quick Start Guide", Value: "quick-start"},
+		{Label: "Command Examples", Value: "commands"},
+		{Label: "Keyboard Shortcuts", Value: "shortcuts"},
+		{Label: "Common API Patterns", Value: "api-patterns"},
+		{Label: "Troubleshooting Guide", Value: "troubleshooting"},
+		{Label: "View All Documentation", Value: "view-all"},
+		{Label: "Back to Main Menu", Value: "back"},
 	}
 
 	selectedOption, err := utils.AskSelection("Help & Documentation:", options)
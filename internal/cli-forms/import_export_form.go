@@ -0,0 +1,144 @@
+package cliforms
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Esa824/apix/internal/converters"
+	hc "github.com/Esa824/apix/internal/http-client"
+	"github.com/Esa824/apix/internal/model"
+	"github.com/Esa824/apix/internal/utils"
+)
+
+// handleImportPostman imports a Postman v2.1 collection or environment export
+func handleImportPostman() {
+	path, err := utils.AskInput(utils.InputConfig{
+		Title:       "Postman File Path:",
+		Description: "Path to a Postman Collection v2.1 or environment export",
+		Placeholder: "/path/to/collection.postman_collection.json",
+		Required:    true,
+	})
+	if err != nil {
+		utils.ShowError("Error reading file path", err)
+		return
+	}
+
+	data, err := os.ReadFile(strings.TrimSpace(path))
+	if err != nil {
+		utils.ShowError("Error reading Postman file", err)
+		askContinueOrReturnTemplates()
+		return
+	}
+
+	if converters.IsPostmanEnvironment(data) {
+		environment, err := converters.ParsePostmanEnvironment(data)
+		if err != nil {
+			utils.ShowError("Error parsing Postman environment", err)
+		} else if saveImportedEnvironment(*environment) {
+			utils.ShowSuccess(fmt.Sprintf("Imported environment '%s' (%d variables)", environment.Name, len(environment.Variables)))
+		}
+		askContinueOrReturnTemplates()
+		return
+	}
+
+	collection, variables, err := converters.ParsePostmanCollection(data)
+	if err != nil {
+		utils.ShowError("Error parsing Postman collection", err)
+		askContinueOrReturnTemplates()
+		return
+	}
+
+	err = hc.ImportCollection(collection.Name, *collection, false)
+	if err != nil && confirmReplace("Collection", collection.Name) {
+		err = hc.ImportCollection(collection.Name, *collection, true)
+	}
+	if err != nil {
+		utils.ShowError("Failed to import collection", err)
+		askContinueOrReturnTemplates()
+		return
+	}
+	utils.ShowSuccess(fmt.Sprintf("Imported collection '%s' (%d requests). Run it from \"Run Collection\".",
+		collection.Name, len(hc.CollectionTemplates(*collection))))
+
+	if len(variables) > 0 {
+		environment := model.Environment{Name: collection.Name, Variables: variables}
+		if saveImportedEnvironment(environment) {
+			utils.ShowSuccess(fmt.Sprintf("Imported collection variables as environment '%s'", environment.Name))
+		}
+	}
+
+	askContinueOrReturnTemplates()
+}
+
+// saveImportedEnvironment saves an environment, asking before replacing an existing one
+func saveImportedEnvironment(environment model.Environment) bool {
+	if existing, err := hc.GetEnvironment(environment.Name); err == nil {
+		if !confirmReplace("Environment", environment.Name) {
+			return false
+		}
+		environment.Active = existing.Active
+	}
+
+	if err := hc.SaveEnvironment(environment); err != nil {
+		utils.ShowError("Failed to save environment", err)
+		return false
+	}
+	return true
+}
+
+// confirmReplace asks whether an existing item should be overwritten by an import
+func confirmReplace(kind, name string) bool {
+	replace, err := utils.AskConfirmation(
+		fmt.Sprintf("%s Exists", kind),
+		fmt.Sprintf("%s '%s' already exists. Replace it?", kind, name),
+		"Replace", "Cancel",
+	)
+	return err == nil && replace
+}
+
+// handleExportTemplates writes all saved templates as a Postman, Insomnia or OpenAPI file
+func handleExportTemplates() {
+	templates, err := hc.GetTemplates()
+	if err != nil {
+		utils.ShowError("Error loading templates", err)
+		return
+	}
+	if len(templates) == 0 {
+		utils.ShowMessage("No saved templates to export.")
+		askContinueOrReturnTemplates()
+		return
+	}
+
+	format, err := utils.AskSelection("Export Format:", []utils.SelectionOption{
+		{Label: "Postman Collection v2.1", Value: converters.FormatPostman},
+		{Label: "Insomnia v4 Export", Value: converters.FormatInsomnia},
+		{Label: "OpenAPI 3 (skeleton)", Value: converters.FormatOpenAPI},
+	})
+	if err != nil {
+		utils.ShowError("Error selecting format", err)
+		return
+	}
+
+	path, err := utils.AskInput(utils.InputConfig{
+		Title:       "Export File Path:",
+		Placeholder: fmt.Sprintf("apix.%s.json", format),
+		Value:       fmt.Sprintf("apix.%s.json", format),
+		Required:    true,
+	})
+	if err != nil {
+		utils.ShowError("Error reading file path", err)
+		return
+	}
+
+	data, err := converters.Export(format, "apix", templates)
+	if err == nil {
+		err = os.WriteFile(strings.TrimSpace(path), data, 0600)
+	}
+	if err != nil {
+		utils.ShowError("Failed to export templates", err)
+	} else {
+		utils.ShowSuccess(fmt.Sprintf("Exported %d templates to %s", len(templates), strings.TrimSpace(path)))
+	}
+	askContinueOrReturnTemplates()
+}
@@ -5,11 +5,13 @@ import (
 	"os"
 
 	"github.com/charmbracelet/huh"
-)
 
-var ConfigPath = "./testconfigs/config1/"
+	hc "github.com/Esa824/apix/internal/http-client"
+	"github.com/Esa824/apix/internal/utils"
+)
 
 func RunInteractiveMode() {
+	hc.Warn = utils.ShowWarning
 	loadAuthProfiles()
 	var selectedOption string
 
@@ -22,6 +24,7 @@ func RunInteractiveMode() {
 					huh.NewOption("HTTP Requests", "http-requests"),
 					huh.NewOption("Templates and History", "templates-and-history"),
 					huh.NewOption("Authentication Management", "authentication-management"),
+					huh.NewOption("Environments", "environments"),
 					huh.NewOption("Settings", "settings"),
 					huh.NewOption("Help", "help"),
 					huh.NewOption("Exit", "exit"),
@@ -49,6 +52,8 @@ func handleSelection(selection string) {
 		HandleTemplatesAndHistory()
 	case "authentication-management":
 		HandleAuthenticationManagement()
+	case "environments":
+		HandleEnvironments()
 	case "settings":
 		HandleSettingsManagement()
 	case "help":
@@ -2,84 +2,35 @@ package cliforms
 
 import (
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
-	"time"
 
+	hc "github.com/Esa824/apix/internal/http-client"
 	"github.com/Esa824/apix/internal/model"
 	"github.com/Esa824/apix/internal/utils"
 )
 
-// Default settings
-var AppSettings = &model.GlobalSettings{
-	Display: model.DisplaySettings{
-		ResponseFormat:  "pretty-json",
-		ColorOutput:     true,
-		ShowTiming:      true,
-		ShowHeaders:     false,
-		ShowStatusCode:  true,
-		MaxResponseSize: 1024, // 1MB
-		IndentSize:      2,
-		LineNumbers:     false,
-		SyntaxHighlight: true,
-	},
-	Behavior: model.BehaviorSettings{
-		AutoSaveRequests:       false,
-		ConfirmDeleteRequests:  true,
-		ConfirmDestructive:     true,
-		RequestTimeout:         30,
-		MaxRetries:             3,
-		RetryDelay:             1,
-		FollowRedirects:        true,
-		MaxRedirects:           5,
-		ValidateSSL:            true,
-		CacheResponses:         false,
-		CacheDuration:          10,
-		ShowProgressBar:        true,
-		VerboseMode:            false,
-		SaveFailedRequests:     true,
-		AutoAddHeaders:         true,
-		DefaultContentType:     "application/json",
-		PreserveSessionCookies: true,
-	},
-	Network: model.NetworkSettings{
-		DefaultTimeout:     30,
-		ConnectTimeout:     10,
-		ReadTimeout:        30,
-		WriteTimeout:       30,
-		MaxConnections:     10,
-		UserAgent:          "Apix/1.0",
-		ProxyEnabled:       false,
-		KeepAlive:          true,
-		CompressionEnabled: true,
-	},
-	Logging: model.LoggingSettings{
-		EnableLogging:  false,
-		LogLevel:       "info",
-		LogFile:        "apix.log",
-		LogRequests:    true,
-		LogResponses:   true,
-		LogHeaders:     false,
-		LogTiming:      true,
-		RotateLogFiles: true,
-		MaxLogSize:     10,
-		MaxLogFiles:    5,
-	},
-	Version:   "1.0.0",
-	LastSaved: time.Now(),
-}
+// AppSettings points at the settings shared with the HTTP client
+var AppSettings = hc.Settings
+
+// savedSettings is the last state written to disk, used to skip redundant saves
+var savedSettings model.GlobalSettings
 
 func HandleSettingsManagement() {
+	savedSettings = *AppSettings
+
 	options := []utils.SelectionOption{
-		{"Display Settings", "display"},
-		{"Behavior Settings", "behavior"},
-		{"Network Settings", "network"},
-		{"Logging Settings", "logging"},
-		{"Export Settings", "export"},
-		{"Import Settings", "import"},
-		{"Reset to Defaults", "reset"},
-		{"Current Settings Overview", "overview"},
-		{"Back to Main Menu", "back"},
+		{Label: "Display Settings", Value: "display"},
+		{Label: "Behavior Settings", Value: "behavior"},
+		{Label: "Network Settings", Value: "network"},
+		{Label: "Logging Settings", Value: "logging"},
+		{Label: "Redaction Settings", Value: "redaction"},
+		{Label: "Export Settings", Value: "export"},
+		{Label: "Import Settings", Value: "import"},
+		{Label: "Reset to Defaults", Value: "reset"},
+		{Label: "Current Settings Overview", Value: "overview"},
+		{Label: "Back to Main Menu", Value: "back"},
 	}
 
 	selectedOption, err := utils.AskSelection("Settings Management:", options)
@@ -101,6 +52,8 @@ func handleSettingsSelection(selection string) {
 		handleNetworkSettings()
 	case "logging":
 		handleLoggingSettings()
+	case "redaction":
+		handleRedactionSettings()
 	case "export":
 		handleExportSettings()
 	case "import":
@@ -118,14 +71,14 @@ func handleSettingsSelection(selection string) {
 
 func handleDisplaySettings() {
 	options := []utils.SelectionOption{
-		{"Response Format", "response-format"},
-		{"Color Output", "color-output"},
-		{"Show Request Timing", "timing"},
-		{"Show Headers", "headers"},
-		{"Show Status Code", "status"},
-		{"Max Response Size", "max-size"},
-		{"Formatting Options", "formatting"},
-		{"Back to Settings", "back"},
+		{Label: "Response Format", Value: "response-format"},
+		{Label: "Color Output", Value: "color-output"},
+		{Label: "Show Request Timing", Value: "timing"},
+		{Label: "Show Headers", Value: "headers"},
+		{Label: "Show Status Code", Value: "status"},
+		{Label: "Max Response Size", Value: "max-size"},
+		{Label: "Formatting Options", Value: "formatting"},
+		{Label: "Back to Settings", Value: "back"},
 	}
 
 	selectedOption, err := utils.AskSelection("Display Settings:", options)
@@ -182,10 +135,10 @@ func handleResponseFormat() {
 	currentFormat := AppSettings.Display.ResponseFormat
 
 	options := []utils.SelectionOption{
-		{"Pretty JSON (formatted with indentation)", "pretty-json"},
-		{"Raw (unmodified response)", "raw"},
-		{"Headers Only (no body)", "headers-only"},
-		{"Compact JSON (minified)", "compact-json"},
+		{Label: "Pretty JSON (formatted with indentation)", Value: "pretty-json"},
+		{Label: "Raw (unmodified response)", Value: "raw"},
+		{Label: "Headers Only (no body)", Value: "headers-only"},
+		{Label: "Compact JSON (minified)", Value: "compact-json"},
 	}
 
 	selectedFormat, err := utils.AskSelection(
@@ -243,10 +196,10 @@ func handleMaxResponseSize() {
 
 func handleFormattingOptions() {
 	options := []utils.SelectionOption{
-		{fmt.Sprintf("Indent Size (current: %d)", AppSettings.Display.IndentSize), "indent"},
-		{fmt.Sprintf("Line Numbers (%s)", formatBoolStatus(AppSettings.Display.LineNumbers)), "line-numbers"},
-		{fmt.Sprintf("Syntax Highlighting (%s)", formatBoolStatus(AppSettings.Display.SyntaxHighlight)), "syntax"},
-		{"Back to Display Settings", "back"},
+		{Label: fmt.Sprintf("Indent Size (current: %d)", AppSettings.Display.IndentSize), Value: "indent"},
+		{Label: fmt.Sprintf("Line Numbers (%s)", formatBoolStatus(AppSettings.Display.LineNumbers)), Value: "line-numbers"},
+		{Label: fmt.Sprintf("Syntax Highlighting (%s)", formatBoolStatus(AppSettings.Display.SyntaxHighlight)), Value: "syntax"},
+		{Label: "Back to Display Settings", Value: "back"},
 	}
 
 	selectedOption, err := utils.AskSelection("Formatting Options:", options)
@@ -302,16 +255,16 @@ func handleIndentSize() {
 
 func handleBehaviorSettings() {
 	options := []utils.SelectionOption{
-		{fmt.Sprintf("Auto-save Requests (%s)", formatBoolStatus(AppSettings.Behavior.AutoSaveRequests)), "auto-save"},
-		{fmt.Sprintf("Confirm DELETE Requests (%s)", formatBoolStatus(AppSettings.Behavior.ConfirmDeleteRequests)), "confirm-delete"},
-		{fmt.Sprintf("Confirm Destructive Actions (%s)", formatBoolStatus(AppSettings.Behavior.ConfirmDestructive)), "confirm-destructive"},
-		{fmt.Sprintf("Request Timeout (%ds)", AppSettings.Behavior.RequestTimeout), "timeout"},
-		{fmt.Sprintf("Retry Settings (max: %d, delay: %ds)", AppSettings.Behavior.MaxRetries, AppSettings.Behavior.RetryDelay), "retry"},
-		{fmt.Sprintf("Redirect Settings (%s, max: %d)", formatBoolStatus(AppSettings.Behavior.FollowRedirects), AppSettings.Behavior.MaxRedirects), "redirects"},
-		{fmt.Sprintf("SSL Validation (%s)", formatBoolStatus(AppSettings.Behavior.ValidateSSL)), "ssl"},
-		{fmt.Sprintf("Response Caching (%s)", formatBoolStatus(AppSettings.Behavior.CacheResponses)), "cache"},
-		{"Advanced Behavior Options", "advanced"},
-		{"Back to Settings", "back"},
+		{Label: fmt.Sprintf("Auto-save Requests (%s)", formatBoolStatus(AppSettings.Behavior.AutoSaveRequests)), Value: "auto-save"},
+		{Label: fmt.Sprintf("Confirm DELETE Requests (%s)", formatBoolStatus(AppSettings.Behavior.ConfirmDeleteRequests)), Value: "confirm-delete"},
+		{Label: fmt.Sprintf("Confirm Destructive Actions (%s)", formatBoolStatus(AppSettings.Behavior.ConfirmDestructive)), Value: "confirm-destructive"},
+		{Label: fmt.Sprintf("Request Timeout (%ds)", AppSettings.Behavior.RequestTimeout), Value: "timeout"},
+		{Label: fmt.Sprintf("Retry Settings (max: %d, delay: %ds)", AppSettings.Behavior.MaxRetries, AppSettings.Behavior.RetryDelay), Value: "retry"},
+		{Label: fmt.Sprintf("Redirect Settings (%s, max: %d)", formatBoolStatus(AppSettings.Behavior.FollowRedirects), AppSettings.Behavior.MaxRedirects), Value: "redirects"},
+		{Label: fmt.Sprintf("SSL Validation (%s)", formatBoolStatus(AppSettings.Behavior.ValidateSSL)), Value: "ssl"},
+		{Label: fmt.Sprintf("Response Caching (%s)", formatBoolStatus(AppSettings.Behavior.CacheResponses)), Value: "cache"},
+		{Label: "Advanced Behavior Options", Value: "advanced"},
+		{Label: "Back to Settings", Value: "back"},
 	}
 
 	selectedOption, err := utils.AskSelection("Behavior Settings:", options)
@@ -478,13 +431,15 @@ func handleCacheSettings() {
 
 func handleAdvancedBehavior() {
 	options := []utils.SelectionOption{
-		{fmt.Sprintf("Progress Bar (%s)", formatBoolStatus(AppSettings.Behavior.ShowProgressBar)), "progress"},
-		{fmt.Sprintf("Verbose Mode (%s)", formatBoolStatus(AppSettings.Behavior.VerboseMode)), "verbose"},
-		{fmt.Sprintf("Save Failed Requests (%s)", formatBoolStatus(AppSettings.Behavior.SaveFailedRequests)), "save-failed"},
-		{fmt.Sprintf("Auto-add Headers (%s)", formatBoolStatus(AppSettings.Behavior.AutoAddHeaders)), "auto-headers"},
-		{fmt.Sprintf("Default Content-Type (%s)", AppSettings.Behavior.DefaultContentType), "content-type"},
-		{fmt.Sprintf("Preserve Cookies (%s)", formatBoolStatus(AppSettings.Behavior.PreserveSessionCookies)), "cookies"},
-		{"Back to Behavior Settings", "back"},
+		{Label: fmt.Sprintf("Progress Bar (%s)", formatBoolStatus(AppSettings.Behavior.ShowProgressBar)), Value: "progress"},
+		{Label: fmt.Sprintf("Verbose Mode (%s)", formatBoolStatus(AppSettings.Behavior.VerboseMode)), Value: "verbose"},
+		{Label: fmt.Sprintf("Save Failed Requests (%s)", formatBoolStatus(AppSettings.Behavior.SaveFailedRequests)), Value: "save-failed"},
+		{Label: fmt.Sprintf("History Body Snapshot (%s)", formatHistoryBodyLimit(AppSettings.Behavior.HistoryBodyLimit)), Value: "history-body"},
+		{Label: fmt.Sprintf("History Retention (%s)", formatHistoryRetention()), Value: "history-retention"},
+		{Label: fmt.Sprintf("Auto-add Headers (%s)", formatBoolStatus(AppSettings.Behavior.AutoAddHeaders)), Value: "auto-headers"},
+		{Label: fmt.Sprintf("Default Content-Type (%s)", AppSettings.Behavior.DefaultContentType), Value: "content-type"},
+		{Label: fmt.Sprintf("Preserve Cookies (%s)", formatBoolStatus(AppSettings.Behavior.PreserveSessionCookies)), Value: "cookies"},
+		{Label: "Back to Behavior Settings", Value: "back"},
 	}
 
 	selectedOption, err := utils.AskSelection("Advanced Behavior Options:", options)
@@ -506,6 +461,10 @@ func handleAdvancedBehavior() {
 		AppSettings.Behavior.SaveFailedRequests = !AppSettings.Behavior.SaveFailedRequests
 		utils.ShowSuccess(fmt.Sprintf("Save failed requests %s", formatBoolStatus(AppSettings.Behavior.SaveFailedRequests)))
 		askContinueOrReturnSettings()
+	case "history-body":
+		handleHistoryBodyLimit()
+	case "history-retention":
+		handleHistoryRetention()
 	case "auto-headers":
 		AppSettings.Behavior.AutoAddHeaders = !AppSettings.Behavior.AutoAddHeaders
 		utils.ShowSuccess(fmt.Sprintf("Auto-add headers %s", formatBoolStatus(AppSettings.Behavior.AutoAddHeaders)))
@@ -521,14 +480,102 @@ func handleAdvancedBehavior() {
 	}
 }
 
+func handleHistoryBodyLimit() {
+	limitConfig := utils.InputConfig{
+		Title:       fmt.Sprintf("History Body Snapshot (current: %s):", formatHistoryBodyLimit(AppSettings.Behavior.HistoryBodyLimit)),
+		Description: "Enter how much of each response body to keep in history, in KB (0 = don't keep bodies, max 1024)",
+		Placeholder: "16",
+		Required:    false,
+	}
+
+	limitInput, err := utils.AskInput(limitConfig)
+	if err != nil {
+		utils.ShowError("Error setting history body snapshot", err)
+		return
+	}
+
+	if limitInput != "" {
+		if limit, parseErr := strconv.Atoi(limitInput); parseErr == nil {
+			if limit < 0 || limit > 1024 {
+				utils.ShowWarning("Size must be between 0 and 1024 KB")
+			} else {
+				AppSettings.Behavior.HistoryBodyLimit = limit
+				utils.ShowSuccess(fmt.Sprintf("History body snapshot set to %s", formatHistoryBodyLimit(limit)))
+			}
+		} else {
+			utils.ShowError("Invalid size", parseErr)
+		}
+	}
+	askContinueOrReturnSettings()
+}
+
+func handleHistoryRetention() {
+	inputs, err := utils.AskMultipleInputs([]utils.InputConfig{
+		{
+			Title:       fmt.Sprintf("Max Entries (current: %d):", AppSettings.Behavior.HistoryMaxEntries),
+			Description: "Number of requests to keep (0 = unlimited, max 100000)",
+			Placeholder: "1000",
+		},
+		{
+			Title:       fmt.Sprintf("Max Age (current: %d days):", AppSettings.Behavior.HistoryMaxAge),
+			Description: "Days to keep requests for (0 = forever, max 3650)",
+			Placeholder: "90",
+		},
+	})
+	if err != nil {
+		utils.ShowError("Error setting history retention", err)
+		return
+	}
+
+	if inputs[0] != "" {
+		if entries, parseErr := strconv.Atoi(inputs[0]); parseErr != nil {
+			utils.ShowError("Invalid max entries", parseErr)
+		} else if entries < 0 || entries > 100000 {
+			utils.ShowWarning("Max entries must be between 0 and 100000")
+		} else {
+			AppSettings.Behavior.HistoryMaxEntries = entries
+		}
+	}
+	if inputs[1] != "" {
+		if days, parseErr := strconv.Atoi(inputs[1]); parseErr != nil {
+			utils.ShowError("Invalid max age", parseErr)
+		} else if days < 0 || days > 3650 {
+			utils.ShowWarning("Max age must be between 0 and 3650 days")
+		} else {
+			AppSettings.Behavior.HistoryMaxAge = days
+		}
+	}
+
+	utils.ShowSuccess(fmt.Sprintf("History retention set to %s", formatHistoryRetention()))
+	askContinueOrReturnSettings()
+}
+
+func formatHistoryRetention() string {
+	entries, age := "unlimited", "forever"
+	if AppSettings.Behavior.HistoryMaxEntries > 0 {
+		entries = fmt.Sprintf("%d entries", AppSettings.Behavior.HistoryMaxEntries)
+	}
+	if AppSettings.Behavior.HistoryMaxAge > 0 {
+		age = fmt.Sprintf("%d days", AppSettings.Behavior.HistoryMaxAge)
+	}
+	return entries + ", " + age
+}
+
+func formatHistoryBodyLimit(sizeKB int) string {
+	if sizeKB == 0 {
+		return "OFF"
+	}
+	return fmt.Sprintf("%d KB", sizeKB)
+}
+
 func handleDefaultContentType() {
 	options := []utils.SelectionOption{
-		{"application/json", "application/json"},
-		{"application/xml", "application/xml"},
-		{"text/plain", "text/plain"},
-		{"application/x-www-form-urlencoded", "application/x-www-form-urlencoded"},
-		{"multipart/form-data", "multipart/form-data"},
-		{"Custom", "custom"},
+		{Label: "application/json", Value: "application/json"},
+		{Label: "application/xml", Value: "application/xml"},
+		{Label: "text/plain", Value: "text/plain"},
+		{Label: "application/x-www-form-urlencoded", Value: "application/x-www-form-urlencoded"},
+		{Label: "multipart/form-data", Value: "multipart/form-data"},
+		{Label: "Custom", Value: "custom"},
 	}
 
 	selectedType, err := utils.AskSelection(
@@ -571,13 +618,158 @@ func handleLoggingSettings() {
 	askContinueOrReturnSettings()
 }
 
+func handleRedactionSettings() {
+	redaction := AppSettings.Redaction
+	options := []utils.SelectionOption{
+		{Label: fmt.Sprintf("Redact History (%s)", formatBoolStatus(redaction.RedactHistory)), Value: "history"},
+		{Label: fmt.Sprintf("Redact Templates (%s)", formatBoolStatus(redaction.RedactTemplates)), Value: "templates"},
+		{Label: fmt.Sprintf("Marker (%s)", redaction.Marker), Value: "marker"},
+		{Label: fmt.Sprintf("Sensitive Headers (%d)", len(redaction.Headers)), Value: "headers"},
+		{Label: fmt.Sprintf("Sensitive Query Parameters (%d)", len(redaction.QueryParams)), Value: "query"},
+		{Label: fmt.Sprintf("Sensitive Body Paths (%d)", len(redaction.BodyPaths)), Value: "body"},
+		{Label: "Back to Settings", Value: "back"},
+	}
+
+	selectedOption, err := utils.AskSelection("Redaction Settings:", options)
+	if err != nil {
+		utils.ShowError("Error in redaction settings", err)
+		return
+	}
+
+	switch selectedOption {
+	case "history":
+		AppSettings.Redaction.RedactHistory = !AppSettings.Redaction.RedactHistory
+		utils.ShowSuccess(fmt.Sprintf("History redaction %s", formatBoolStatus(AppSettings.Redaction.RedactHistory)))
+		askContinueOrReturnSettings()
+	case "templates":
+		AppSettings.Redaction.RedactTemplates = !AppSettings.Redaction.RedactTemplates
+		utils.ShowSuccess(fmt.Sprintf("Template redaction %s", formatBoolStatus(AppSettings.Redaction.RedactTemplates)))
+		askContinueOrReturnSettings()
+	case "marker":
+		marker, err := utils.AskInput(utils.InputConfig{
+			Title:       "Redaction Marker:",
+			Description: "Text that replaces redacted values",
+			Value:       AppSettings.Redaction.Marker,
+			Required:    true,
+		})
+		if err != nil {
+			utils.ShowError("Error setting marker", err)
+			return
+		}
+		AppSettings.Redaction.Marker = marker
+		utils.ShowSuccess(fmt.Sprintf("Redaction marker set to: %s", marker))
+		askContinueOrReturnSettings()
+	case "headers":
+		AppSettings.Redaction.Headers = askRedactionList("Sensitive Headers:", "Header names, case-insensitive", AppSettings.Redaction.Headers)
+		askContinueOrReturnSettings()
+	case "query":
+		AppSettings.Redaction.QueryParams = askRedactionList("Sensitive Query Parameters:", "Parameter names, case-insensitive", AppSettings.Redaction.QueryParams)
+		askContinueOrReturnSettings()
+	case "body":
+		AppSettings.Redaction.BodyPaths = askRedactionList("Sensitive Body Paths:",
+			"JSON paths such as user.password; a single name matches at any depth and * matches any key", AppSettings.Redaction.BodyPaths)
+		askContinueOrReturnSettings()
+	case "back":
+		HandleSettingsManagement()
+	}
+}
+
+// askRedactionList edits a comma-separated list, returning current unchanged if the input fails
+func askRedactionList(title, description string, current []string) []string {
+	input, err := utils.AskInput(utils.InputConfig{
+		Title:       title,
+		Description: description + " (comma-separated)",
+		Value:       strings.Join(current, ", "),
+	})
+	if err != nil {
+		utils.ShowError("Error updating list", err)
+		return current
+	}
+
+	items := []string{}
+	for _, item := range strings.Split(input, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	utils.ShowSuccess(fmt.Sprintf("%d entries saved", len(items)))
+	return items
+}
+
 func handleExportSettings() {
-	utils.ShowMessage("Export settings functionality coming soon...")
+	formatOptions := []utils.SelectionOption{
+		{Label: "JSON", Value: hc.SettingsFormatJSON},
+		{Label: "YAML", Value: hc.SettingsFormatYAML},
+	}
+
+	format, err := utils.AskSelection("Export Format:", formatOptions)
+	if err != nil {
+		utils.ShowError("Error selecting export format", err)
+		return
+	}
+
+	pathConfig := utils.InputConfig{
+		Title:       "Export File:",
+		Description: "Path of the file to write",
+		Placeholder: "apix-settings." + format,
+		Required:    false,
+	}
+
+	path, err := utils.AskInput(pathConfig)
+	if err != nil {
+		utils.ShowError("Error getting export path", err)
+		return
+	}
+	if path == "" {
+		path = "apix-settings." + format
+	}
+
+	if err := hc.ExportSettings(AppSettings, path, format); err != nil {
+		utils.ShowError("Failed to export settings", err)
+	} else {
+		utils.ShowSuccess(fmt.Sprintf("Settings exported to %s", path))
+	}
 	askContinueOrReturnSettings()
 }
 
 func handleImportSettings() {
-	utils.ShowMessage("Import settings functionality coming soon...")
+	pathConfig := utils.InputConfig{
+		Title:       "Import File:",
+		Description: "Path of a JSON or YAML settings file",
+		Placeholder: "apix-settings.json",
+		Required:    true,
+	}
+
+	path, err := utils.AskInput(pathConfig)
+	if err != nil {
+		utils.ShowError("Error getting import path", err)
+		return
+	}
+
+	imported, err := hc.ImportSettings(path)
+	if err != nil {
+		utils.ShowError("Failed to import settings", err)
+		askContinueOrReturnSettings()
+		return
+	}
+
+	confirmed, err := utils.AskConfirmation(
+		"Apply Imported Settings",
+		fmt.Sprintf("Replace your current settings with the ones from %s?", path),
+		"Yes, apply",
+		"Cancel",
+	)
+	if err != nil {
+		utils.ShowError("Error confirming import", err)
+		return
+	}
+
+	if confirmed {
+		*AppSettings = *imported
+		utils.ShowSuccess(fmt.Sprintf("Settings imported from %s", path))
+	} else {
+		utils.ShowMessage("Settings import cancelled")
+	}
 	askContinueOrReturnSettings()
 }
 
@@ -595,40 +787,7 @@ func handleResetSettings() {
 
 	if confirmed {
 		// Reset to defaults (create new instance)
-		*AppSettings = model.GlobalSettings{
-			Display: model.DisplaySettings{
-				ResponseFormat:  "pretty-json",
-				ColorOutput:     true,
-				ShowTiming:      true,
-				ShowHeaders:     false,
-				ShowStatusCode:  true,
-				MaxResponseSize: 1024,
-				IndentSize:      2,
-				LineNumbers:     false,
-				SyntaxHighlight: true,
-			},
-			Behavior: model.BehaviorSettings{
-				AutoSaveRequests:       false,
-				ConfirmDeleteRequests:  true,
-				ConfirmDestructive:     true,
-				RequestTimeout:         30,
-				MaxRetries:             3,
-				RetryDelay:             1,
-				FollowRedirects:        true,
-				MaxRedirects:           5,
-				ValidateSSL:            true,
-				CacheResponses:         false,
-				CacheDuration:          10,
-				ShowProgressBar:        true,
-				VerboseMode:            false,
-				SaveFailedRequests:     true,
-				AutoAddHeaders:         true,
-				DefaultContentType:     "application/json",
-				PreserveSessionCookies: true,
-			},
-			Version:   "1.0.0",
-			LastSaved: time.Now(),
-		}
+		*AppSettings = *hc.DefaultSettings()
 		utils.ShowSuccess("All settings have been reset to defaults")
 	} else {
 		utils.ShowMessage("Settings reset cancelled")
@@ -663,6 +822,16 @@ func showSettingsOverview() {
 	overview.WriteString(fmt.Sprintf("SSL Validation: %s\n", formatBoolStatus(AppSettings.Behavior.ValidateSSL)))
 	overview.WriteString("\n")
 
+	// Redaction Settings
+	overview.WriteString("Redaction:\n")
+	overview.WriteString("─────────────────────────────────\n")
+	overview.WriteString(fmt.Sprintf("Redact History: %s\n", formatBoolStatus(AppSettings.Redaction.RedactHistory)))
+	overview.WriteString(fmt.Sprintf("Redact Templates: %s\n", formatBoolStatus(AppSettings.Redaction.RedactTemplates)))
+	overview.WriteString(fmt.Sprintf("Headers: %s\n", strings.Join(AppSettings.Redaction.Headers, ", ")))
+	overview.WriteString(fmt.Sprintf("Query Parameters: %s\n", strings.Join(AppSettings.Redaction.QueryParams, ", ")))
+	overview.WriteString(fmt.Sprintf("Body Paths: %s\n", strings.Join(AppSettings.Redaction.BodyPaths, ", ")))
+	overview.WriteString("\n")
+
 	overview.WriteString("═══════════════════════════════════════")
 	overview.WriteString(fmt.Sprintf("\nLast Updated: %s", AppSettings.LastSaved.Format("2006-01-02 15:04:05")))
 
@@ -671,6 +840,14 @@ func showSettingsOverview() {
 }
 
 func askContinueOrReturnSettings() {
+	if !reflect.DeepEqual(*AppSettings, savedSettings) {
+		if err := SaveSettings(); err != nil {
+			utils.ShowError("Failed to save settings", err)
+		} else {
+			savedSettings = *AppSettings
+		}
+	}
+
 	utils.AskContinueOrReturn(
 		HandleSettingsManagement,
 		RunInteractiveMode,
@@ -699,7 +876,7 @@ func GetCurrentSettings() *model.GlobalSettings {
 	return AppSettings
 }
 
-// SaveSettings updates the last saved timestamp
-func SaveSettings() {
-	AppSettings.LastSaved = time.Now()
+// SaveSettings writes the current settings to disk
+func SaveSettings() error {
+	return hc.SaveSettings(AppSettings)
 }
@@ -2,19 +2,23 @@ package cliforms
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	yaml "gopkg.in/yaml.v2"
 	"io"
 	"net/http"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/huh"
 
+	"github.com/Esa824/apix/internal/converters"
 	hc "github.com/Esa824/apix/internal/http-client"
 	"github.com/Esa824/apix/internal/model"
+	"github.com/Esa824/apix/internal/runner"
 	"github.com/Esa824/apix/internal/utils"
 )
 
@@ -27,6 +31,8 @@ func HandleTemplatesAndHistory() {
 				Title("Templates & History:").Options(
 				huh.NewOption("Saved Templates", "saved-templates"),
 				huh.NewOption("Request History", "request-history"),
+				huh.NewOption("Run Collection", "run-collection"),
+				huh.NewOption("Run Template Tests", "run-tests"),
 				huh.NewOption("Back to Main Menu", "back"),
 			).
 				Value(&selectedOption),
@@ -48,6 +54,10 @@ func handleTemplatesHistorySelection(selection string) {
 		handleSavedTemplates()
 	case "request-history":
 		handleRequestHistory()
+	case "run-collection":
+		handleRunCollection()
+	case "run-tests":
+		handleRunTemplateTests()
 	case "back":
 		RunInteractiveMode()
 	default:
@@ -68,6 +78,8 @@ func handleSavedTemplates() {
 	// Add management options
 	options = append(options,
 		huh.NewOption("Create Templates From Swagger File", "create-templates-from-swagger-file"),
+		huh.NewOption("Import Postman Collection", "import-postman"),
+		huh.NewOption("Export Templates", "export-templates"),
 		huh.NewOption("Back", "back"),
 	)
 
@@ -94,6 +106,10 @@ func handleTemplateSelection(selection string) {
 	switch selection {
 	case "create-templates-from-swagger-file":
 		handleCreateTemplatesFromSwaggerFile()
+	case "import-postman":
+		handleImportPostman()
+	case "export-templates":
+		handleExportTemplates()
 	case "back":
 		HandleTemplatesAndHistory()
 	default:
@@ -118,6 +134,7 @@ func handleTemplateActions(template *model.Template) {
 				Options(
 					huh.NewOption("Execute Template", "execute"),
 					huh.NewOption("Edit Template", "edit"),
+					huh.NewOption("Copy as curl", "copy-curl"),
 					huh.NewOption("Delete Template", "delete"),
 					huh.NewOption("Back to Templates", "back"),
 				).
@@ -136,6 +153,8 @@ func handleTemplateActions(template *model.Template) {
 		executeTemplate(template)
 	case "edit":
 		editTemplate(template)
+	case "copy-curl":
+		copyAsCurl(*template)
 	case "delete":
 		deleteTemplate(template)
 	case "back":
@@ -279,7 +298,7 @@ func handleCreateTemplatesFromSwaggerFile() {
 		if pathMethods, ok := pathData.(map[string]interface{}); ok {
 			for method, methodData := range pathMethods {
 				method = strings.ToUpper(method)
-				if method == "GET" || method == "POST" || method == "PUT" || method == "DELETE" || method == "PATCH" {
+				if method == "GET" || method == "POST" || method == "PUT" || method == "DELETE" || method == "PATCH" || method == "HEAD" || method == "OPTIONS" {
 					if methodInfo, ok := methodData.(map[string]interface{}); ok {
 						summary := ""
 						if s, ok := methodInfo["summary"].(string); ok {
@@ -330,7 +349,7 @@ func handleCreateTemplatesFromSwaggerFile() {
 	}
 
 	// Step 6: Parse security definitions
-	var globalAuth *string
+	var globalAuth *model.Auth
 	var securityDefs map[string]interface{}
 
 	// Swagger 2.0 security definitions
@@ -350,9 +369,8 @@ func handleCreateTemplatesFromSwaggerFile() {
 		if secReq, ok := security[0].(map[string]interface{}); ok {
 			for secName := range secReq {
 				if secDef, ok := securityDefs[secName].(map[string]interface{}); ok {
-					authType := parseAuthType(secDef)
-					if authType != "" {
-						globalAuth = &authType
+					if auth := parseAuthType(secDef); auth != nil {
+						globalAuth = auth
 						break
 					}
 				}
@@ -401,16 +419,15 @@ func handleCreateTemplatesFromSwaggerFile() {
 		}
 
 		// Determine auth type for this endpoint
-		var endpointAuth *string
+		var endpointAuth *model.Auth
 
 		// Check endpoint-specific security (standard security definitions)
 		if security, ok := methodInfo["security"].([]interface{}); ok && len(security) > 0 {
 			if secReq, ok := security[0].(map[string]interface{}); ok {
 				for secName := range secReq {
 					if secDef, ok := securityDefs[secName].(map[string]interface{}); ok {
-						authType := parseAuthType(secDef)
-						if authType != "" {
-							endpointAuth = &authType
+						if auth := parseAuthType(secDef); auth != nil {
+							endpointAuth = auth
 							break
 						}
 					}
@@ -433,14 +450,12 @@ func handleCreateTemplatesFromSwaggerFile() {
 							if desc, ok := paramMap["description"].(string); ok {
 								if strings.Contains(strings.ToLower(desc), "jwt") ||
 									strings.Contains(strings.ToLower(desc), "bearer") {
-									bearerAuth := "bearer"
-									endpointAuth = &bearerAuth
+									endpointAuth = &model.Auth{Type: "bearer"}
 									break
 								}
 							}
 							// Default to bearer token for Authorization header
-							bearerAuth := "bearer"
-							endpointAuth = &bearerAuth
+							endpointAuth = &model.Auth{Type: "bearer"}
 							break
 						}
 					}
@@ -492,7 +507,8 @@ func handleCreateTemplatesFromSwaggerFile() {
 		}
 
 		if endpointAuth != nil {
-			template.Auth = &model.Auth{Type: *endpointAuth}
+			auth := *endpointAuth
+			template.Auth = &auth
 		}
 
 		err := hc.SaveTemplate(template)
@@ -509,32 +525,34 @@ func handleCreateTemplatesFromSwaggerFile() {
 	askContinueOrReturnTemplates()
 }
 
-// Helper function to parse auth type from security definition
-func parseAuthType(secDef map[string]interface{}) string {
+// Helper function to parse auth from security definition. API keys keep their name and
+// location; the credentials are asked for when the template runs.
+func parseAuthType(secDef map[string]interface{}) *model.Auth {
 	if authType, ok := secDef["type"].(string); ok {
 		switch authType {
 		case "apiKey":
-			if in, ok := secDef["in"].(string); ok {
-				switch in {
-				case "header":
-					return "apikey"
-				case "query":
-					return "apikey"
-				}
+			name, _ := secDef["name"].(string)
+			location, _ := secDef["in"].(string)
+			switch location {
+			case "header", "query", "cookie":
+			default:
+				location = ""
 			}
-			return "apikey" // default
+			return &model.Auth{Type: "apikey", Primary: name, Location: location}
 		case "http":
 			if scheme, ok := secDef["scheme"].(string); ok {
-				switch scheme {
+				switch strings.ToLower(scheme) {
 				case "basic":
-					return "basic"
+					return &model.Auth{Type: "basic"}
+				case "digest":
+					return &model.Auth{Type: "digest"}
 				case "bearer":
-					return "bearer"
+					return &model.Auth{Type: "bearer"}
 				}
 			}
-			return "bearer" // default for http
+			return &model.Auth{Type: "bearer"} // default for http
 		case "oauth2":
-			return "oauth2"
+			return &model.Auth{Type: "oauth2"}
 		}
 	}
 
@@ -542,13 +560,13 @@ func parseAuthType(secDef map[string]interface{}) string {
 	if authType, ok := secDef["type"].(string); ok {
 		switch authType {
 		case "basic":
-			return "basic"
+			return &model.Auth{Type: "basic"}
 		case "oauth2":
-			return "oauth2"
+			return &model.Auth{Type: "oauth2"}
 		}
 	}
 
-	return ""
+	return nil
 }
 
 // Helper function to generate sample request body from schema
@@ -679,13 +697,17 @@ func resolveReference(ref string, swaggerData map[string]interface{}) map[string
 func handleRequestHistory() {
 	var selectedOption string
 
-	// TODO: Replace with actual request history from storage
 	history, err := hc.GetHistory()
+	if err != nil {
+		utils.ShowError("Failed to load request history", err)
+	}
 
+	// Newest first
 	options := []huh.Option[string]{}
-	for _, request := range history {
-		label := fmt.Sprintf("%s %s - %s", request.Method, request.URL, utils.FormatTime(request.Time))
-		options = append(options, huh.NewOption(label, strconv.Itoa(request.Id)))
+	for i := len(history) - 1; i >= 0; i-- {
+		entry := history[i]
+		label := fmt.Sprintf("%s %s [%s] - %s", entry.Method, entry.URL, historyOutcome(entry), utils.FormatTime(entry.Time))
+		options = append(options, huh.NewOption(label, strconv.Itoa(entry.Id)))
 	}
 
 	// Add management options
@@ -724,26 +746,42 @@ func handleHistorySelection(selection string) {
 		if err != nil {
 			return
 		}
-		history, err := hc.GetHistory()
+		entry, err := hc.GetHistoryEntry(id)
 		if err != nil {
+			utils.ShowError("Failed to load history entry", err)
+			askContinueOrReturnTemplates()
 			return
 		}
-		handleHistoryActions(&history[id])
+		handleHistoryActions(entry)
 		return
 	}
 }
 
-func handleHistoryActions(historyItem *hc.RequestOptions) {
+// historyOutcome summarises how a history entry went: its status code and duration, or ERROR
+func historyOutcome(entry hc.HistoryEntry) string {
+	switch {
+	case entry.Failed():
+		return "ERROR"
+	case entry.StatusCode == 0:
+		return "no response recorded"
+	default:
+		return fmt.Sprintf("%d, %s", entry.StatusCode, entry.Duration.Round(time.Millisecond))
+	}
+}
+
+func handleHistoryActions(entry *hc.HistoryEntry) {
 	var selectedAction string
+	historyItem := &entry.RequestOptions
 
 	form := huh.NewForm(
 		huh.NewGroup(
 			huh.NewSelect[string]().
 				Title(fmt.Sprintf("Request: %s %s", historyItem.Method, historyItem.URL)).
-				Description(fmt.Sprintf("Executed: %s", utils.FormatTime(historyItem.Time))).
+				Description(fmt.Sprintf("Executed: %s (%s)", utils.FormatTime(historyItem.Time), historyOutcome(*entry))).
 				Options(
 					huh.NewOption("Re-execute Request", "reexecute"),
 					huh.NewOption("Save as Template", "save-template"),
+					huh.NewOption("Copy as curl", "copy-curl"),
 					huh.NewOption("View Details", "view-details"),
 					huh.NewOption("Back to History", "back"),
 				).
@@ -762,8 +800,10 @@ func handleHistoryActions(historyItem *hc.RequestOptions) {
 		reExecuteFromHistory(historyItem)
 	case "save-template":
 		saveHistoryAsTemplate(historyItem)
+	case "copy-curl":
+		copyAsCurl(historyTemplate(historyItem))
 	case "view-details":
-		viewHistoryDetails(historyItem)
+		viewHistoryDetails(entry)
 	case "back":
 		handleRequestHistory()
 	}
@@ -790,9 +830,12 @@ func handleClearHistory() {
 	}
 
 	if confirmClear {
-		hc.DeleteHistory()
 		fmt.Println("Clearing request history...")
-		fmt.Println("Request history cleared successfully!")
+		if err := hc.DeleteHistory(); err != nil {
+			utils.ShowError("Failed to clear request history", err)
+		} else {
+			fmt.Println("Request history cleared successfully!")
+		}
 	} else {
 		fmt.Println("History clearing cancelled.")
 	}
@@ -835,6 +878,7 @@ func executeTemplate(template *model.Template) {
 		Body:        body,
 		Headers:     headers,
 		QueryParams: queryParams,
+		FormData:    template.FormData,
 		Time:        time.Now(),
 	}
 
@@ -851,10 +895,42 @@ func executeTemplate(template *model.Template) {
 	}
 
 	// Execute request and handle response
-	response, _ := hc.NewClient(10*time.Second).Do(options, false)
+	response, err := hc.NewClient(hc.RequestTimeout()).Do(options, false)
+	if err != nil {
+		utils.ShowError("Error while executing template", err)
+		askContinueOrReturnTemplates()
+		return
+	}
+
+	if len(template.Captures) > 0 {
+		applyCaptures(template, utils.ParseResponse(response))
+	}
 	utils.HandleResponse(response, HandleTemplatesAndHistory, RunInteractiveMode, "Continue with templates & history", "Return to Main Menu")
 }
 
+// applyCaptures stores the values a template captures from its response as variables
+func applyCaptures(template *model.Template, response *model.HTTPResponse) {
+	values, captureErr := utils.ExtractCaptures(response, template.Captures)
+
+	if len(values) > 0 {
+		// Without an active environment the values last for the rest of this session
+		if err := hc.SetVariables(values); err != nil && !errors.Is(err, hc.ErrNoActiveEnvironment) {
+			utils.ShowError("Failed to save captured variables", err)
+		} else {
+			names := make([]string, 0, len(values))
+			for name := range values {
+				names = append(names, "{{"+name+"}}")
+			}
+			sort.Strings(names)
+			utils.ShowSuccess(fmt.Sprintf("Captured %s", strings.Join(names, ", ")))
+		}
+	}
+
+	if captureErr != nil {
+		utils.ShowWarning(fmt.Sprintf("Some captures failed:\n%v", captureErr))
+	}
+}
+
 func editTemplate(template *model.Template) {
 	// Create a copy of the template to edit
 	editedTemplate := *template
@@ -881,6 +957,8 @@ func editTemplate(template *model.Template) {
 					huh.NewOption("PUT", "PUT"),
 					huh.NewOption("DELETE", "DELETE"),
 					huh.NewOption("PATCH", "PATCH"),
+					huh.NewOption("HEAD", "HEAD"),
+					huh.NewOption("OPTIONS", "OPTIONS"),
 				).
 				Value(&editedTemplate.Method),
 			huh.NewInput().
@@ -1001,14 +1079,18 @@ func editTemplate(template *model.Template) {
 	if editAuth {
 		var authType string
 		authOptions := []huh.Option[string]{
+			huh.NewOption("Default (the active auth profile)", "default"),
 			huh.NewOption("No Authentication", "none"),
 			huh.NewOption("Bearer Token", "bearer"),
 			huh.NewOption("API Key", "apikey"),
 			huh.NewOption("Basic Authentication", "basic"),
+			huh.NewOption("Digest Authentication", "digest"),
+			huh.NewOption("AWS Signature V4", "aws"),
+			huh.NewOption("HMAC Signature", "hmac"),
 		}
 
 		// Set current auth type as default
-		currentAuthType := "none"
+		currentAuthType := "default"
 		if editedTemplate.Auth != nil {
 			currentAuthType = editedTemplate.Auth.Type
 		}
@@ -1031,9 +1113,13 @@ func editTemplate(template *model.Template) {
 			return
 		}
 
-		if authType == "none" {
+		switch authType {
+		case "default":
 			editedTemplate.Auth = nil
-		} else {
+		case "none":
+			// Keeps the active profile from being attached
+			editedTemplate.Auth = &model.Auth{Type: "none"}
+		default:
 			// Get current auth details if they exist
 			currentAuth := model.Auth{Type: authType}
 			if editedTemplate.Auth != nil && editedTemplate.Auth.Type == authType {
@@ -1041,16 +1127,61 @@ func editTemplate(template *model.Template) {
 			}
 
 			// Use existing authentication handler with current values
-			if newAuthType, newAuthValue := handleAuthentication(currentAuth); newAuthType != "" {
-				editedTemplate.Auth = &model.Auth{
-					Type:    newAuthType,
-					Primary: newAuthValue,
-				}
+			if newAuthType, newAuthValue := handleAuthentication(currentAuth); newAuthType != "" && newAuthValue != "" {
+				var options hc.RequestOptions
+				applyAuthentication(&options, newAuthType, newAuthValue)
+				editedTemplate.Auth = options.Auth
 			}
 		}
 	}
 
-	// Step 6: Confirm changes
+	// Step 6: Edit captures
+	var editCaptures bool
+	form = huh.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title("Edit Captures?").
+				Description("Captures store response values as variables, e.g. token = body.data.access_token or id = header.Location").
+				Affirmative("Yes").
+				Negative("No").
+				Value(&editCaptures),
+		),
+	)
+
+	err = form.Run()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	if editCaptures {
+		editedTemplate.Captures = utils.CollectKeyValuePairs("Capture", "token", "body.data.access_token", editedTemplate.Captures)
+	}
+
+	// Step 7: Edit assertions
+	var editAssertions bool
+	form = huh.NewForm(
+		huh.NewGroup(
+			huh.NewConfirm().
+				Title("Edit Assertions?").
+				Description(fmt.Sprintf("Assertions turn this template into a test for 'apix test' (%d configured)", len(editedTemplate.Assertions))).
+				Affirmative("Yes").
+				Negative("No").
+				Value(&editAssertions),
+		),
+	)
+
+	err = form.Run()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	if editAssertions {
+		editedTemplate.Assertions = handleAssertions(editedTemplate.Assertions)
+	}
+
+	// Step 8: Confirm changes
 	var confirmSave bool
 	form = huh.NewForm(
 		huh.NewGroup(
@@ -1083,6 +1214,172 @@ func editTemplate(template *model.Template) {
 	askContinueOrReturnTemplates()
 }
 
+// handleAssertions lets the user add and remove assertions, returning the updated list
+func handleAssertions(assertions []model.Assertion) []model.Assertion {
+	for {
+		options := []utils.SelectionOption{}
+		for i, assertion := range assertions {
+			options = append(options, utils.SelectionOption{Label: fmt.Sprintf("Remove: %s", runner.Describe(assertion)), Value: strconv.Itoa(i)})
+		}
+		options = append(options,
+			utils.SelectionOption{Label: "Add Assertion", Value: "add"},
+			utils.SelectionOption{Label: "Done", Value: "done"},
+		)
+
+		selection, err := utils.AskSelection(fmt.Sprintf("Assertions (%d):", len(assertions)), options)
+		if err != nil || selection == "done" {
+			return assertions
+		}
+
+		if selection == "add" {
+			if assertion, ok := askAssertion(); ok {
+				assertions = append(assertions, assertion)
+			}
+			continue
+		}
+
+		index, _ := strconv.Atoi(selection)
+		assertions = append(assertions[:index], assertions[index+1:]...)
+	}
+}
+
+// askAssertion prompts for a single assertion
+func askAssertion() (model.Assertion, bool) {
+	typeOptions := []utils.SelectionOption{
+		{Label: "Status Code", Value: "status"},
+		{Label: "Header", Value: "header"},
+		{Label: "JSON Path", Value: "json"},
+		{Label: "Response Time", Value: "response_time"},
+		{Label: "Body", Value: "body"},
+	}
+
+	assertionType, err := utils.AskSelection("Assertion Type:", typeOptions)
+	if err != nil {
+		return model.Assertion{}, false
+	}
+
+	assertion := model.Assertion{Type: assertionType}
+
+	var operatorOptions []utils.SelectionOption
+	switch assertionType {
+	case "status":
+		operatorOptions = []utils.SelectionOption{{Label: "Equals", Value: "equals"}, {Label: "In Range (200-299, 2xx, 200,201)", Value: "in"}}
+	case "header", "json":
+		targetConfig := utils.InputConfig{Title: "Header Name:", Placeholder: "Content-Type", Required: true}
+		if assertionType == "json" {
+			targetConfig = utils.InputConfig{Title: "JSON Path:", Placeholder: "data.id", Required: true}
+		}
+
+		target, err := utils.AskInput(targetConfig)
+		if err != nil {
+			return model.Assertion{}, false
+		}
+		assertion.Target = strings.TrimSpace(target)
+		operatorOptions = []utils.SelectionOption{{Label: "Exists", Value: "exists"}, {Label: "Equals", Value: "equals"}, {Label: "Contains", Value: "contains"}, {Label: "Matches Regex", Value: "matches"}}
+	case "response_time":
+		operatorOptions = []utils.SelectionOption{{Label: "Less Than (ms)", Value: "less_than"}}
+	case "body":
+		operatorOptions = []utils.SelectionOption{{Label: "Contains", Value: "contains"}, {Label: "Equals", Value: "equals"}, {Label: "Matches Regex", Value: "matches"}}
+	}
+
+	assertion.Operator, err = utils.AskSelection("Operator:", operatorOptions)
+	if err != nil {
+		return model.Assertion{}, false
+	}
+
+	if assertion.Operator != "exists" {
+		value, err := utils.AskInput(utils.InputConfig{
+			Title:    "Expected Value:",
+			Required: true,
+		})
+		if err != nil {
+			return model.Assertion{}, false
+		}
+		assertion.Value = value
+	}
+
+	return assertion, true
+}
+
+// handleRunCollection runs the templates of a collection in order and shows the results
+func handleRunCollection() {
+	paths, err := hc.GetCollectionPaths()
+	if err != nil {
+		utils.ShowError("Error loading collections", err)
+		return
+	}
+
+	if len(paths) == 0 {
+		utils.ShowMessage("No collections found. Create a folder inside the templates directory to start one.")
+		askContinueOrReturnTemplates()
+		return
+	}
+
+	options := make([]utils.SelectionOption, 0, len(paths))
+	for _, path := range paths {
+		options = append(options, utils.SelectionOption{Label: path, Value: path})
+	}
+
+	selected, err := utils.AskSelection("Select Collection to Run:", options)
+	if err != nil {
+		utils.ShowError("Error selecting collection", err)
+		return
+	}
+
+	collection, err := hc.LoadCollection(selected)
+	if err != nil {
+		utils.ShowError("Error loading collection", err)
+		askContinueOrReturnTemplates()
+		return
+	}
+
+	templates := hc.CollectionTemplates(*collection)
+	if len(templates) == 0 {
+		utils.ShowMessage(fmt.Sprintf("Collection '%s' has no templates.", selected))
+		askContinueOrReturnTemplates()
+		return
+	}
+
+	summary := runner.Run(hc.NewClient(hc.RequestTimeout()), templates, false)
+
+	var report strings.Builder
+	runner.WriteSummary(&report, summary)
+	utils.DisplayFormattedText(fmt.Sprintf("Collection: %s", selected), report.String())
+	askContinueOrReturnTemplates()
+}
+
+// handleRunTemplateTests runs every template that has assertions and shows the summary
+func handleRunTemplateTests() {
+	templates, err := hc.GetTemplates()
+	if err != nil {
+		utils.ShowError("Error loading templates", err)
+		return
+	}
+
+	var tests []model.Template
+	for _, template := range templates {
+		if len(template.Assertions) > 0 {
+			tests = append(tests, template)
+		}
+	}
+	sort.Slice(tests, func(i, j int) bool {
+		return tests[i].Name < tests[j].Name
+	})
+
+	if len(tests) == 0 {
+		utils.ShowMessage("No templates with assertions found. Add assertions by editing a template.")
+		askContinueOrReturnTemplates()
+		return
+	}
+
+	summary := runner.Run(hc.NewClient(hc.RequestTimeout()), tests, false)
+
+	var report strings.Builder
+	runner.WriteSummary(&report, summary)
+	utils.DisplayFormattedText("Test Results", report.String())
+	askContinueOrReturnTemplates()
+}
+
 func deleteTemplate(template *model.Template) {
 	var confirmDelete bool
 
@@ -1112,7 +1409,12 @@ func deleteTemplate(template *model.Template) {
 
 func reExecuteFromHistory(historyItem *hc.RequestOptions) {
 	fmt.Printf("Re-executing request: %s %s\n", historyItem.Method, historyItem.URL)
-	response, _ := hc.NewClient(10*time.Second).Do(*historyItem, false)
+	response, err := hc.NewClient(hc.RequestTimeout()).Do(*historyItem, false)
+	if err != nil {
+		utils.ShowError("Error while re-executing request", err)
+		askContinueOrReturnTemplates()
+		return
+	}
 	utils.HandleResponse(response, HandleTemplatesAndHistory, RunInteractiveMode, "Continue with templates & history", "Return to Main Menu")
 }
 
@@ -1127,37 +1429,87 @@ func saveHistoryAsTemplate(opts *hc.RequestOptions) {
 		fmt.Printf("Error: %v\n", err)
 		return
 	}
-	hc.SaveTemplate(model.Template{
-		Name:        templateName,
+	template := historyTemplate(opts)
+	template.Name = templateName
+	hc.SaveTemplate(template)
+	askContinueOrReturnTemplates()
+}
+
+// historyTemplate converts a history entry into an unnamed template
+func historyTemplate(opts *hc.RequestOptions) model.Template {
+	return model.Template{
 		Method:      opts.Method,
 		URL:         opts.URL,
 		Headers:     opts.Headers,
 		QueryParams: opts.QueryParams,
 		Files:       opts.Files,
+		FormData:    opts.FormData,
 		Auth:        opts.Auth,
 		Body:        opts.Body,
-	})
-	askContinueOrReturnTemplates()
+	}
 }
 
-func viewHistoryDetails(historyItem *hc.RequestOptions) {
-	fmt.Println("📋 Request Details:")
-	fmt.Println("─────────────────────────")
-	fmt.Printf("Method: %s\n", historyItem.Method)
-	fmt.Printf("URL: %s\n", historyItem.URL)
-	fmt.Printf("Timestamp: %s\n", "")
-	fmt.Printf("Status: %s\n", "")
-	if historyItem.Body != "" {
-		fmt.Printf("Body: %s\n", historyItem.Body)
+// copyAsCurl shows the curl equivalent of a request and copies it to the clipboard
+func copyAsCurl(template model.Template) {
+	command := converters.ToCurl(template)
+
+	title := "curl (copied to clipboard)"
+	if err := utils.CopyToClipboard(command); err != nil {
+		title = fmt.Sprintf("curl (%v)", err)
 	}
-	if len(historyItem.Headers) == 0 {
-		fmt.Printf("Headers: %s\n", historyItem.Headers)
+	utils.DisplayFormattedText(title, command)
+	askContinueOrReturnTemplates()
+}
+
+func viewHistoryDetails(entry *hc.HistoryEntry) {
+	var details strings.Builder
+	fmt.Fprintf(&details, "Method: %s\n", entry.Method)
+	fmt.Fprintf(&details, "URL: %s\n", entry.URL)
+	fmt.Fprintf(&details, "Timestamp: %s\n", utils.FormatTime(entry.Time))
+	if entry.Failed() {
+		fmt.Fprintf(&details, "Error: %s\n", entry.Error)
+	} else if entry.StatusCode != 0 {
+		fmt.Fprintf(&details, "Status: %s\n", entry.Status)
+		fmt.Fprintf(&details, "Duration: %s\n", entry.Duration.Round(time.Millisecond))
+		fmt.Fprintf(&details, "Size: %d bytes\n", entry.Size)
+	}
+
+	writeHistoryPairs(&details, "Request Headers", entry.Headers)
+	writeHistoryPairs(&details, "Query Parameters", entry.QueryParams)
+	if entry.Body != nil && entry.Body != "" {
+		fmt.Fprintf(&details, "\nRequest Body:\n%v\n", entry.Body)
+	}
+
+	writeHistoryPairs(&details, "Response Headers", entry.ResponseHeaders)
+	if entry.ResponseBody != "" {
+		body, _ := utils.FormatJSON([]byte(entry.ResponseBody))
+		fmt.Fprintf(&details, "\nResponse Body:\n%s\n", body)
+		if entry.BodyTruncated {
+			fmt.Fprintf(&details, "... (truncated, %d of %d bytes shown)\n", len(entry.ResponseBody), entry.Size)
+		}
 	}
-	fmt.Println("─────────────────────────")
 
+	utils.DisplayFormattedText("📋 Request Details", details.String())
 	askContinueOrReturnTemplates()
 }
 
+// writeHistoryPairs writes a titled, sorted list of key-value pairs, skipping empty maps
+func writeHistoryPairs(w io.Writer, title string, pairs map[string]string) {
+	if len(pairs) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(pairs))
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintf(w, "\n%s:\n", title)
+	for _, key := range keys {
+		fmt.Fprintf(w, "  %s: %s\n", key, pairs[key])
+	}
+}
+
 func askContinueOrReturnTemplates() {
 	var choice string
 
@@ -0,0 +1,177 @@
+package cobracommands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	hc "github.com/Esa824/apix/internal/http-client"
+	"github.com/Esa824/apix/internal/utils"
+)
+
+// newPassphraseEnv supplies the new passphrase to `apix auth encrypt` in scripts
+const newPassphraseEnv = "APIX_NEW_PASSPHRASE"
+
+var AuthCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage auth profiles",
+	Long: `Auth profiles hold tokens, passwords and API keys. They can be encrypted at rest with a
+passphrase, which scripts supply in $APIX_PASSPHRASE.`,
+}
+
+var authEncryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt auth profiles with a passphrase, or change the passphrase",
+	Long: `Encrypts every auth profile under a new passphrase. Plaintext profiles are migrated; if the
+profiles are already encrypted they are re-encrypted, which rotates the passphrase.
+
+The current passphrase is read from $APIX_PASSPHRASE and the new one from $APIX_NEW_PASSPHRASE,
+and both are prompted for when unset.`,
+	Example: `  apix auth encrypt
+  APIX_PASSPHRASE=old APIX_NEW_PASSPHRASE=new apix auth encrypt`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		if err := unlockProfiles(); err != nil {
+			return err
+		}
+
+		rotating := hc.AuthProfilesEncrypted()
+		passphrase := os.Getenv(newPassphraseEnv)
+		if passphrase == "" {
+			var err error
+			if passphrase, err = askNewPassphrase(); err != nil {
+				return err
+			}
+		}
+
+		count, err := hc.EncryptAuthProfiles(passphrase)
+		if err != nil {
+			return err
+		}
+		if rotating {
+			fmt.Fprintf(cmd.OutOrStdout(), "Re-encrypted %d auth profile(s) with the new passphrase\n", count)
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "Encrypted %d auth profile(s)\n", count)
+		}
+		return nil
+	},
+}
+
+var authDecryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Store auth profiles as plaintext again",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		if !hc.AuthProfilesEncrypted() {
+			fmt.Fprintln(cmd.OutOrStdout(), "Auth profiles are not encrypted")
+			return nil
+		}
+		if err := unlockProfiles(); err != nil {
+			return err
+		}
+
+		count, err := hc.DecryptAuthProfiles()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Decrypted %d auth profile(s)\n", count)
+		return nil
+	},
+}
+
+var authLoginCmd = &cobra.Command{
+	Use:   "login <profile>",
+	Short: "Fetch a new token for an OAuth 2.0 profile or a bearer profile with a login template",
+	Long: `Fetches a new access token and caches it in the profile. OAuth 2.0 profiles use their grant; for
+the authorization code grant, the authorization URL is opened in the browser and the code is received
+on a loopback redirect URI (http://127.0.0.1:<port>/callback). Bearer profiles run their login template.
+
+Tokens are renewed automatically when requests use the profile, so logging in is only needed
+for the authorization code grant or when a refresh token has expired.`,
+	Example: `  apix auth login github`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		if err := unlockProfiles(); err != nil {
+			return err
+		}
+		profile, err := hc.GetAuthProfile(args[0])
+		if err != nil {
+			return err
+		}
+		switch {
+		case profile.Type == "oauth":
+			openURL := func(url string) {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Open this URL in your browser to log in:\n\n  %s\n\nWaiting for the redirect...\n", url)
+				utils.OpenBrowser(url)
+			}
+			if err := hc.LoginOAuth(cmd.Context(), profile, openURL); err != nil {
+				return err
+			}
+			if err := hc.SaveAuthProfile(profile); err != nil {
+				return err
+			}
+		case profile.Type == "bearer" && profile.LoginTemplate != "":
+			if err := hc.RunLoginTemplate(profile); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("auth profile '%s' is neither an OAuth 2.0 profile nor a bearer profile with a login template", profile.Name)
+		}
+
+		if profile.Expiry != nil {
+			fmt.Fprintf(cmd.OutOrStdout(), "Logged in to '%s'; the token expires at %s\n", profile.Name, profile.Expiry.Local().Format("2006-01-02 15:04:05"))
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "Logged in to '%s'\n", profile.Name)
+		}
+		return nil
+	},
+}
+
+// unlockProfiles unlocks encrypted auth profiles with $APIX_PASSPHRASE, prompting for it when unset
+func unlockProfiles() error {
+	if !hc.AuthProfilesLocked() {
+		return nil
+	}
+	if os.Getenv(hc.PassphraseEnv) != "" {
+		// AuthProfilesLocked already tried it
+		return fmt.Errorf("failed to unlock auth profiles with %s: %w", hc.PassphraseEnv, hc.ErrWrongPassphrase)
+	}
+
+	passphrase, err := utils.AskInput(utils.InputConfig{
+		Title:    "Current passphrase:",
+		Password: true,
+		Required: true,
+	})
+	if err != nil {
+		return err
+	}
+	return hc.UnlockAuthProfiles(passphrase)
+}
+
+func askNewPassphrase() (string, error) {
+	values, err := utils.AskMultipleInputs([]utils.InputConfig{
+		{Title: "New passphrase:", Password: true, Required: true},
+		{Title: "Confirm passphrase:", Password: true, Required: true},
+	})
+	if err != nil {
+		return "", err
+	}
+	if values[0] != values[1] {
+		return "", errors.New("passphrases do not match")
+	}
+	return values[0], nil
+}
+
+func init() {
+	AuthCmd.AddCommand(authEncryptCmd)
+	AuthCmd.AddCommand(authDecryptCmd)
+	AuthCmd.AddCommand(authLoginCmd)
+}
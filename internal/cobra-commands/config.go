@@ -0,0 +1,71 @@
+package cobracommands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	hc "github.com/Esa824/apix/internal/http-client"
+)
+
+var ConfigCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Show and migrate the config directory",
+}
+
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the config directory",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		_, err := fmt.Fprintln(cmd.OutOrStdout(), hc.ConfigPath)
+		return err
+	},
+}
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate [dir...]",
+	Short: "Move files from the config locations of earlier versions into the config directory",
+	Long: `Earlier versions kept their files in ./.config and ./testconfigs/config1, relative to the
+directory apix ran from. This moves the settings, history, templates and auth profiles found there,
+or in the given directories, into the config directory.
+
+Only files that parse as apix data are moved. Other files, and files that already exist in the
+config directory, are left where they are.`,
+	Example: `  apix config migrate --dry-run
+  apix config migrate ~/old-project/.config`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		moved, skipped, err := hc.MigrateLegacyConfig(args, dryRun)
+
+		verb := "Moved"
+		if dryRun {
+			verb = "Would move"
+		}
+		for _, path := range moved {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", verb, path)
+		}
+		for _, path := range skipped {
+			fmt.Fprintf(cmd.OutOrStdout(), "Skipped %s\n", path)
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(moved) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No apix files found to migrate")
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %d file(s) to %s\n", verb, len(moved), hc.ConfigPath)
+		}
+		return nil
+	},
+}
+
+func init() {
+	configMigrateCmd.Flags().Bool("dry-run", false, "List the files that would be moved without moving them")
+	ConfigCmd.AddCommand(configPathCmd)
+	ConfigCmd.AddCommand(configMigrateCmd)
+}
@@ -11,11 +11,12 @@ var DeleteCmd = &cobra.Command{
 	Use:   "delete [URL]",
 	Short: "Make a DELETE request to the specified URL",
 	Long:  `Make a DELETE request to the specified URL with optional headers and parameters.`,
-	Args:  cobra.MinimumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("Making DELETE request to: %s\n", args[0])
-		// TODO: Implement DELETE request logic
-	},
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRequest("DELETE"),
+}
+
+func init() {
+	addRequestFlags(DeleteCmd)
 }
 
 func HandleDeleteRequest() {
@@ -0,0 +1,194 @@
+package cobracommands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	hc "github.com/Esa824/apix/internal/http-client"
+	"github.com/Esa824/apix/internal/model"
+)
+
+var EnvCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Manage environments and their variables",
+	Long: `Environments hold variables that are substituted into {{name}} placeholders in request
+URLs, headers, query parameters, bodies and auth. The active environment is used unless --env is given.`,
+}
+
+var envListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List environments",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		environments, err := hc.GetEnvironments()
+		if err != nil {
+			return err
+		}
+
+		if len(environments) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No environments found")
+			return nil
+		}
+
+		for _, environment := range environments {
+			marker := " "
+			if environment.Active {
+				marker = "*"
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %s (%d variables)\n", marker, environment.Name, len(environment.Variables))
+		}
+		return nil
+	},
+}
+
+var envShowCmd = &cobra.Command{
+	Use:               "show [name]",
+	Short:             "Show the variables of an environment (the active one by default)",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeEnvironments,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var environment *model.Environment
+		var err error
+		if len(args) == 1 {
+			environment, err = hc.GetEnvironment(args[0])
+		} else {
+			environment, err = hc.GetActiveEnvironment()
+		}
+		if err != nil {
+			return err
+		}
+		if environment == nil {
+			return fmt.Errorf("no active environment, use 'apix env use <name>'")
+		}
+
+		names := make([]string, 0, len(environment.Variables))
+		for name := range environment.Variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		fmt.Fprintf(cmd.OutOrStdout(), "%s\n", environment.Name)
+		for _, name := range names {
+			fmt.Fprintf(cmd.OutOrStdout(), "  %s = %s\n", name, environment.Variables[name])
+		}
+		return nil
+	},
+}
+
+var envUseCmd = &cobra.Command{
+	Use:               "use [name]",
+	Short:             "Set the active environment (no name clears it)",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeEnvironments,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := ""
+		if len(args) == 1 {
+			name = args[0]
+		}
+
+		if err := hc.SetActiveEnvironment(name); err != nil {
+			return err
+		}
+
+		if name == "" {
+			fmt.Fprintln(cmd.OutOrStdout(), "No active environment")
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "Active environment: %s\n", name)
+		}
+		return nil
+	},
+}
+
+var envSetCmd = &cobra.Command{
+	Use:               "set [name] [key=value...]",
+	Short:             "Set variables in an environment, creating it if needed",
+	Args:              cobra.MinimumNArgs(2),
+	ValidArgsFunction: completeEnvironments,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		environment, err := hc.GetEnvironment(args[0])
+		if err != nil {
+			environment = &model.Environment{Name: args[0], Variables: make(map[string]string)}
+		}
+
+		for _, pair := range args[1:] {
+			key, value, found := strings.Cut(pair, "=")
+			if !found || key == "" {
+				return fmt.Errorf("invalid variable %q, expected 'key=value'", pair)
+			}
+			environment.Variables[key] = value
+		}
+
+		if err := hc.SaveEnvironment(*environment); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Updated environment %s\n", environment.Name)
+		return nil
+	},
+}
+
+var envUnsetCmd = &cobra.Command{
+	Use:               "unset [name] [key...]",
+	Short:             "Remove variables from an environment",
+	Args:              cobra.MinimumNArgs(2),
+	ValidArgsFunction: completeEnvironments,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		environment, err := hc.GetEnvironment(args[0])
+		if err != nil {
+			return err
+		}
+
+		for _, key := range args[1:] {
+			delete(environment.Variables, key)
+		}
+
+		if err := hc.SaveEnvironment(*environment); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Updated environment %s\n", environment.Name)
+		return nil
+	},
+}
+
+var envDeleteCmd = &cobra.Command{
+	Use:               "delete [name]",
+	Short:             "Delete an environment",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeEnvironments,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := hc.DeleteEnvironment(args[0]); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Deleted environment %s\n", args[0])
+		return nil
+	},
+}
+
+// completeEnvironments completes the first argument with environment names
+func completeEnvironments(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	environments, err := hc.GetEnvironments()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	names := make([]string, 0, len(environments))
+	for _, environment := range environments {
+		names = append(names, environment.Name)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	EnvCmd.AddCommand(envListCmd)
+	EnvCmd.AddCommand(envShowCmd)
+	EnvCmd.AddCommand(envUseCmd)
+	EnvCmd.AddCommand(envSetCmd)
+	EnvCmd.AddCommand(envUnsetCmd)
+	EnvCmd.AddCommand(envDeleteCmd)
+}
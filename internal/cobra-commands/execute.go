@@ -0,0 +1,343 @@
+package cobracommands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	hc "github.com/Esa824/apix/internal/http-client"
+	"github.com/Esa824/apix/internal/model"
+	"github.com/Esa824/apix/internal/runner"
+	"github.com/Esa824/apix/internal/utils"
+)
+
+// addRequestFlags registers the flags shared by all request commands
+func addRequestFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("data", "d", "", "Request body (prefix with @ to read it from a file)")
+	cmd.Flags().StringArrayP("header", "H", nil, "Request header in 'Key: Value' format (repeatable)")
+	cmd.Flags().StringArrayP("query", "q", nil, "Query parameter in 'key=value' format (repeatable)")
+	cmd.Flags().String("auth", "", "Authentication: bearer:<token>, basic:<user>:<password>, apikey:[query:|cookie:][<name>:]<key>, digest:<user>:<password>, aws:<access key>:<secret key>:<region>:<service> or hmac:[<key id>:]<secret>")
+	addProfileFlags(cmd)
+	cmd.Flags().Duration("timeout", 0, "Request timeout (defaults to the RequestTimeout setting)")
+	cmd.Flags().Bool("fail", false, "Exit with code 4 on 4xx and 5 on 5xx responses")
+	cmd.Flags().String("output-format", outputText, "Output format: text or json")
+	cmd.Flags().StringArray("capture", nil, "Store a response value as a variable: name=body.<path>, name=header.<Name> or name=status (repeatable)")
+	cmd.MarkFlagsMutuallyExclusive("auth", "profile", "no-auth")
+}
+
+// addProfileFlags registers the flags that choose the auth profile of a request
+func addProfileFlags(cmd *cobra.Command) {
+	cmd.Flags().String("profile", "", "Use this auth profile instead of the active one")
+	cmd.Flags().Bool("no-auth", false, "Send the request without auth, even if an auth profile is active")
+}
+
+// profileAuthFromFlags returns the auth chosen with --profile or --no-auth, or nil if neither was given
+func profileAuthFromFlags(cmd *cobra.Command) (*model.Auth, error) {
+	if noAuth, _ := cmd.Flags().GetBool("no-auth"); noAuth {
+		return &model.Auth{Type: "none"}, nil
+	}
+
+	name, _ := cmd.Flags().GetString("profile")
+	if name == "" {
+		return nil, nil
+	}
+	profile, err := hc.GetAuthProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	return hc.ProfileReference(profile), nil
+}
+
+// runRequest returns a cobra RunE function that executes a request with the given method
+func runRequest(method string) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		format, _ := cmd.Flags().GetString("output-format")
+		if err := validateOutputFormat(format); err != nil {
+			return err
+		}
+
+		options, err := requestOptionsFromFlags(cmd, method, args[0])
+		if err != nil {
+			return err
+		}
+		captures, err := captureFlags(cmd)
+		if err != nil {
+			return err
+		}
+
+		return sendRequest(cmd, options, captures, nil)
+	}
+}
+
+// sendRequest sends a request with the --timeout, --output-format and --fail flags of cmd and
+// prints the response. It then stores the captures and checks the assertions, so that ad hoc
+// requests and saved templates handle responses the same way.
+func sendRequest(cmd *cobra.Command, options hc.RequestOptions, captures map[string]string, assertions []model.Assertion) error {
+	format, _ := cmd.Flags().GetString("output-format")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	if timeout <= 0 {
+		timeout = hc.RequestTimeout()
+	}
+
+	// Report the URL that was actually requested, with variables substituted
+	resolved, err := hc.ResolveVariables(options)
+	if err != nil {
+		return err
+	}
+
+	response, err := hc.NewClient(timeout).Do(options, true)
+	if err != nil {
+		writeErrorOutput(cmd.OutOrStdout(), format, resolved, err)
+		return requestError(err)
+	}
+
+	httpResp := utils.ParseResponse(response)
+	if err := writeOutput(cmd.OutOrStdout(), format, resolved, httpResp); err != nil {
+		return err
+	}
+
+	// An error status decides the exit code, even when the captures then fail on its body
+	if fail, _ := cmd.Flags().GetBool("fail"); fail {
+		if err := statusError(httpResp.StatusCode, httpResp.Status); err != nil {
+			return err
+		}
+	}
+
+	captureErr := captureVariables(cmd, httpResp, captures)
+	if err := checkAssertions(cmd, format, httpResp, assertions); err != nil {
+		if captureErr != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %v\n", captureErr)
+		}
+		return err
+	}
+	return captureErr
+}
+
+// requestOptionsFromFlags builds request options from the command line flags
+func requestOptionsFromFlags(cmd *cobra.Command, method, url string) (hc.RequestOptions, error) {
+	options := hc.RequestOptions{
+		Method:      method,
+		URL:         url,
+		Headers:     make(map[string]string),
+		QueryParams: make(map[string]string),
+		Time:        time.Now(),
+	}
+
+	headers, _ := cmd.Flags().GetStringArray("header")
+	for _, header := range headers {
+		key, value, found := strings.Cut(header, ":")
+		if !found || strings.TrimSpace(key) == "" {
+			return options, fmt.Errorf("invalid header %q, expected 'Key: Value'", header)
+		}
+		options.Headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+
+	params, _ := cmd.Flags().GetStringArray("query")
+	for _, param := range params {
+		key, value, found := strings.Cut(param, "=")
+		if !found || key == "" {
+			return options, fmt.Errorf("invalid query parameter %q, expected 'key=value'", param)
+		}
+		options.QueryParams[key] = value
+	}
+
+	data, _ := cmd.Flags().GetString("data")
+	if data != "" {
+		body, err := readData(data)
+		if err != nil {
+			return options, err
+		}
+		options.Body = body
+
+		if json.Valid([]byte(body)) && !hasHeader(options.Headers, "Content-Type") {
+			options.Headers["Content-Type"] = "application/json"
+		}
+	}
+
+	authValue, _ := cmd.Flags().GetString("auth")
+	if authValue != "" {
+		auth, err := parseAuthFlag(authValue)
+		if err != nil {
+			return options, err
+		}
+		options.Auth = auth
+	}
+
+	auth, err := profileAuthFromFlags(cmd)
+	if err != nil {
+		return options, err
+	}
+	if auth != nil {
+		options.Auth = auth
+	}
+
+	return options, nil
+}
+
+// captureFlags parses the --capture flags into capture expressions by variable name
+func captureFlags(cmd *cobra.Command) (map[string]string, error) {
+	pairs, _ := cmd.Flags().GetStringArray("capture")
+	captures := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		name, expression, found := strings.Cut(pair, "=")
+		if !found || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid capture %q, expected 'name=expression'", pair)
+		}
+		captures[strings.TrimSpace(name)] = strings.TrimSpace(expression)
+	}
+	return captures, nil
+}
+
+// captureVariables stores the captured response values as variables. Values that were
+// extracted are saved even when others fail.
+func captureVariables(cmd *cobra.Command, response *model.HTTPResponse, captures map[string]string) error {
+	if len(captures) == 0 {
+		return nil
+	}
+
+	values, captureErr := utils.ExtractCaptures(response, captures)
+	if err := saveCaptures(cmd, values); err != nil {
+		return err
+	}
+	if captureErr != nil {
+		return fmt.Errorf("capture failed: %w", captureErr)
+	}
+	return nil
+}
+
+// checkAssertions evaluates assertions against the response, printing each result in text
+// output, and returns an exit code 1 error if any fails
+func checkAssertions(cmd *cobra.Command, format string, response *model.HTTPResponse, assertions []model.Assertion) error {
+	failed := 0
+	for _, assertion := range assertions {
+		result := runner.Evaluate(assertion, response)
+		if !result.Passed {
+			failed++
+		}
+		if format != outputText {
+			continue
+		}
+		if result.Passed {
+			fmt.Fprintf(cmd.OutOrStdout(), "PASS  %s\n", runner.Describe(assertion))
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "FAIL  %s\n", result.Message)
+		}
+	}
+	if failed > 0 {
+		return &ExitCodeError{
+			Code: ExitFailure,
+			Err:  fmt.Errorf("%d of %d assertions failed", failed, len(assertions)),
+		}
+	}
+	return nil
+}
+
+// saveCaptures stores captured values as variables, warning when no environment is active
+// to keep them for the commands that follow
+func saveCaptures(cmd *cobra.Command, values map[string]string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	err := hc.SetVariables(values)
+	if errors.Is(err, hc.ErrNoActiveEnvironment) {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %v\n", err)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save captured variables: %w", err)
+	}
+	return nil
+}
+
+// readData returns the request body, reading it from a file when prefixed with @
+func readData(data string) (string, error) {
+	if !strings.HasPrefix(data, "@") {
+		return data, nil
+	}
+
+	content, err := os.ReadFile(strings.TrimPrefix(data, "@"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read request body: %w", err)
+	}
+	return string(content), nil
+}
+
+// parseAuthFlag parses the --auth flag into an Auth value
+func parseAuthFlag(value string) (*model.Auth, error) {
+	authType, credentials, _ := strings.Cut(value, ":")
+	authType = strings.ToLower(authType)
+
+	switch authType {
+	case "bearer":
+		if credentials == "" {
+			return nil, fmt.Errorf("bearer auth requires a token: bearer:<token>")
+		}
+		return &model.Auth{Type: authType, Primary: credentials}, nil
+	case "basic":
+		username, password, found := strings.Cut(credentials, ":")
+		if !found || username == "" {
+			return nil, fmt.Errorf("basic auth requires credentials: basic:<user>:<password>")
+		}
+		return &model.Auth{Type: authType, Primary: username, Secondary: password}, nil
+	case "apikey":
+		// The key goes in a header unless the credentials start with query: or cookie:
+		var location string
+		if prefix, rest, found := strings.Cut(credentials, ":"); found && (prefix == "query" || prefix == "cookie") {
+			location, credentials = prefix, rest
+		}
+		if credentials == "" {
+			return nil, fmt.Errorf("API key auth requires a key: apikey:[query:|cookie:][<name>:]<key>")
+		}
+		name, key, found := strings.Cut(credentials, ":")
+		if !found {
+			name, key = hc.DefaultAPIKeyName(location), credentials
+		}
+		return &model.Auth{Type: authType, Primary: name, Secondary: key, Location: location}, nil
+	case "digest":
+		username, password, found := strings.Cut(credentials, ":")
+		if !found || username == "" {
+			return nil, fmt.Errorf("digest auth requires credentials: digest:<user>:<password>")
+		}
+		return &model.Auth{Type: authType, Primary: username, Secondary: password}, nil
+	case "aws":
+		parts := strings.Split(credentials, ":")
+		if len(parts) != 4 || slices.Contains(parts, "") {
+			return nil, fmt.Errorf("AWS auth requires credentials and scope: aws:<access key>:<secret key>:<region>:<service>")
+		}
+		return &model.Auth{
+			Type:      authType,
+			Primary:   parts[0],
+			Secondary: parts[1],
+			AWS:       &model.AWSSigning{Region: parts[2], Service: parts[3]},
+		}, nil
+	case "hmac":
+		keyID, secret, found := strings.Cut(credentials, ":")
+		if !found {
+			keyID, secret = "", credentials
+		}
+		if secret == "" {
+			return nil, fmt.Errorf("HMAC auth requires a secret: hmac:[<key id>:]<secret>")
+		}
+		return &model.Auth{Type: authType, Primary: keyID, Secondary: secret}, nil
+	default:
+		return nil, fmt.Errorf("unknown auth type %q (use bearer, basic, apikey, digest, aws or hmac)", authType)
+	}
+}
+
+// hasHeader reports whether headers contains name, ignoring case
+func hasHeader(headers map[string]string, name string) bool {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
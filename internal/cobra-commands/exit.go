@@ -0,0 +1,75 @@
+package cobracommands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	hc "github.com/Esa824/apix/internal/http-client"
+)
+
+// Process exit codes used in command mode
+const (
+	ExitOK        = 0
+	ExitFailure   = 1 // usage errors and anything not covered below
+	ExitTransport = 2 // the request could not be sent or no response was received
+	ExitTimeout   = 3 // the request timed out
+	ExitClientErr = 4 // 4xx response with --fail
+	ExitServerErr = 5 // 5xx response with --fail
+)
+
+// ExitCodeError carries the exit code the process should terminate with
+type ExitCodeError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitCodeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitCodeError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the process exit code for an error returned by a command
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var exitErr *ExitCodeError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	return ExitFailure
+}
+
+// requestError classifies an error returned by the HTTP client
+func requestError(err error) error {
+	var undefined *hc.UndefinedVariablesError
+	if errors.As(err, &undefined) || errors.Is(err, hc.ErrRedactedValues) {
+		return err
+	}
+
+	code := ExitTransport
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		code = ExitTimeout
+	}
+
+	return &ExitCodeError{Code: code, Err: fmt.Errorf("request failed: %w", err)}
+}
+
+// statusError returns an error for 4xx and 5xx responses, or nil otherwise
+func statusError(statusCode int, status string) error {
+	switch {
+	case statusCode >= 500:
+		return &ExitCodeError{Code: ExitServerErr, Err: fmt.Errorf("server error: %s", status)}
+	case statusCode >= 400:
+		return &ExitCodeError{Code: ExitClientErr, Err: fmt.Errorf("client error: %s", status)}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package cobracommands
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Esa824/apix/internal/converters"
+	hc "github.com/Esa824/apix/internal/http-client"
+	"github.com/Esa824/apix/internal/model"
+)
+
+var ExportCmd = &cobra.Command{
+	Use:   "export [format] [template...]",
+	Short: "Export templates to Postman, Insomnia or OpenAPI",
+	Long: `Export saved templates, or a collection with --collection, as a Postman v2.1 collection,
+an Insomnia v4 export or a skeletal OpenAPI 3 document. Formats: ` + strings.Join(converters.ExportFormats, ", ") + `.
+All templates are exported unless template names are given. Output goes to stdout unless --output is set.`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeExport,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		format := args[0]
+		collectionPath, _ := cmd.Flags().GetString("collection")
+		name, _ := cmd.Flags().GetString("name")
+		output, _ := cmd.Flags().GetString("output")
+
+		var templates []model.Template
+		if collectionPath != "" {
+			collection, err := hc.LoadCollection(collectionPath)
+			if err != nil {
+				return err
+			}
+			templates = hc.CollectionTemplates(*collection)
+			if name == "" {
+				name = collection.Name
+			}
+		} else {
+			all, err := hc.GetTemplates()
+			if err != nil {
+				return err
+			}
+			templates = all
+		}
+		if name == "" {
+			name = "apix"
+		}
+
+		templates, err := selectTemplates(templates, args[1:])
+		if err != nil {
+			return err
+		}
+		if len(templates) == 0 {
+			return fmt.Errorf("no templates to export")
+		}
+
+		data, err := converters.Export(format, name, templates)
+		if err != nil {
+			return err
+		}
+
+		if output == "" || output == "-" {
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+			return err
+		}
+		if err := os.WriteFile(output, data, 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", output, err)
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Exported %d templates to %s\n", len(templates), output)
+		return nil
+	},
+}
+
+// selectTemplates returns the named templates in the order given, or all templates when names is empty
+func selectTemplates(templates []model.Template, names []string) ([]model.Template, error) {
+	if len(names) == 0 {
+		return templates, nil
+	}
+
+	byName := make(map[string]model.Template, len(templates))
+	for _, template := range templates {
+		byName[template.Name] = template
+	}
+
+	selected := make([]model.Template, 0, len(names))
+	for _, name := range names {
+		template, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("template '%s' not found", name)
+		}
+		selected = append(selected, template)
+	}
+	return selected, nil
+}
+
+// completeExport completes the format, then template names
+func completeExport(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return converters.ExportFormats, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeTemplates(cmd, nil, toComplete)
+}
+
+func init() {
+	ExportCmd.Flags().String("collection", "", "Export the templates of a collection, with inherited defaults applied")
+	ExportCmd.Flags().String("name", "", "Name of the exported collection, workspace or API (defaults to the collection name)")
+	ExportCmd.Flags().StringP("output", "o", "", "File to write (defaults to stdout)")
+
+	ExportCmd.RegisterFlagCompletionFunc("collection", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeCollections(cmd, nil, toComplete)
+	})
+}
@@ -11,11 +11,12 @@ var GetCmd = &cobra.Command{
 	Use:   "get [URL]",
 	Short: "Make a GET request to the specified URL",
 	Long:  `Make a GET request to the specified URL with optional headers and parameters.`,
-	Args:  cobra.MinimumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("Making GET request to: %s\n", args[0])
-		// TODO: Implement GET request logic
-	},
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRequest("GET"),
+}
+
+func init() {
+	addRequestFlags(GetCmd)
 }
 
 func HandleGetRequest() {
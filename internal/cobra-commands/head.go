@@ -0,0 +1,17 @@
+package cobracommands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var HeadCmd = &cobra.Command{
+	Use:   "head [URL]",
+	Short: "Make a HEAD request to the specified URL",
+	Long:  `Make a HEAD request to the specified URL with optional headers and parameters.`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRequest("HEAD"),
+}
+
+func init() {
+	addRequestFlags(HeadCmd)
+}
@@ -0,0 +1,177 @@
+package cobracommands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	hc "github.com/Esa824/apix/internal/http-client"
+)
+
+var HistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Search and manage request history",
+	Long: `Requests sent with history enabled are recorded with their response status, duration and size.
+Retention is controlled by the behavior.history_max_entries and behavior.history_max_age settings.`,
+}
+
+var historyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recorded requests, oldest first",
+	Example: `  apix history list --method POST --status 5xx --url-contains /users --since 2h
+  apix history list --status error --limit 20 --output-format json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		format, _ := cmd.Flags().GetString("output-format")
+		if err := validateOutputFormat(format); err != nil {
+			return err
+		}
+
+		filter := hc.HistoryFilter{}
+		filter.Method, _ = cmd.Flags().GetString("method")
+		filter.Status, _ = cmd.Flags().GetString("status")
+		filter.URLContains, _ = cmd.Flags().GetString("url-contains")
+		filter.Limit, _ = cmd.Flags().GetInt("limit")
+		if since, _ := cmd.Flags().GetString("since"); since != "" {
+			start, err := parseSince(since, time.Now())
+			if err != nil {
+				return err
+			}
+			filter.Since = start
+		}
+
+		history, err := hc.SearchHistory(filter)
+		if err != nil {
+			return err
+		}
+
+		if format == outputJSON {
+			documents := make([]historyDocument, 0, len(history))
+			for _, entry := range history {
+				documents = append(documents, newHistoryDocument(entry))
+			}
+			return writeJSON(cmd.OutOrStdout(), documents)
+		}
+
+		if len(history) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No matching requests found")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tTIME\tMETHOD\tSTATUS\tDURATION\tURL")
+		for _, entry := range history {
+			status, duration := "ERROR", "-"
+			if !entry.Failed() {
+				status = strconv.Itoa(entry.StatusCode)
+				duration = entry.Duration.Round(time.Millisecond).String()
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+				entry.Id, entry.Time.Local().Format("2006-01-02 15:04:05"), entry.Method, status, duration, entry.URL)
+		}
+		return w.Flush()
+	},
+}
+
+var historyClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete all recorded requests",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		if err := hc.DeleteHistory(); err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), "Request history cleared")
+		return nil
+	},
+}
+
+var historyPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove requests outside the retention limits",
+	Long: `Requests beyond behavior.history_max_entries or older than behavior.history_max_age days are
+hidden right away but only removed from history.jsonl every 100 requests. prune removes them now.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		removed, err := hc.PruneHistory()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Removed %d request(s) from history\n", removed)
+		return nil
+	},
+}
+
+// historyDocument is the machine-readable form of a history entry
+type historyDocument struct {
+	ID         int               `json:"id"`
+	Time       time.Time         `json:"time"`
+	Method     string            `json:"method"`
+	URL        string            `json:"url"`
+	Status     string            `json:"status,omitempty"`
+	StatusCode int               `json:"status_code,omitempty"`
+	Timing     *timingDocument   `json:"timing,omitempty"`
+	Size       int64             `json:"size,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+func newHistoryDocument(entry hc.HistoryEntry) historyDocument {
+	doc := historyDocument{
+		ID:         entry.Id,
+		Time:       entry.Time,
+		Method:     entry.Method,
+		URL:        entry.URL,
+		Status:     entry.Status,
+		StatusCode: entry.StatusCode,
+		Size:       entry.Size,
+		Headers:    entry.ResponseHeaders,
+		Error:      entry.Error,
+	}
+	if !entry.Failed() {
+		doc.Timing = &timingDocument{TotalMs: float64(entry.Duration.Microseconds()) / 1000}
+	}
+	return doc
+}
+
+// parseSince reads --since as a duration before now ("90m", "2h", "7d") or as a date or timestamp
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if duration, err := time.ParseDuration(value); err == nil && duration >= 0 {
+		return now.Add(-duration), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q (use a duration such as 2h or 7d, a date such as 2024-05-01, or an RFC 3339 timestamp)", value)
+}
+
+func init() {
+	historyListCmd.Flags().String("method", "", "Only requests with this method")
+	historyListCmd.Flags().String("status", "", "Only responses with this status code (404), class (5xx), or failed requests (error)")
+	historyListCmd.Flags().String("url-contains", "", "Only requests whose URL contains this text")
+	historyListCmd.Flags().String("since", "", "Only requests sent within this duration (2h, 7d) or since this date")
+	historyListCmd.Flags().Int("limit", 0, "Show only the newest N matching requests")
+	historyListCmd.Flags().String("output-format", outputText, "Output format: text or json")
+
+	HistoryCmd.AddCommand(historyListCmd)
+	HistoryCmd.AddCommand(historyClearCmd)
+	HistoryCmd.AddCommand(historyPruneCmd)
+}
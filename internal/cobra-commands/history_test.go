@@ -0,0 +1,51 @@
+package cobracommands
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "7d", want: now.AddDate(0, 0, -7)},
+		{value: "0d", want: now},
+		{value: "90m", want: now.Add(-90 * time.Minute)},
+		{value: "2h", want: now.Add(-2 * time.Hour)},
+		{value: "1h30m15s", want: now.Add(-(time.Hour + 30*time.Minute + 15*time.Second))},
+		{value: "2024-05-01T09:00:00Z", want: time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)},
+		{value: "2024-05-01T09:00:00+02:00", want: time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC)},
+		{value: "2024-05-01", want: time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)},
+		{value: "-2h", wantErr: true},
+		{value: "-3d", wantErr: true},
+		{value: "d", wantErr: true},
+		{value: "1.5d", wantErr: true},
+		{value: "yesterday", wantErr: true},
+		{value: "2024-13-01", wantErr: true},
+		{value: "05/01/2024", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseSince(tt.value, now)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "invalid --since") {
+					t.Errorf("parseSince(%q) = %v, %v, want an invalid --since error", tt.value, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSince(%q) error = %v", tt.value, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseSince(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
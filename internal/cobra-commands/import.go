@@ -0,0 +1,209 @@
+package cobracommands
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Esa824/apix/internal/converters"
+	hc "github.com/Esa824/apix/internal/http-client"
+	"github.com/Esa824/apix/internal/model"
+	"github.com/Esa824/apix/internal/utils"
+)
+
+var ImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import requests from other API tools",
+}
+
+var importPostmanCmd = &cobra.Command{
+	Use:   "postman [file...]",
+	Short: "Import Postman v2.1 collections and environments",
+	Long: `Import Postman Collection v2.1 exports as apix collections, keeping their folder structure,
+and Postman environment exports as apix environments. Collection variables are saved as an
+environment named after the collection.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		into, _ := cmd.Flags().GetString("into")
+		force, _ := cmd.Flags().GetBool("force")
+		if into != "" && len(args) > 1 {
+			return fmt.Errorf("--into can only be used with a single file")
+		}
+
+		for _, path := range args {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", path, err)
+			}
+
+			if converters.IsPostmanEnvironment(data) {
+				environment, err := converters.ParsePostmanEnvironment(data)
+				if err != nil {
+					return err
+				}
+				if err := saveImportedEnvironment(*environment, force); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Imported environment '%s' (%d variables)\n", environment.Name, len(environment.Variables))
+				continue
+			}
+
+			collection, variables, err := converters.ParsePostmanCollection(data)
+			if err != nil {
+				return err
+			}
+
+			target := into
+			if target == "" {
+				target = collection.Name
+			}
+			if err := hc.ImportCollection(target, *collection, force); err != nil {
+				return fmt.Errorf("%w (use --force to replace it)", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Imported collection '%s' (%d requests)\n", target, len(hc.CollectionTemplates(*collection)))
+
+			if len(variables) > 0 {
+				environment := model.Environment{Name: collection.Name, Variables: variables}
+				if err := saveImportedEnvironment(environment, force); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Imported collection variables as environment '%s'\n", environment.Name)
+			}
+		}
+		return nil
+	},
+}
+
+var importCurlCmd = &cobra.Command{
+	Use:   "curl [command]",
+	Short: "Import a curl command as a template, or run it",
+	Long: `Parse a curl command line, such as one copied from browser devtools, and save it as a template.
+Pass the command as one quoted argument, as separate arguments after --, or as "-" to read it from stdin.
+Supports -X, -H, -d/--data-raw/--data-binary, --data-urlencode, -F, -u, -b, -A, -e, -G, -I,
+--compressed and -k. With --run the request is sent instead of saved.`,
+	Example: `  apix import curl 'curl -X POST https://api.example.com/users -H "Content-Type: application/json" -d "{\"name\":\"x\"}"' --name create-user
+  apix import curl --run -- curl https://api.example.com/users -H 'Accept: application/json'
+  pbpaste | apix import curl - --name from-devtools`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		var parsed *converters.CurlCommand
+		var err error
+		switch {
+		case len(args) == 1 && args[0] == "-":
+			input, readErr := io.ReadAll(cmd.InOrStdin())
+			if readErr != nil {
+				return fmt.Errorf("failed to read curl command: %w", readErr)
+			}
+			parsed, err = converters.ParseCurl(string(input))
+		case len(args) == 1:
+			parsed, err = converters.ParseCurl(args[0])
+		default:
+			parsed, err = converters.ParseCurlArgs(args)
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, ignored := range parsed.Ignored {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: ignoring curl option %s\n", ignored)
+		}
+
+		if run, _ := cmd.Flags().GetBool("run"); run {
+			return runCurl(cmd, parsed)
+		}
+
+		template := parsed.Template
+		template.Name, _ = cmd.Flags().GetString("name")
+		if template.Name == "" {
+			template.Name = curlTemplateName(template)
+		}
+		if existing, _ := hc.GetTemplateByName(template.Name); existing != nil {
+			if force, _ := cmd.Flags().GetBool("force"); !force {
+				return fmt.Errorf("template '%s' already exists (use --force to replace it, or --name)", template.Name)
+			}
+		}
+		if err := hc.SaveTemplate(template); err != nil {
+			return err
+		}
+
+		if parsed.Insecure {
+			fmt.Fprintln(cmd.ErrOrStderr(), "Note: -k is not stored in templates; disable ValidateSSL in settings to skip certificate checks")
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Saved template '%s' (%s %s)\n", template.Name, template.Method, template.URL)
+		return nil
+	},
+}
+
+// runCurl sends a parsed curl command, honoring -k and --compressed for this run only
+func runCurl(cmd *cobra.Command, parsed *converters.CurlCommand) error {
+	format, _ := cmd.Flags().GetString("output-format")
+	if err := validateOutputFormat(format); err != nil {
+		return err
+	}
+
+	if parsed.Insecure {
+		hc.Settings.Behavior.ValidateSSL = false
+	}
+	if parsed.Compressed {
+		hc.Settings.Network.CompressionEnabled = true
+	}
+
+	options := hc.TemplateRequestOptions(parsed.Template)
+	resolved, err := hc.ResolveVariables(options)
+	if err != nil {
+		return err
+	}
+
+	response, err := hc.NewClient(hc.RequestTimeout()).Do(options, true)
+	if err != nil {
+		writeErrorOutput(cmd.OutOrStdout(), format, resolved, err)
+		return requestError(err)
+	}
+	return writeOutput(cmd.OutOrStdout(), format, resolved, utils.ParseResponse(response))
+}
+
+// curlTemplateName derives a template name such as "post-users" from the method and the last path segment
+func curlTemplateName(template model.Template) string {
+	name := strings.ToLower(template.Method)
+	if parsed, err := url.Parse(template.URL); err == nil {
+		if segment := path.Base(parsed.Path); segment != "/" && segment != "." {
+			name += "-" + segment
+		} else if parsed.Host != "" {
+			name += "-" + parsed.Hostname()
+		}
+	}
+	return converters.SanitizeName(name)
+}
+
+// saveImportedEnvironment saves an imported environment, refusing to replace an existing one unless forced
+func saveImportedEnvironment(environment model.Environment, force bool) error {
+	if existing, err := hc.GetEnvironment(environment.Name); err == nil {
+		if !force {
+			return fmt.Errorf("environment '%s' already exists (use --force to replace it)", environment.Name)
+		}
+		environment.Active = existing.Active
+	}
+	return hc.SaveEnvironment(environment)
+}
+
+func init() {
+	importPostmanCmd.Flags().String("into", "", "Collection path to import into (defaults to the collection name)")
+	importPostmanCmd.Flags().Bool("force", false, "Replace existing collections and environments")
+
+	importCurlCmd.Flags().String("name", "", "Template name (defaults to the method and last path segment)")
+	importCurlCmd.Flags().Bool("force", false, "Replace an existing template with the same name")
+	importCurlCmd.Flags().Bool("run", false, "Send the request instead of saving it as a template")
+	importCurlCmd.Flags().String("output-format", outputText, "Output format with --run: text or json")
+
+	ImportCmd.AddCommand(importPostmanCmd)
+	ImportCmd.AddCommand(importCurlCmd)
+}
@@ -0,0 +1,17 @@
+package cobracommands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var OptionsCmd = &cobra.Command{
+	Use:   "options [URL]",
+	Short: "Make a OPTIONS request to the specified URL",
+	Long:  `Make a OPTIONS request to the specified URL with optional headers and parameters.`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRequest("OPTIONS"),
+}
+
+func init() {
+	addRequestFlags(OptionsCmd)
+}
@@ -0,0 +1,96 @@
+package cobracommands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	hc "github.com/Esa824/apix/internal/http-client"
+	"github.com/Esa824/apix/internal/model"
+	"github.com/Esa824/apix/internal/utils"
+)
+
+// Supported values for --output-format
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// responseDocument is the machine-readable form of a response
+type responseDocument struct {
+	Method     string            `json:"method"`
+	URL        string            `json:"url"`
+	Status     string            `json:"status,omitempty"`
+	StatusCode int               `json:"status_code,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Timing     *timingDocument   `json:"timing,omitempty"`
+	Size       int64             `json:"size,omitempty"`
+	Body       any               `json:"body,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+type timingDocument struct {
+	TotalMs float64 `json:"total_ms"`
+}
+
+// newResponseDocument builds a response document from a parsed response
+func newResponseDocument(options hc.RequestOptions, response *model.HTTPResponse) responseDocument {
+	doc := responseDocument{
+		Method:     options.Method,
+		URL:        options.URL,
+		Status:     response.Status,
+		StatusCode: response.StatusCode,
+		Headers:    response.Headers,
+		Timing:     &timingDocument{TotalMs: float64(response.Duration.Microseconds()) / 1000},
+		Size:       response.Size,
+	}
+
+	if response.IsJSON {
+		doc.Body = json.RawMessage(response.Body)
+	} else {
+		doc.Body = string(response.Body)
+	}
+
+	return doc
+}
+
+// validateOutputFormat checks the value of --output-format
+func validateOutputFormat(format string) error {
+	if format != outputText && format != outputJSON {
+		return fmt.Errorf("invalid output format %q (use %s or %s)", format, outputText, outputJSON)
+	}
+	return nil
+}
+
+// writeOutput prints a response in the requested format
+func writeOutput(w io.Writer, format string, options hc.RequestOptions, response *model.HTTPResponse) error {
+	if format == outputJSON {
+		return writeJSON(w, newResponseDocument(options, response))
+	}
+
+	utils.WriteResponse(w, response)
+	return nil
+}
+
+// writeErrorOutput reports a failed request in the requested format
+func writeErrorOutput(w io.Writer, format string, options hc.RequestOptions, err error) {
+	if format != outputJSON {
+		return
+	}
+
+	writeJSON(w, responseDocument{
+		Method: options.Method,
+		URL:    options.URL,
+		Error:  err.Error(),
+	})
+}
+
+func writeJSON(w io.Writer, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal output: %w", err)
+	}
+
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
@@ -0,0 +1,17 @@
+package cobracommands
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var PatchCmd = &cobra.Command{
+	Use:   "patch [URL]",
+	Short: "Make a PATCH request to the specified URL",
+	Long:  `Make a PATCH request to the specified URL with optional body, headers and parameters.`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRequest("PATCH"),
+}
+
+func init() {
+	addRequestFlags(PatchCmd)
+}
@@ -11,11 +11,12 @@ var PostCmd = &cobra.Command{
 	Use:   "post [URL]",
 	Short: "Make a POST request to the specified URL",
 	Long:  `Make a POST request to the specified URL with optional body, headers and parameters.`,
-	Args:  cobra.MinimumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("Making POST request to: %s\n", args[0])
-		// TODO: Implement POST request logic
-	},
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRequest("POST"),
+}
+
+func init() {
+	addRequestFlags(PostCmd)
 }
 
 func HandlePostRequest() {
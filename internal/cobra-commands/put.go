@@ -11,11 +11,12 @@ var PutCmd = &cobra.Command{
 	Use:   "put [URL]",
 	Short: "Make a PUT request to the specified URL",
 	Long:  `Make a PUT request to the specified URL with optional body, headers and parameters.`,
-	Args:  cobra.MinimumNArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Printf("Making PUT request to: %s\n", args[0])
-		// TODO: Implement PUT request logic
-	},
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRequest("PUT"),
+}
+
+func init() {
+	addRequestFlags(PutCmd)
 }
 
 func HandlePutRequest() {
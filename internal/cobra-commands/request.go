@@ -0,0 +1,32 @@
+package cobracommands
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Esa824/apix/internal/utils"
+)
+
+var RequestCmd = &cobra.Command{
+	Use:   "request [URL]",
+	Short: "Make a request with any HTTP method",
+	Long: `Make a request to the specified URL using the method given with -X.
+Any method is accepted, including HEAD, OPTIONS, TRACE and custom verbs such as PURGE.`,
+	Example: `  apix request -X PURGE https://cdn.example.com/assets/app.js
+  apix request -X OPTIONS https://api.example.com/users -H "Origin: https://example.com"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		method, _ := cmd.Flags().GetString("request")
+		method = strings.ToUpper(strings.TrimSpace(method))
+		if err := utils.ValidateHTTPMethod(method); err != nil {
+			return err
+		}
+		return runRequest(method)(cmd, args)
+	},
+}
+
+func init() {
+	RequestCmd.Flags().StringP("request", "X", "GET", "HTTP method to use")
+	addRequestFlags(RequestCmd)
+}
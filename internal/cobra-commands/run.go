@@ -0,0 +1,127 @@
+package cobracommands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Esa824/apix/internal/converters"
+	hc "github.com/Esa824/apix/internal/http-client"
+	"github.com/Esa824/apix/internal/model"
+	"github.com/Esa824/apix/internal/utils"
+)
+
+var RunCmd = &cobra.Command{
+	Use:   "run [collection | file.http]",
+	Short: "Run the templates of a collection or the requests of a .http file in order",
+	Long: `Run every template in a collection, including nested folders, in the collection's order.
+Templates inherit the collection's base URL, headers and auth. Assertions and captures apply as in
+'apix test'. Exits with code 1 if any request fails.
+
+A .http or .rest file (JetBrains HTTP Client / VS Code REST Client format) runs its requests in
+order: requests are separated by ###, '@name = value' lines define variables, '# @name' names a
+request and '< ./file.json' includes a body from a file. Use --request to send a single request
+and print its response, or --save-collection to store the requests as a collection instead.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeCollections,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		if isRequestFile(args[0]) {
+			return runRequestFile(cmd, args[0])
+		}
+
+		collection, err := hc.LoadCollection(args[0])
+		if err != nil {
+			return err
+		}
+
+		templates := hc.CollectionTemplates(*collection)
+		if len(templates) == 0 {
+			return fmt.Errorf("collection '%s' has no templates", args[0])
+		}
+		return runTemplates(cmd, templates)
+	},
+}
+
+// isRequestFile reports whether path names a .http or .rest file
+func isRequestFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".http" || ext == ".rest"
+}
+
+// runRequestFile runs, or saves as a collection, the requests of a .http/.rest file
+func runRequestFile(cmd *cobra.Command, path string) error {
+	file, err := converters.ParseHTTPFile(path)
+	if err != nil {
+		return err
+	}
+
+	if collectionPath, _ := cmd.Flags().GetString("save-collection"); collectionPath != "" {
+		collection := model.Collection{Templates: file.Templates}
+		for _, template := range file.Templates {
+			collection.Order = append(collection.Order, template.Name)
+		}
+
+		force, _ := cmd.Flags().GetBool("force")
+		if err := hc.ImportCollection(collectionPath, collection, force); err != nil {
+			return fmt.Errorf("%w (use --force to replace it)", err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Saved %d requests to collection '%s'\n", len(file.Templates), collectionPath)
+		return nil
+	}
+
+	name, _ := cmd.Flags().GetString("request")
+	if name == "" {
+		return runTemplates(cmd, file.Templates)
+	}
+
+	for _, template := range file.Templates {
+		if template.Name == name {
+			return sendTemplate(cmd, template)
+		}
+	}
+	return fmt.Errorf("request '%s' not found in %s", name, path)
+}
+
+// sendTemplate sends a single template and prints the response like the request commands do
+func sendTemplate(cmd *cobra.Command, template model.Template) error {
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	if timeout <= 0 {
+		timeout = hc.RequestTimeout()
+	}
+
+	options := hc.TemplateRequestOptions(template)
+	resolved, err := hc.ResolveVariables(options)
+	if err != nil {
+		return err
+	}
+
+	response, err := hc.NewClient(timeout).Do(options, true)
+	if err != nil {
+		return requestError(err)
+	}
+	return writeOutput(cmd.OutOrStdout(), outputText, resolved, utils.ParseResponse(response))
+}
+
+// completeCollections completes the first argument with collection paths
+func completeCollections(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	paths, err := hc.GetCollectionPaths()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return paths, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	addRunnerFlags(RunCmd)
+	RunCmd.Flags().String("request", "", "Send only the named request of a .http file and print its response")
+	RunCmd.Flags().String("save-collection", "", "Save the requests of a .http file as a collection instead of running them")
+	RunCmd.Flags().Bool("force", false, "Replace an existing collection when using --save-collection")
+}
@@ -0,0 +1,82 @@
+package cobracommands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	hc "github.com/Esa824/apix/internal/http-client"
+)
+
+var SettingsCmd = &cobra.Command{
+	Use:   "settings",
+	Short: "Export, import and validate application settings",
+}
+
+var settingsExportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "Export settings to a JSON or YAML file",
+	Long: `Export the current settings to a file, or to stdout when the file is "-".
+The format is taken from --format, or from the file extension (.yaml/.yml for YAML, JSON otherwise).`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		format, _ := cmd.Flags().GetString("format")
+		path := args[0]
+
+		if path == "-" {
+			if format == "" {
+				format = hc.SettingsFormatJSON
+			}
+			data, err := hc.MarshalSettings(hc.Settings, format)
+			if err != nil {
+				return err
+			}
+			_, err = cmd.OutOrStdout().Write(data)
+			return err
+		}
+
+		if err := hc.ExportSettings(hc.Settings, path, format); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Settings exported to %s\n", path)
+		return nil
+	},
+}
+
+var settingsImportCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Import settings from a JSON or YAML file",
+	Long: `Import settings from a file written by 'apix settings export'.
+Files from older versions are migrated, and every field is validated before anything is saved.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		imported, err := hc.ImportSettings(args[0])
+		if err != nil {
+			return err
+		}
+
+		if check, _ := cmd.Flags().GetBool("check"); check {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s is valid\n", args[0])
+			return nil
+		}
+
+		*hc.Settings = *imported
+		if err := hc.SaveSettings(hc.Settings); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Settings imported from %s\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	settingsExportCmd.Flags().String("format", "", "Export format: json or yaml (defaults to the file extension)")
+	settingsImportCmd.Flags().Bool("check", false, "Validate the file without applying it")
+
+	SettingsCmd.AddCommand(settingsExportCmd)
+	SettingsCmd.AddCommand(settingsImportCmd)
+}
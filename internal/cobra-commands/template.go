@@ -0,0 +1,175 @@
+package cobracommands
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Esa824/apix/internal/converters"
+	hc "github.com/Esa824/apix/internal/http-client"
+)
+
+var TemplateCmd = &cobra.Command{
+	Use:     "template",
+	Aliases: []string{"templates"},
+	Short:   "Run and manage saved templates",
+}
+
+var templateRunCmd = &cobra.Command{
+	Use:   "run [name]",
+	Short: "Run a saved template without prompts",
+	Long: `Send a saved template and print the response. --set provides {{variables}} for this run
+only, taking precedence over the active environment. The template's captures are stored and its
+assertions are checked; the command exits with code 1 if any assertion fails.`,
+	Example: `  apix template run get-user --set id=42
+  apix template run login --env staging --output-format json`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeTemplates,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		format, _ := cmd.Flags().GetString("output-format")
+		if err := validateOutputFormat(format); err != nil {
+			return err
+		}
+
+		template, err := hc.GetTemplateByName(args[0])
+		if err != nil {
+			return err
+		}
+
+		pairs, _ := cmd.Flags().GetStringArray("set")
+		values := make(map[string]string, len(pairs))
+		for _, pair := range pairs {
+			name, value, ok := strings.Cut(pair, "=")
+			if !ok || strings.TrimSpace(name) == "" {
+				return fmt.Errorf("invalid --set %q, expected 'name=value'", pair)
+			}
+			values[strings.TrimSpace(name)] = value
+		}
+		hc.OverrideVariables(values)
+
+		options := hc.TemplateRequestOptions(*template)
+		auth, err := profileAuthFromFlags(cmd)
+		if err != nil {
+			return err
+		}
+		if auth != nil {
+			options.Auth = auth
+		}
+
+		return sendRequest(cmd, options, template.Captures, template.Assertions)
+	},
+}
+
+var templateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved templates",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		templates, err := hc.GetTemplates()
+		if err != nil {
+			return err
+		}
+
+		if len(templates) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No templates found")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		for _, template := range templates {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", template.Name, template.Method, template.URL)
+		}
+		return w.Flush()
+	},
+}
+
+var templateShowCmd = &cobra.Command{
+	Use:               "show [name]",
+	Short:             "Show a saved template as JSON, or as a curl command with --curl",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeTemplates,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		template, err := hc.GetTemplateByName(args[0])
+		if err != nil {
+			return err
+		}
+
+		if asCurl, _ := cmd.Flags().GetBool("curl"); asCurl {
+			_, err := fmt.Fprintln(cmd.OutOrStdout(), converters.ToCurl(*template))
+			return err
+		}
+		return writeJSON(cmd.OutOrStdout(), template)
+	},
+}
+
+var templateDeleteCmd = &cobra.Command{
+	Use:               "delete [name...]",
+	Short:             "Delete saved templates",
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeTemplates,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		for _, name := range args {
+			if err := hc.DeleteTemplate(name); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Deleted template '%s'\n", name)
+		}
+		return nil
+	},
+}
+
+var templateRenameCmd = &cobra.Command{
+	Use:   "rename [name] [new-name]",
+	Short: "Rename a saved template",
+	Args:  cobra.ExactArgs(2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeTemplates(cmd, args, toComplete)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		oldName, newName := args[0], strings.TrimSpace(args[1])
+		template, err := hc.GetTemplateByName(oldName)
+		if err != nil {
+			return err
+		}
+		if newName == "" || newName == oldName {
+			return fmt.Errorf("new name must be different from '%s'", oldName)
+		}
+		if existing, _ := hc.GetTemplateByName(newName); existing != nil {
+			return fmt.Errorf("template '%s' already exists", newName)
+		}
+
+		template.Name = newName
+		if err := hc.UpdateTemplate(*template, oldName); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Renamed template '%s' to '%s'\n", oldName, newName)
+		return nil
+	},
+}
+
+func init() {
+	templateRunCmd.Flags().StringArray("set", nil, "Set a variable for this run in 'name=value' format (repeatable)")
+	templateRunCmd.Flags().Duration("timeout", 0, "Request timeout (defaults to the RequestTimeout setting)")
+	templateRunCmd.Flags().Bool("fail", false, "Exit with code 4 on 4xx and 5 on 5xx responses")
+	templateRunCmd.Flags().String("output-format", outputText, "Output format: text or json")
+	addProfileFlags(templateRunCmd)
+	templateRunCmd.MarkFlagsMutuallyExclusive("profile", "no-auth")
+	templateShowCmd.Flags().Bool("curl", false, "Print the template as a curl command")
+
+	TemplateCmd.AddCommand(templateRunCmd)
+	TemplateCmd.AddCommand(templateListCmd)
+	TemplateCmd.AddCommand(templateShowCmd)
+	TemplateCmd.AddCommand(templateDeleteCmd)
+	TemplateCmd.AddCommand(templateRenameCmd)
+}
@@ -0,0 +1,147 @@
+package cobracommands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	hc "github.com/Esa824/apix/internal/http-client"
+	"github.com/Esa824/apix/internal/model"
+	"github.com/Esa824/apix/internal/runner"
+)
+
+var TestCmd = &cobra.Command{
+	Use:   "test [template...]",
+	Short: "Run templates as tests and evaluate their assertions",
+	Long: `Run the named templates, or every template with assertions when none are given,
+evaluate their assertions and print a pass/fail summary. Exits with code 1 if any test fails.`,
+	ValidArgsFunction: completeTemplates,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+
+		templates, err := testTemplates(args)
+		if err != nil {
+			return err
+		}
+		return runTemplates(cmd, templates)
+	},
+}
+
+// addRunnerFlags registers the flags shared by commands that execute templates in sequence
+func addRunnerFlags(cmd *cobra.Command) {
+	cmd.Flags().Duration("timeout", 0, "Request timeout (defaults to the RequestTimeout setting)")
+	cmd.Flags().Bool("fail-fast", false, "Stop after the first failing request")
+	cmd.Flags().String("reporter", runner.ReporterText, "Report format: text, junit, tap or json")
+	cmd.Flags().String("report-file", "", "Write the report to a file instead of stdout (a text summary is still printed)")
+}
+
+// runTemplates executes templates in order, writes the report and fails if any of them failed
+func runTemplates(cmd *cobra.Command, templates []model.Template) error {
+	reporter, _ := cmd.Flags().GetString("reporter")
+	if !slices.Contains(runner.Reporters, reporter) {
+		return fmt.Errorf("invalid reporter %q (use %s)", reporter, strings.Join(runner.Reporters, ", "))
+	}
+	reportFile, _ := cmd.Flags().GetString("report-file")
+
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	if timeout <= 0 {
+		timeout = hc.RequestTimeout()
+	}
+	failFast, _ := cmd.Flags().GetBool("fail-fast")
+
+	summary := runner.Run(hc.NewClient(timeout), templates, failFast)
+
+	if reportFile == "" {
+		if err := runner.WriteReport(cmd.OutOrStdout(), reporter, summary); err != nil {
+			return err
+		}
+	} else {
+		runner.WriteSummary(cmd.OutOrStdout(), summary)
+		if err := writeReportFile(reportFile, reporter, summary); err != nil {
+			return err
+		}
+	}
+
+	if summary.Failed > 0 {
+		return &ExitCodeError{
+			Code: ExitFailure,
+			Err:  fmt.Errorf("%d of %d requests failed", summary.Failed, len(summary.Results)),
+		}
+	}
+	return nil
+}
+
+// writeReportFile writes the report to path, creating parent directories as needed
+func writeReportFile(path, reporter string, summary runner.Summary) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create report directory: %w", err)
+		}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	defer file.Close()
+
+	if err := runner.WriteReport(file, reporter, summary); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// testTemplates returns the named templates, or all templates with assertions sorted by name
+func testTemplates(names []string) ([]model.Template, error) {
+	if len(names) > 0 {
+		templates := make([]model.Template, 0, len(names))
+		for _, name := range names {
+			template, err := hc.GetTemplateByName(name)
+			if err != nil {
+				return nil, err
+			}
+			templates = append(templates, *template)
+		}
+		return templates, nil
+	}
+
+	all, err := hc.GetTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	var templates []model.Template
+	for _, template := range all {
+		if len(template.Assertions) > 0 {
+			templates = append(templates, template)
+		}
+	}
+	if len(templates) == 0 {
+		return nil, fmt.Errorf("no templates with assertions found")
+	}
+	return templates, nil
+}
+
+// completeTemplates completes arguments with template names
+func completeTemplates(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	templates, err := hc.GetTemplates()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	names := make([]string, 0, len(templates))
+	for _, template := range templates {
+		names = append(names, template.Name)
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	addRunnerFlags(TestCmd)
+}
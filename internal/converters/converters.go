@@ -0,0 +1,61 @@
+// Package converters translates between apix templates and other API tool formats
+package converters
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+// SanitizeName makes a name safe to use as a template file or collection folder name
+func SanitizeName(name string) string {
+	name = strings.TrimSpace(name)
+	name = strings.NewReplacer("/", "-", "\\", "-", ":", "-").Replace(name)
+	name = strings.Trim(name, ".")
+	if name == "" || name == "collection" {
+		return "untitled"
+	}
+	return name
+}
+
+// nameSet hands out unique names within one folder
+type nameSet map[string]int
+
+func newNameSet() nameSet {
+	return make(nameSet)
+}
+
+// unique returns name, suffixed with a counter if it was already used
+func (s nameSet) unique(name string) string {
+	s[name]++
+	if s[name] == 1 {
+		return name
+	}
+	for {
+		candidate := fmt.Sprintf("%s-%d", name, s[name])
+		if _, exists := s[candidate]; !exists {
+			s[candidate] = 1
+			return candidate
+		}
+		s[name]++
+	}
+}
+
+// apiKeyLocation returns where API key auth sends the key: header, query or cookie
+func apiKeyLocation(auth *model.Auth) string {
+	if auth.Location == "" {
+		return "header"
+	}
+	return auth.Location
+}
+
+// hasKey reports whether headers contains key, ignoring case
+func hasKey(headers map[string]string, key string) bool {
+	for existing := range headers {
+		if strings.EqualFold(existing, key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,575 @@
+package converters
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+// CurlCommand is a parsed curl command line
+type CurlCommand struct {
+	Template   model.Template
+	Insecure   bool     // -k/--insecure
+	Compressed bool     // --compressed
+	Ignored    []string // options apix has no equivalent for
+}
+
+// curlValueOptions are the options that take a value, by every name they can be given
+var curlValueOptions = map[string]string{
+	"-X": "request", "--request": "request",
+	"-H": "header", "--header": "header",
+	"-d": "data", "--data": "data", "--data-ascii": "data",
+	"--data-raw": "data-raw", "--data-binary": "data-binary",
+	"--data-urlencode": "data-urlencode",
+	"-F":               "form", "--form": "form", "--form-string": "form-string",
+	"-u": "user", "--user": "user",
+	"-b": "cookie", "--cookie": "cookie",
+	"-A": "user-agent", "--user-agent": "user-agent",
+	"-e": "referer", "--referer": "referer",
+	"--url":           "url",
+	"--oauth2-bearer": "oauth2-bearer",
+	"--aws-sigv4":     "aws-sigv4",
+	// Accepted but without an apix equivalent
+	"-o": "", "--output": "", "-w": "", "--write-out": "", "-m": "", "--max-time": "",
+	"--connect-timeout": "", "-x": "", "--proxy": "", "--cacert": "", "-E": "", "--cert": "",
+	"--key": "", "--retry": "", "-c": "", "--cookie-jar": "", "-T": "", "--upload-file": "",
+	"-r": "", "--range": "", "--resolve": "", "--max-redirs": "",
+}
+
+// curlFlagOptions are the options that take no value
+var curlFlagOptions = map[string]string{
+	"-k": "insecure", "--insecure": "insecure",
+	"--compressed": "compressed",
+	"-G":           "get", "--get": "get",
+	"-I": "head", "--head": "head",
+	"--digest": "digest",
+	// Output and transfer flags that do not change the request
+	"-s": "", "--silent": "", "-S": "", "--show-error": "", "-L": "", "--location": "",
+	"-i": "", "--include": "", "-v": "", "--verbose": "", "-f": "", "--fail": "",
+	"-N": "", "--no-buffer": "", "--http1.1": "", "--http2": "", "-O": "", "--remote-name": "",
+	"-g": "", "--globoff": "", "-#": "", "--progress-bar": "",
+}
+
+// ParseCurl parses a curl command line, such as one copied from browser devtools
+func ParseCurl(command string) (*CurlCommand, error) {
+	args, err := SplitShellWords(command)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCurlArgs(args)
+}
+
+// ParseCurlArgs parses the arguments of a curl command that a shell has already split
+func ParseCurlArgs(args []string) (*CurlCommand, error) {
+	if len(args) > 0 && (args[0] == "curl" || strings.HasSuffix(args[0], "/curl")) {
+		args = args[1:]
+	}
+	// Short option clusters are expanded in place
+	args = append([]string(nil), args...)
+
+	result := &CurlCommand{}
+	template := &result.Template
+	template.Headers = make(map[string]string)
+	template.QueryParams = make(map[string]string)
+
+	var method, rawURL string
+	var data []string
+	var get, head, digest bool
+	var awsScope string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == "" || arg[0] != '-' || arg == "-" {
+			if rawURL != "" {
+				return nil, fmt.Errorf("unexpected argument %q: only one URL is supported", arg)
+			}
+			rawURL = arg
+			continue
+		}
+
+		name, value, hasValue := splitCurlOption(arg)
+		option, takesValue := curlValueOptions[name]
+		if !takesValue {
+			flag, known := curlFlagOptions[name]
+			if !known {
+				if strings.HasPrefix(arg, "--") {
+					result.Ignored = append(result.Ignored, arg)
+					continue
+				}
+				// A cluster of short options such as -sSLk. The first option that takes a
+				// value ends it and keeps the rest as that value, as in -sXPOST.
+				expanded := make([]string, 0, len(arg)-1)
+				for j, short := range arg[1:] {
+					option := "-" + string(short)
+					if _, ok := curlValueOptions[option]; ok {
+						expanded = append(expanded, option+arg[j+2:])
+						break
+					}
+					expanded = append(expanded, option)
+				}
+				if len(expanded) == 1 {
+					result.Ignored = append(result.Ignored, arg)
+					continue
+				}
+				args = append(args[:i+1], append(expanded, args[i+1:]...)...)
+				continue
+			}
+
+			switch flag {
+			case "insecure":
+				result.Insecure = true
+			case "compressed":
+				result.Compressed = true
+			case "get":
+				get = true
+			case "head":
+				head = true
+			case "digest":
+				digest = true
+			}
+			continue
+		}
+
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("curl option %s requires a value", name)
+			}
+			i++
+			value = args[i]
+		}
+
+		switch option {
+		case "request":
+			method = strings.ToUpper(value)
+		case "header":
+			key, headerValue, ok := strings.Cut(value, ":")
+			if !ok {
+				return nil, fmt.Errorf("invalid header %q, expected 'Key: Value'", value)
+			}
+			template.Headers[strings.TrimSpace(key)] = strings.TrimSpace(headerValue)
+		case "data", "data-binary":
+			body, err := curlData(value, option == "data")
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, body)
+		case "data-raw":
+			data = append(data, value)
+		case "data-urlencode":
+			body, err := curlURLEncode(value)
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, body)
+		case "form", "form-string":
+			key, fieldValue, ok := strings.Cut(value, "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid form field %q, expected 'name=value'", value)
+			}
+			if option == "form" && (strings.HasPrefix(fieldValue, "@") || strings.HasPrefix(fieldValue, "<")) {
+				if template.Files == nil {
+					template.Files = make(map[string]string)
+				}
+				path, _, _ := strings.Cut(fieldValue[1:], ";")
+				template.Files[key] = path
+				continue
+			}
+			if template.FormData == nil {
+				template.FormData = make(map[string]string)
+			}
+			if option == "form" {
+				fieldValue, _, _ = strings.Cut(fieldValue, ";type=")
+			}
+			template.FormData[key] = fieldValue
+		case "user":
+			user, password, _ := strings.Cut(value, ":")
+			template.Auth = &model.Auth{Type: "basic", Primary: user, Secondary: password}
+		case "cookie":
+			if !strings.Contains(value, "=") {
+				result.Ignored = append(result.Ignored, name+" "+value)
+				continue
+			}
+			template.Headers["Cookie"] = value
+		case "user-agent":
+			template.Headers["User-Agent"] = value
+		case "referer":
+			template.Headers["Referer"] = value
+		case "url":
+			rawURL = value
+		case "oauth2-bearer":
+			template.Auth = &model.Auth{Type: "bearer", Primary: value}
+		case "aws-sigv4":
+			awsScope = value
+		default:
+			result.Ignored = append(result.Ignored, name)
+		}
+	}
+
+	if rawURL == "" {
+		return nil, fmt.Errorf("no URL found in curl command")
+	}
+	if !strings.Contains(rawURL, "://") && !strings.HasPrefix(rawURL, "{{") {
+		rawURL = "http://" + rawURL
+	}
+
+	base, query, _ := strings.Cut(rawURL, "?")
+	template.URL = base
+	addQuery(template.QueryParams, query)
+
+	body := strings.Join(data, "&")
+	if get {
+		addQuery(template.QueryParams, body)
+		body = ""
+	}
+	if body != "" {
+		template.Body = body
+		if !hasKey(template.Headers, "Content-Type") {
+			if json.Valid([]byte(body)) {
+				template.Headers["Content-Type"] = "application/json"
+			} else {
+				template.Headers["Content-Type"] = "application/x-www-form-urlencoded"
+			}
+		}
+	}
+
+	// --digest and --aws-sigv4 change how the -u credentials are sent
+	if auth := template.Auth; auth != nil && auth.Type == "basic" {
+		switch {
+		case awsScope != "":
+			// The scope is provider1[:provider2[:region[:service]]]
+			scope := strings.Split(awsScope, ":")
+			signing := &model.AWSSigning{}
+			if len(scope) > 2 {
+				signing.Region = scope[2]
+			}
+			if len(scope) > 3 {
+				signing.Service = scope[3]
+			}
+			auth.Type, auth.AWS = "aws", signing
+		case digest:
+			auth.Type = "digest"
+		}
+	}
+
+	// A bearer Authorization header becomes the template's auth
+	for key, value := range template.Headers {
+		if strings.EqualFold(key, "Authorization") && strings.HasPrefix(strings.ToLower(value), "bearer ") {
+			template.Auth = &model.Auth{Type: "bearer", Primary: strings.TrimSpace(value[len("bearer "):])}
+			delete(template.Headers, key)
+		}
+	}
+
+	switch {
+	case method != "":
+		template.Method = method
+	case head:
+		template.Method = "HEAD"
+	case body != "" || len(template.FormData) > 0 || len(template.Files) > 0:
+		template.Method = "POST"
+	default:
+		template.Method = "GET"
+	}
+
+	if len(template.Headers) == 0 {
+		template.Headers = nil
+	}
+	if len(template.QueryParams) == 0 {
+		template.QueryParams = nil
+	}
+	return result, nil
+}
+
+// splitCurlOption splits "--name=value" and "-Xvalue" forms into the option name and its value
+func splitCurlOption(arg string) (string, string, bool) {
+	if strings.HasPrefix(arg, "--") {
+		if name, value, ok := strings.Cut(arg, "="); ok {
+			return name, value, true
+		}
+		return arg, "", false
+	}
+	if len(arg) > 2 {
+		if _, ok := curlValueOptions[arg[:2]]; ok {
+			return arg[:2], arg[2:], true
+		}
+	}
+	return arg, "", false
+}
+
+// curlData resolves a -d value, reading @file references. Like curl, -d strips newlines from files.
+func curlData(value string, stripNewlines bool) (string, error) {
+	if !strings.HasPrefix(value, "@") {
+		return value, nil
+	}
+
+	content, err := os.ReadFile(value[1:])
+	if err != nil {
+		return "", fmt.Errorf("failed to read data file: %w", err)
+	}
+	if stripNewlines {
+		return strings.NewReplacer("\r", "", "\n", "").Replace(string(content)), nil
+	}
+	return string(content), nil
+}
+
+// curlURLEncode resolves a --data-urlencode value: "content", "=content", "name=content",
+// "@file" or "name@file"
+func curlURLEncode(value string) (string, error) {
+	if i := strings.IndexAny(value, "=@"); i >= 0 {
+		name, content := value[:i], value[i+1:]
+		if value[i] == '@' {
+			data, err := os.ReadFile(content)
+			if err != nil {
+				return "", fmt.Errorf("failed to read data file: %w", err)
+			}
+			content = string(data)
+		}
+		if name == "" {
+			return url.QueryEscape(content), nil
+		}
+		return name + "=" + url.QueryEscape(content), nil
+	}
+	return url.QueryEscape(value), nil
+}
+
+// addQuery adds the pairs of a raw query string to params
+func addQuery(params map[string]string, query string) {
+	for _, pair := range strings.Split(query, "&") {
+		if pair == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			key = unescaped
+		}
+		if unescaped, err := url.QueryUnescape(value); err == nil {
+			value = unescaped
+		}
+		params[key] = value
+	}
+}
+
+// SplitShellWords splits a command line the way a POSIX shell would, handling single and
+// double quotes, $'...' strings, backslash escapes and line continuations
+func SplitShellWords(command string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\':
+			if i+1 < len(runes) {
+				i++
+				if runes[i] != '\n' && runes[i] != '\r' {
+					word.WriteRune(runes[i])
+					inWord = true
+				} else if runes[i] == '\r' && i+1 < len(runes) && runes[i+1] == '\n' {
+					i++
+				}
+			}
+		case r == '\'':
+			end := indexRune(runes, '\'', i+1)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			word.WriteString(string(runes[i+1 : end]))
+			inWord = true
+			i = end
+		case r == '$' && i+1 < len(runes) && runes[i+1] == '\'':
+			end, value, err := ansiCString(runes, i+2)
+			if err != nil {
+				return nil, err
+			}
+			word.WriteString(value)
+			inWord = true
+			i = end
+		case r == '"':
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`\n", runes[i+1]) {
+					i++
+					if runes[i] == '\n' {
+						continue
+					}
+				}
+				word.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated double quote")
+			}
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+func indexRune(runes []rune, target rune, from int) int {
+	for i := from; i < len(runes); i++ {
+		if runes[i] == target {
+			return i
+		}
+	}
+	return -1
+}
+
+// ansiCString decodes a $'...' string starting after the opening quote, returning the closing quote's index
+func ansiCString(runes []rune, start int) (int, string, error) {
+	var value strings.Builder
+	for i := start; i < len(runes); i++ {
+		switch runes[i] {
+		case '\'':
+			return i, value.String(), nil
+		case '\\':
+			if i+1 >= len(runes) {
+				continue
+			}
+			i++
+			switch runes[i] {
+			case 'n':
+				value.WriteByte('\n')
+			case 't':
+				value.WriteByte('\t')
+			case 'r':
+				value.WriteByte('\r')
+			case 'x', 'u', 'U':
+				digits := map[rune]int{'x': 2, 'u': 4, 'U': 8}[runes[i]]
+				end := i + 1
+				for end < len(runes) && end-i-1 < digits && strings.ContainsRune("0123456789abcdefABCDEF", runes[end]) {
+					end++
+				}
+				code, err := strconv.ParseUint(string(runes[i+1:end]), 16, 32)
+				if err != nil {
+					value.WriteRune(runes[i])
+					continue
+				}
+				if runes[i] == 'x' {
+					value.WriteByte(byte(code))
+				} else {
+					value.WriteRune(rune(code))
+				}
+				i = end - 1
+			default:
+				value.WriteRune(runes[i])
+			}
+		default:
+			value.WriteRune(runes[i])
+		}
+	}
+	return 0, "", fmt.Errorf("unterminated $'...' string")
+}
+
+// shellSafe matches words that need no quoting in a POSIX shell
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// ShellQuote quotes a word for a POSIX shell
+func ShellQuote(word string) string {
+	if shellSafe.MatchString(word) {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+// ToCurl renders a template as an equivalent curl command
+func ToCurl(template model.Template) string {
+	parts := []string{"curl"}
+	if template.Method != "" && template.Method != "GET" {
+		parts = append(parts, "-X "+ShellQuote(template.Method))
+	}
+
+	queryParams := template.QueryParams
+	if auth := template.Auth; auth != nil && auth.Type == "apikey" && auth.Location == "query" {
+		queryParams = make(map[string]string, len(template.QueryParams)+1)
+		for key, value := range template.QueryParams {
+			queryParams[key] = value
+		}
+		queryParams[auth.Primary] = auth.Secondary
+	}
+
+	requestURL := template.URL
+	if len(queryParams) > 0 {
+		query := make([]string, 0, len(queryParams))
+		for _, key := range sortedKeys(queryParams) {
+			query = append(query, queryEscape(key)+"="+queryEscape(queryParams[key]))
+		}
+		separator := "?"
+		if strings.Contains(requestURL, "?") {
+			separator = "&"
+		}
+		requestURL += separator + strings.Join(query, "&")
+	}
+	parts = append(parts, ShellQuote(requestURL))
+
+	for _, key := range sortedKeys(template.Headers) {
+		parts = append(parts, "-H "+ShellQuote(key+": "+template.Headers[key]))
+	}
+
+	if auth := template.Auth; auth != nil {
+		switch auth.Type {
+		case "bearer":
+			parts = append(parts, "-H "+ShellQuote("Authorization: Bearer "+auth.Primary))
+		case "basic":
+			parts = append(parts, "-u "+ShellQuote(auth.Primary+":"+auth.Secondary))
+		case "digest":
+			parts = append(parts, "--digest", "-u "+ShellQuote(auth.Primary+":"+auth.Secondary))
+		case "aws":
+			if auth.AWS != nil {
+				parts = append(parts, "--aws-sigv4 "+ShellQuote("aws:amz:"+auth.AWS.Region+":"+auth.AWS.Service))
+				if auth.AWS.SessionToken != "" {
+					parts = append(parts, "-H "+ShellQuote("X-Amz-Security-Token: "+auth.AWS.SessionToken))
+				}
+			}
+			parts = append(parts, "-u "+ShellQuote(auth.Primary+":"+auth.Secondary))
+		case "apikey":
+			switch auth.Location {
+			case "query":
+				// Added to the URL above
+			case "cookie":
+				parts = append(parts, "-b "+ShellQuote(auth.Primary+"="+auth.Secondary))
+			default:
+				parts = append(parts, "-H "+ShellQuote(auth.Primary+": "+auth.Secondary))
+			}
+		}
+	}
+
+	for _, key := range sortedKeys(template.FormData) {
+		parts = append(parts, "-F "+ShellQuote(key+"="+template.FormData[key]))
+	}
+	for _, key := range sortedKeys(template.Files) {
+		parts = append(parts, "-F "+ShellQuote(key+"=@"+template.Files[key]))
+	}
+
+	if body := templateBody(template); body.mode == "raw" || body.mode == "urlencoded" {
+		if !hasKey(template.Headers, "Content-Type") && body.contentType != "" {
+			parts = append(parts, "-H "+ShellQuote("Content-Type: "+body.contentType))
+		}
+		parts = append(parts, "--data-raw "+ShellQuote(body.text))
+	}
+
+	return strings.Join(parts, " \\\n  ")
+}
+
+// queryEscape escapes a query component, keeping {{variable}} placeholders readable
+func queryEscape(value string) string {
+	return strings.NewReplacer("%7B%7B", "{{", "%7D%7D", "}}").Replace(url.QueryEscape(value))
+}
@@ -0,0 +1,353 @@
+package converters
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+func TestParseCurl(t *testing.T) {
+	dir := t.TempDir()
+	dataFile := filepath.Join(dir, "body.json")
+	if err := os.WriteFile(dataFile, []byte("{\n  \"name\": \"Rex\"\n}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		command string
+		want    CurlCommand
+	}{
+		{
+			name:    "plain GET",
+			command: "curl https://api.example.com/pets",
+			want:    CurlCommand{Template: model.Template{Method: "GET", URL: "https://api.example.com/pets"}},
+		},
+		{
+			name:    "URL without a scheme",
+			command: "curl api.example.com/pets?limit=20&tag=a%20b",
+			want: CurlCommand{Template: model.Template{
+				Method:      "GET",
+				URL:         "http://api.example.com/pets",
+				QueryParams: map[string]string{"limit": "20", "tag": "a b"},
+			}},
+		},
+		{
+			name:    "flag cluster",
+			command: "curl -sSLk https://x.com",
+			want:    CurlCommand{Template: model.Template{Method: "GET", URL: "https://x.com"}, Insecure: true},
+		},
+		{
+			name:    "cluster ending in a value option with an attached value",
+			command: "curl -sXPOST https://x.com",
+			want:    CurlCommand{Template: model.Template{Method: "POST", URL: "https://x.com"}},
+		},
+		{
+			name:    "cluster ending in a value option with a separate value",
+			command: "curl -sX put https://x.com",
+			want:    CurlCommand{Template: model.Template{Method: "PUT", URL: "https://x.com"}},
+		},
+		{
+			name:    "cluster value that looks like options",
+			command: "curl -kdsk=1 https://x.com",
+			want: CurlCommand{
+				Template: model.Template{
+					Method:  "POST",
+					URL:     "https://x.com",
+					Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+					Body:    "sk=1",
+				},
+				Insecure: true,
+			},
+		},
+		{
+			name:    "attached and --name=value forms",
+			command: "curl -XDELETE --url=https://x.com/pets/1",
+			want:    CurlCommand{Template: model.Template{Method: "DELETE", URL: "https://x.com/pets/1"}},
+		},
+		{
+			name:    "header values containing colons",
+			command: `curl -H 'X-Window: 09:00-17:30' -H "Referer:https://a.example.com:8443/x" https://x.com`,
+			want: CurlCommand{Template: model.Template{
+				Method:  "GET",
+				URL:     "https://x.com",
+				Headers: map[string]string{"X-Window": "09:00-17:30", "Referer": "https://a.example.com:8443/x"},
+			}},
+		},
+		{
+			name:    "bearer Authorization header becomes auth",
+			command: `curl -H 'authorization: Bearer abc.def' https://x.com`,
+			want: CurlCommand{Template: model.Template{
+				Method: "GET",
+				URL:    "https://x.com",
+				Auth:   &model.Auth{Type: "bearer", Primary: "abc.def"},
+			}},
+		},
+		{
+			name:    "user with a password containing colons",
+			command: "curl -u ada:s3:cr:et https://x.com",
+			want: CurlCommand{Template: model.Template{
+				Method: "GET",
+				URL:    "https://x.com",
+				Auth:   &model.Auth{Type: "basic", Primary: "ada", Secondary: "s3:cr:et"},
+			}},
+		},
+		{
+			name:    "user without a password",
+			command: "curl --user ada https://x.com",
+			want: CurlCommand{Template: model.Template{
+				Method: "GET",
+				URL:    "https://x.com",
+				Auth:   &model.Auth{Type: "basic", Primary: "ada"},
+			}},
+		},
+		{
+			name:    "digest user",
+			command: "curl --digest -u ada:secret https://x.com",
+			want: CurlCommand{Template: model.Template{
+				Method: "GET",
+				URL:    "https://x.com",
+				Auth:   &model.Auth{Type: "digest", Primary: "ada", Secondary: "secret"},
+			}},
+		},
+		{
+			name:    "aws sigv4 user",
+			command: "curl --aws-sigv4 aws:amz:eu-west-1:execute-api -u AKID:SECRET https://x.com",
+			want: CurlCommand{Template: model.Template{
+				Method: "GET",
+				URL:    "https://x.com",
+				Auth: &model.Auth{
+					Type: "aws", Primary: "AKID", Secondary: "SECRET",
+					AWS: &model.AWSSigning{Region: "eu-west-1", Service: "execute-api"},
+				},
+			}},
+		},
+		{
+			name:    "--data-binary @file keeps newlines",
+			command: "curl --data-binary @" + dataFile + " https://x.com",
+			want: CurlCommand{Template: model.Template{
+				Method:  "POST",
+				URL:     "https://x.com",
+				Headers: map[string]string{"Content-Type": "application/json"},
+				Body:    "{\n  \"name\": \"Rex\"\n}\n",
+			}},
+		},
+		{
+			name:    "-d @file strips newlines",
+			command: "curl -d @" + dataFile + " https://x.com",
+			want: CurlCommand{Template: model.Template{
+				Method:  "POST",
+				URL:     "https://x.com",
+				Headers: map[string]string{"Content-Type": "application/json"},
+				Body:    `{  "name": "Rex"}`,
+			}},
+		},
+		{
+			name:    "--data-raw keeps a leading @",
+			command: "curl --data-raw @handle -H 'Content-Type: text/plain' https://x.com",
+			want: CurlCommand{Template: model.Template{
+				Method:  "POST",
+				URL:     "https://x.com",
+				Headers: map[string]string{"Content-Type": "text/plain"},
+				Body:    "@handle",
+			}},
+		},
+		{
+			name:    "repeated data joined with &",
+			command: "curl -d a=1 --data-urlencode 'q=x y' https://x.com",
+			want: CurlCommand{Template: model.Template{
+				Method:  "POST",
+				URL:     "https://x.com",
+				Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+				Body:    "a=1&q=x+y",
+			}},
+		},
+		{
+			name:    "-G moves data to the query",
+			command: "curl -G -d q=rex https://x.com/search?page=2",
+			want: CurlCommand{Template: model.Template{
+				Method:      "GET",
+				URL:         "https://x.com/search",
+				QueryParams: map[string]string{"page": "2", "q": "rex"},
+			}},
+		},
+		{
+			name:    "form fields and files",
+			command: "curl -F name=Rex -F 'photo=@/tmp/rex.png;type=image/png' -F 'note=hi;type=text/plain' https://x.com",
+			want: CurlCommand{Template: model.Template{
+				Method:   "POST",
+				URL:      "https://x.com",
+				FormData: map[string]string{"name": "Rex", "note": "hi"},
+				Files:    map[string]string{"photo": "/tmp/rex.png"},
+			}},
+		},
+		{
+			name:    "head request and ignored options",
+			command: "curl -I --max-time 5 --fancy https://x.com",
+			want: CurlCommand{
+				Template: model.Template{Method: "HEAD", URL: "https://x.com"},
+				Ignored:  []string{"--max-time", "--fancy"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCurl(tt.command)
+			if err != nil {
+				t.Fatalf("ParseCurl() error = %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("ParseCurl() =\n%+v\nwant\n%+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCurlErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    string
+	}{
+		{name: "no URL", command: "curl -s", want: "no URL found"},
+		{name: "two URLs", command: "curl https://a.com https://b.com", want: "only one URL is supported"},
+		{name: "missing value", command: "curl https://x.com -H", want: "requires a value"},
+		{name: "header without a colon", command: "curl -H Accept https://x.com", want: "invalid header"},
+		{name: "form field without =", command: "curl -F name https://x.com", want: "invalid form field"},
+		{name: "missing data file", command: "curl -d @/nonexistent/body.json https://x.com", want: "failed to read data file"},
+		{name: "unterminated quote", command: "curl 'https://x.com", want: "unterminated single quote"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCurl(tt.command)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseCurl() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitShellWords(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    []string
+		wantErr string
+	}{
+		{name: "spaces and tabs", command: "curl  -s\thttps://x.com ", want: []string{"curl", "-s", "https://x.com"}},
+		{name: "single quotes are literal", command: `'a "b" \n $c'`, want: []string{`a "b" \n $c`}},
+		{name: "double quote escapes", command: `"a \"b\" \\ \$c \n"`, want: []string{`a "b" \ $c \n`}},
+		{name: "adjacent quoted parts join", command: `-H'X: '"y"z`, want: []string{"-HX: yz"}},
+		{name: "empty quotes are a word", command: `a '' ""`, want: []string{"a", "", ""}},
+		{name: "escaped space", command: `a\ b c`, want: []string{"a b", "c"}},
+		{name: "line continuation", command: "curl \\\n  -s \\\r\n  https://x.com", want: []string{"curl", "-s", "https://x.com"}},
+		{name: "ANSI-C string", command: `$'a\tb\n\x41é\'c'`, want: []string{"a\tb\nAé'c"}},
+		{name: "unterminated single quote", command: "'abc", wantErr: "unterminated single quote"},
+		{name: "unterminated double quote", command: `"abc`, wantErr: "unterminated double quote"},
+		{name: "unterminated ANSI-C string", command: `$'abc`, wantErr: "unterminated $'...' string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SplitShellWords(tt.command)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("SplitShellWords() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SplitShellWords() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitShellWords() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{word: "https://x.com/a?b", want: "'https://x.com/a?b'"},
+		{word: "ada:secret", want: "ada:secret"},
+		{word: "", want: "''"},
+		{word: "a b", want: "'a b'"},
+		{word: "it's", want: `'it'\''s'`},
+		{word: "{{token}}", want: "'{{token}}'"},
+		{word: "$HOME", want: "'$HOME'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			got := ShellQuote(tt.word)
+			if got != tt.want {
+				t.Errorf("ShellQuote(%q) = %s, want %s", tt.word, got, tt.want)
+			}
+			words, err := SplitShellWords(got)
+			if err != nil || !reflect.DeepEqual(words, []string{tt.word}) {
+				t.Errorf("SplitShellWords(%s) = %q, %v, want [%q]", got, words, err, tt.word)
+			}
+		})
+	}
+}
+
+func TestToCurlRoundTrip(t *testing.T) {
+	templates := []model.Template{
+		{
+			Method: "GET",
+			URL:    "https://api.example.com/pets",
+		},
+		{
+			Method:      "POST",
+			URL:         "{{base_url}}/pets",
+			Headers:     map[string]string{"Content-Type": "application/json", "X-Note": "it's 10:30"},
+			QueryParams: map[string]string{"dry_run": "true", "token": "{{token}}"},
+			Body:        "{\"name\": \"Rex\",\n \"tag\": \"dog & cat\"}",
+			Auth:        &model.Auth{Type: "bearer", Primary: "{{token}}"},
+		},
+		{
+			Method:  "PUT",
+			URL:     "https://api.example.com/login",
+			Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			Body:    "password=p%40ss&username=ada",
+			Auth:    &model.Auth{Type: "basic", Primary: "ada", Secondary: "p@ss:word"},
+		},
+		{
+			Method:   "POST",
+			URL:      "https://api.example.com/photos",
+			FormData: map[string]string{"caption": "Rex at the beach"},
+			Files:    map[string]string{"photo": "/tmp/my photos/rex.png"},
+			Auth:     &model.Auth{Type: "digest", Primary: "ada", Secondary: "secret"},
+		},
+		{
+			Method: "GET",
+			URL:    "https://abc.execute-api.eu-west-1.amazonaws.com/prod",
+			Auth: &model.Auth{
+				Type: "aws", Primary: "AKID", Secondary: "SECRET",
+				AWS: &model.AWSSigning{Region: "eu-west-1", Service: "execute-api"},
+			},
+		},
+	}
+
+	for _, template := range templates {
+		t.Run(template.Method+" "+template.URL, func(t *testing.T) {
+			command := ToCurl(template)
+			got, err := ParseCurl(command)
+			if err != nil {
+				t.Fatalf("ParseCurl(%s) error = %v", command, err)
+			}
+			if !reflect.DeepEqual(got.Template, template) {
+				t.Errorf("ParseCurl(%s) =\n%+v\nwant\n%+v", command, got.Template, template)
+			}
+		})
+	}
+}
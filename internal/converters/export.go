@@ -0,0 +1,111 @@
+package converters
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+// Export formats
+const (
+	FormatPostman  = "postman"
+	FormatInsomnia = "insomnia"
+	FormatOpenAPI  = "openapi"
+)
+
+// templateVariable matches {{name}} placeholders, as substituted by the http client
+var templateVariable = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.\-]+)\s*\}\}`)
+
+// ExportFormats lists the supported export formats
+var ExportFormats = []string{FormatPostman, FormatInsomnia, FormatOpenAPI}
+
+// Export renders templates in the given format. Template names containing "/" are placed in folders.
+func Export(format, name string, templates []model.Template) ([]byte, error) {
+	switch format {
+	case FormatPostman:
+		return ExportPostman(name, templates)
+	case FormatInsomnia:
+		return ExportInsomnia(name, templates)
+	case FormatOpenAPI:
+		return ExportOpenAPI(name, templates)
+	default:
+		return nil, fmt.Errorf("unknown export format '%s' (expected %s)", format, strings.Join(ExportFormats, ", "))
+	}
+}
+
+// requestBody is a template body reduced to what the export formats distinguish
+type requestBody struct {
+	mode        string // "", "raw", "urlencoded" or "formdata"
+	text        string
+	contentType string
+	fields      url.Values
+}
+
+// templateBody classifies a template's body, form data and files
+func templateBody(template model.Template) requestBody {
+	if len(template.FormData) > 0 || len(template.Files) > 0 {
+		return requestBody{mode: "formdata", contentType: "multipart/form-data"}
+	}
+
+	var text string
+	switch body := template.Body.(type) {
+	case nil:
+		return requestBody{}
+	case string:
+		text = body
+	case []byte:
+		text = string(body)
+	default:
+		data, err := json.MarshalIndent(body, "", "  ")
+		if err != nil {
+			text = fmt.Sprint(body)
+		} else {
+			text = string(data)
+		}
+	}
+	if text == "" {
+		return requestBody{}
+	}
+
+	contentType := headerValue(template.Headers, "Content-Type")
+	if strings.Contains(contentType, "application/x-www-form-urlencoded") {
+		if fields, err := url.ParseQuery(text); err == nil {
+			return requestBody{mode: "urlencoded", text: text, contentType: contentType, fields: fields}
+		}
+	}
+	if contentType == "" && json.Valid([]byte(text)) {
+		contentType = "application/json"
+	}
+	return requestBody{mode: "raw", text: text, contentType: contentType}
+}
+
+// headerValue returns the value of a header, ignoring the case of its name
+func headerValue(headers map[string]string, name string) string {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
+
+// splitFolder splits a template name such as "users/get" into its folder path and base name
+func splitFolder(name string) ([]string, string) {
+	parts := strings.Split(name, "/")
+	return parts[:len(parts)-1], parts[len(parts)-1]
+}
+
+// sortedKeys returns the keys of m in order, so exports are stable
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,187 @@
+package converters
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// assertGolden compares got with testdata/name, or rewrites the file when -update is set
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatalf("failed to update %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s (run go test -update to create it): %v", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s (run go test -update to accept it)\ngot:\n%s", path, got)
+	}
+}
+
+// exportTemplates covers each body mode and auth type the exporters translate
+func exportTemplates() []model.Template {
+	return []model.Template{
+		{
+			Name:        "pets/list",
+			Method:      "GET",
+			URL:         "{{base_url}}/pets",
+			Headers:     map[string]string{"Accept": "application/json"},
+			QueryParams: map[string]string{"limit": "20", "tag": "dog"},
+			Auth:        &model.Auth{Type: "bearer", Primary: "{{token}}"},
+		},
+		{
+			Name:    "pets/create",
+			Method:  "POST",
+			URL:     "{{base_url}}/pets",
+			Headers: map[string]string{"Content-Type": "application/json"},
+			Body:    `{"name":"Rex","tag":"dog"}`,
+			Auth:    &model.Auth{Type: "bearer", Primary: "{{token}}"},
+		},
+		{
+			Name:     "pets/upload",
+			Method:   "POST",
+			URL:      "{{base_url}}/pets/{{pet_id}}/photo",
+			FormData: map[string]string{"caption": "Rex at the beach"},
+			Files:    map[string]string{"photo": "/tmp/rex.png"},
+		},
+		{
+			Name:    "login",
+			Method:  "POST",
+			URL:     "{{base_url}}/login",
+			Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			Body:    "password=secret&username=ada",
+			Auth:    &model.Auth{Type: "basic", Primary: "ada", Secondary: "secret"},
+		},
+		{
+			Name:   "keys/header",
+			Method: "GET",
+			URL:    "{{base_url}}/keys/header",
+			Auth:   &model.Auth{Type: "apikey", Primary: "X-API-Key", Secondary: "{{api_key}}"},
+		},
+		{
+			Name:   "keys/query",
+			Method: "GET",
+			URL:    "{{base_url}}/keys/query",
+			Auth:   &model.Auth{Type: "apikey", Primary: "api_key", Secondary: "{{api_key}}", Location: "query"},
+		},
+		{
+			Name:   "keys/cookie",
+			Method: "GET",
+			URL:    "{{base_url}}/keys/cookie",
+			Auth:   &model.Auth{Type: "apikey", Primary: "session", Secondary: "{{session}}", Location: "cookie"},
+		},
+		{
+			Name:   "health",
+			Method: "GET",
+			URL:    "https://status.example.com/health",
+			Auth:   &model.Auth{Type: "none"},
+		},
+	}
+}
+
+func TestExportUnknownFormat(t *testing.T) {
+	_, err := Export("har", "petstore", exportTemplates())
+	if err == nil || !strings.Contains(err.Error(), "unknown export format 'har'") {
+		t.Errorf("Export() error = %v, want an unknown format error", err)
+	}
+}
+
+func TestExportInsomnia(t *testing.T) {
+	data, err := ExportInsomnia("petstore", exportTemplates())
+	if err != nil {
+		t.Fatalf("ExportInsomnia() error = %v", err)
+	}
+
+	// The export date changes on every run, so pin it before comparing
+	var export insomniaExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		t.Fatalf("ExportInsomnia() returned invalid JSON: %v", err)
+	}
+	if _, err := time.Parse(time.RFC3339, export.ExportDate); err != nil {
+		t.Errorf("__export_date %q is not RFC 3339: %v", export.ExportDate, err)
+	}
+	export.ExportDate = "2024-01-01T00:00:00Z"
+	data, err = json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "petstore.insomnia.golden.json", data)
+}
+
+func TestExportOpenAPI(t *testing.T) {
+	data, err := ExportOpenAPI("petstore", exportTemplates())
+	if err != nil {
+		t.Fatalf("ExportOpenAPI() error = %v", err)
+	}
+	assertGolden(t, "petstore.openapi.golden.json", data)
+}
+
+func TestExportOpenAPISecuritySchemes(t *testing.T) {
+	data, err := ExportOpenAPI("petstore", exportTemplates())
+	if err != nil {
+		t.Fatalf("ExportOpenAPI() error = %v", err)
+	}
+
+	var document struct {
+		Paths      map[string]map[string]struct{ Security []map[string][]string }
+		Components struct {
+			SecuritySchemes map[string]map[string]string
+		}
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		t.Fatalf("ExportOpenAPI() returned invalid JSON: %v", err)
+	}
+
+	tests := []struct {
+		path   string
+		scheme string
+		want   map[string]string
+	}{
+		{path: "/keys/header", scheme: "apiKey_X_API_Key", want: map[string]string{"type": "apiKey", "in": "header", "name": "X-API-Key"}},
+		{path: "/keys/query", scheme: "apiKey_api_key", want: map[string]string{"type": "apiKey", "in": "query", "name": "api_key"}},
+		{path: "/keys/cookie", scheme: "apiKey_session", want: map[string]string{"type": "apiKey", "in": "cookie", "name": "session"}},
+		{path: "/pets", scheme: "bearerAuth", want: map[string]string{"type": "http", "scheme": "bearer"}},
+		{path: "/login", scheme: "basicAuth", want: map[string]string{"type": "http", "scheme": "basic"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := document.Components.SecuritySchemes[tt.scheme]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("securitySchemes[%s] = %v, want %v", tt.scheme, got, tt.want)
+			}
+
+			var operation struct{ Security []map[string][]string }
+			for _, op := range document.Paths[tt.path] {
+				operation = op
+				break
+			}
+			want := []map[string][]string{{tt.scheme: {}}}
+			if !reflect.DeepEqual(operation.Security, want) {
+				t.Errorf("%s security = %v, want %v", tt.path, operation.Security, want)
+			}
+		})
+	}
+
+	// Requests without auth, or that opt out of it, add no scheme
+	if len(document.Components.SecuritySchemes) != len(tests) {
+		t.Errorf("got %d security schemes, want %d", len(document.Components.SecuritySchemes), len(tests))
+	}
+}
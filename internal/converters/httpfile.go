@@ -0,0 +1,284 @@
+package converters
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+// HTTPFile is a parsed .http/.rest request file (JetBrains HTTP Client / VS Code REST Client format)
+type HTTPFile struct {
+	Variables map[string]string // @name = value definitions
+	Templates []model.Template  // one per request, in file order
+}
+
+var (
+	// httpFileVariable matches "@name = value" definitions
+	httpFileVariable = regexp.MustCompile(`^@([A-Za-z0-9_.\-]+)\s*=\s*(.*)$`)
+	// httpRequestName matches "# @name login" and "// @name login"
+	httpRequestName = regexp.MustCompile(`^(?:#|//)\s*@name\s*=?\s*(\S+)`)
+	// httpRequestLine matches "METHOD url [HTTP/version]"
+	httpRequestLine = regexp.MustCompile(`^([A-Z]+)\s+(\S.*?)(?:\s+HTTP/[0-9.]+)?$`)
+	// httpHeaderLine matches "Name: value"
+	httpHeaderLine = regexp.MustCompile(`^([A-Za-z0-9!#$%&'*+.^_` + "`" + `|~\-]+)\s*:\s*(.*)$`)
+)
+
+// httpMethods are the request methods recognised at the start of a request line
+var httpMethods = map[string]bool{
+	"GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true,
+	"HEAD": true, "OPTIONS": true, "TRACE": true, "CONNECT": true,
+}
+
+// ParseHTTPFile reads a .http or .rest file. Body includes ("< ./file.json") are resolved
+// relative to the file's directory.
+func ParseHTTPFile(path string) (*HTTPFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	file, err := ParseHTTPFileData(string(data), filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return file, nil
+}
+
+// ParseHTTPFileData parses the contents of a request file. File variables are substituted into
+// the requests; other {{placeholders}} are left for the active environment.
+func ParseHTTPFileData(data, dir string) (*HTTPFile, error) {
+	file := &HTTPFile{Variables: make(map[string]string)}
+
+	var blocks []httpBlock
+	current := httpBlock{}
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if strings.HasPrefix(line, "###") {
+			blocks = append(blocks, current)
+			current = httpBlock{title: strings.TrimSpace(strings.TrimLeft(line, "#")), line: lineNumber + 1}
+			continue
+		}
+		if current.line == 0 {
+			current.line = lineNumber
+		}
+		current.lines = append(current.lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	blocks = append(blocks, current)
+
+	names := newNameSet()
+	for _, block := range blocks {
+		template, err := block.parse(file.Variables, dir)
+		if err != nil {
+			return nil, err
+		}
+		if template == nil {
+			continue
+		}
+
+		if template.Name == "" {
+			template.Name = fmt.Sprintf("request-%d", len(file.Templates)+1)
+		}
+		template.Name = names.unique(SanitizeName(template.Name))
+		file.Templates = append(file.Templates, *template)
+	}
+
+	if len(file.Templates) == 0 {
+		return nil, fmt.Errorf("no requests found")
+	}
+
+	resolved := resolveFileVariables(file.Variables)
+	for i := range file.Templates {
+		substituteTemplate(&file.Templates[i], resolved)
+	}
+	return file, nil
+}
+
+// httpBlock is the text between two ### separators
+type httpBlock struct {
+	title string
+	line  int
+	lines []string
+}
+
+// parse reads the block's variables into variables and returns its request, or nil if it has none
+func (b httpBlock) parse(variables map[string]string, dir string) (*model.Template, error) {
+	template := &model.Template{Name: b.title}
+
+	i := 0
+	// Variables, comments and blank lines before the request line
+	for ; i < len(b.lines); i++ {
+		line := strings.TrimSpace(b.lines[i])
+		if line == "" {
+			continue
+		}
+		if match := httpFileVariable.FindStringSubmatch(line); match != nil {
+			variables[match[1]] = strings.TrimSpace(match[2])
+			continue
+		}
+		if match := httpRequestName.FindStringSubmatch(line); match != nil {
+			template.Name = match[1]
+			continue
+		}
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		break
+	}
+	if i == len(b.lines) {
+		return nil, nil
+	}
+
+	requestLine := strings.TrimSpace(b.lines[i])
+	if match := httpRequestLine.FindStringSubmatch(requestLine); match != nil && httpMethods[match[1]] {
+		template.Method, template.URL = match[1], strings.TrimSpace(match[2])
+	} else if strings.Contains(requestLine, "://") || strings.HasPrefix(requestLine, "{{") || strings.HasPrefix(requestLine, "/") {
+		template.Method, template.URL = "GET", strings.Fields(requestLine)[0]
+	} else {
+		return nil, fmt.Errorf("line %d: expected a request line such as 'GET https://example.com', got %q", b.line+i, requestLine)
+	}
+	i++
+
+	// Query continuation lines ("  ?page=2", "  &limit=10")
+	for ; i < len(b.lines); i++ {
+		line := strings.TrimSpace(b.lines[i])
+		if !strings.HasPrefix(line, "?") && !strings.HasPrefix(line, "&") {
+			break
+		}
+		template.URL += line
+	}
+
+	// Headers until the first blank line
+	for ; i < len(b.lines); i++ {
+		line := strings.TrimSpace(b.lines[i])
+		if line == "" {
+			i++
+			break
+		}
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		match := httpHeaderLine.FindStringSubmatch(line)
+		if match == nil {
+			return nil, fmt.Errorf("line %d: expected a header such as 'Accept: application/json', got %q", b.line+i, line)
+		}
+		if template.Headers == nil {
+			template.Headers = make(map[string]string)
+		}
+		template.Headers[match[1]] = match[2]
+	}
+
+	body, err := httpBody(b.lines[min(i, len(b.lines)):], dir)
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %w", b.line+i, err)
+	}
+	if body != "" {
+		template.Body = body
+	}
+
+	base, query, _ := strings.Cut(template.URL, "?")
+	template.URL = base
+	if query != "" {
+		template.QueryParams = make(map[string]string)
+		addQuery(template.QueryParams, query)
+	}
+	return template, nil
+}
+
+// httpBody joins the body lines, resolving "< file" includes and dropping response handlers
+func httpBody(lines []string, dir string) (string, error) {
+	var body []string
+	inScript := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		// Response handlers and response references run after the request, which apix does not support
+		if inScript {
+			inScript = !strings.Contains(trimmed, "%}")
+			continue
+		}
+		if strings.HasPrefix(trimmed, "> {%") {
+			inScript = !strings.Contains(trimmed[4:], "%}")
+			continue
+		}
+		if strings.HasPrefix(trimmed, "> ") || strings.HasPrefix(trimmed, ">>") || strings.HasPrefix(trimmed, "<> ") {
+			continue
+		}
+
+		if include, ok := strings.CutPrefix(trimmed, "<"); ok && (strings.HasPrefix(include, " ") || strings.HasPrefix(include, "@ ")) {
+			includePath := strings.TrimSpace(strings.TrimPrefix(include, "@"))
+			if !filepath.IsAbs(includePath) {
+				includePath = filepath.Join(dir, includePath)
+			}
+			content, err := os.ReadFile(includePath)
+			if err != nil {
+				return "", fmt.Errorf("failed to read body file: %w", err)
+			}
+			body = append(body, strings.TrimRight(string(content), "\r\n"))
+			continue
+		}
+		body = append(body, line)
+	}
+
+	return strings.TrimSpace(strings.Join(body, "\n")), nil
+}
+
+// resolveFileVariables substitutes file variables that reference each other
+func resolveFileVariables(variables map[string]string) map[string]string {
+	resolved := make(map[string]string, len(variables))
+	for name, value := range variables {
+		resolved[name] = value
+	}
+
+	// Bounded so that cyclic definitions terminate
+	for range len(resolved) {
+		changed := false
+		for name, value := range resolved {
+			substituted := substituteVariables(value, resolved)
+			if substituted != value {
+				resolved[name] = substituted
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	return resolved
+}
+
+// substituteVariables replaces the {{name}} placeholders defined in variables, leaving others in place
+func substituteVariables(value string, variables map[string]string) string {
+	return templateVariable.ReplaceAllStringFunc(value, func(match string) string {
+		name := templateVariable.FindStringSubmatch(match)[1]
+		if replacement, ok := variables[name]; ok {
+			return replacement
+		}
+		return match
+	})
+}
+
+func substituteTemplate(template *model.Template, variables map[string]string) {
+	template.URL = substituteVariables(template.URL, variables)
+	for key, value := range template.Headers {
+		template.Headers[key] = substituteVariables(value, variables)
+	}
+	for key, value := range template.QueryParams {
+		template.QueryParams[key] = substituteVariables(value, variables)
+	}
+	if body, ok := template.Body.(string); ok {
+		template.Body = substituteVariables(body, variables)
+	}
+}
@@ -0,0 +1,169 @@
+package converters
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+func TestParseHTTPFileData(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		wantTemplates []model.Template
+		wantVariables map[string]string
+	}{
+		{
+			name:          "single request without a separator",
+			data:          "GET https://x.com/pets\n",
+			wantTemplates: []model.Template{{Name: "request-1", Method: "GET", URL: "https://x.com/pets"}},
+		},
+		{
+			name: "### separators and titles",
+			data: "### List pets\nGET https://x.com/pets\n\n###\nDELETE https://x.com/pets/1\n### Create pet\r\nPOST https://x.com/pets\r\n",
+			wantTemplates: []model.Template{
+				{Name: "List pets", Method: "GET", URL: "https://x.com/pets"},
+				{Name: "request-2", Method: "DELETE", URL: "https://x.com/pets/1"},
+				{Name: "Create pet", Method: "POST", URL: "https://x.com/pets"},
+			},
+		},
+		{
+			name: "@variables are substituted, others are left for the environment",
+			data: "@host = https://x.com\n@api={{host}}/v1\n\n###\nGET {{api}}/pets?owner={{owner}}\nAuthorization: Bearer {{token}}\nX-Api: {{ api }}\n",
+			wantTemplates: []model.Template{{
+				Name:        "request-1",
+				Method:      "GET",
+				URL:         "https://x.com/v1/pets",
+				QueryParams: map[string]string{"owner": "{{owner}}"},
+				Headers:     map[string]string{"Authorization": "Bearer {{token}}", "X-Api": "https://x.com/v1"},
+			}},
+			wantVariables: map[string]string{"host": "https://x.com", "api": "{{host}}/v1"},
+		},
+		{
+			name: "comments before the request and between headers",
+			data: "# List every pet\n// owned by anyone\nGET https://x.com/pets\n# Accept: text/html\nAccept: application/json\n// X-Debug: 1\n",
+			wantTemplates: []model.Template{{
+				Name:    "request-1",
+				Method:  "GET",
+				URL:     "https://x.com/pets",
+				Headers: map[string]string{"Accept": "application/json"},
+			}},
+		},
+		{
+			name: "JSON body after a blank line, without the response handler",
+			data: "POST https://x.com/pets HTTP/1.1\nContent-Type: application/json\n\n{\n  \"name\": \"Rex\"\n}\n\n> {%\n  client.global.set(\"id\", response.body.id);\n%}\n",
+			wantTemplates: []model.Template{{
+				Name:    "request-1",
+				Method:  "POST",
+				URL:     "https://x.com/pets",
+				Headers: map[string]string{"Content-Type": "application/json"},
+				Body:    "{\n  \"name\": \"Rex\"\n}",
+			}},
+		},
+		{
+			name: "body without headers and response references",
+			data: "PUT https://x.com/notes/1\n\nbuy milk\n>> saved.json\n<> previous.json\n",
+			wantTemplates: []model.Template{{
+				Name:   "request-1",
+				Method: "PUT",
+				URL:    "https://x.com/notes/1",
+				Body:   "buy milk",
+			}},
+		},
+		{
+			name: "# @name and // @name override the separator title",
+			data: "### Sign in\n# @name login\nPOST https://x.com/login\n\n###\n// @name = whoami\nGET https://x.com/me\n",
+			wantTemplates: []model.Template{
+				{Name: "login", Method: "POST", URL: "https://x.com/login"},
+				{Name: "whoami", Method: "GET", URL: "https://x.com/me"},
+			},
+		},
+		{
+			name: "duplicate names are made unique",
+			data: "### ping\nGET https://x.com/ping\n### ping\nGET https://y.com/ping\n### a/b\nGET https://x.com/a/b\n",
+			wantTemplates: []model.Template{
+				{Name: "ping", Method: "GET", URL: "https://x.com/ping"},
+				{Name: "ping-2", Method: "GET", URL: "https://y.com/ping"},
+				{Name: "a-b", Method: "GET", URL: "https://x.com/a/b"},
+			},
+		},
+		{
+			name: "URL-only request line and query continuation lines",
+			data: "https://x.com/search\n    ?q=rex\n    &page=2\nAccept: */*\n",
+			wantTemplates: []model.Template{{
+				Name:        "request-1",
+				Method:      "GET",
+				URL:         "https://x.com/search",
+				QueryParams: map[string]string{"q": "rex", "page": "2"},
+				Headers:     map[string]string{"Accept": "*/*"},
+			}},
+		},
+		{
+			name:          "blocks with only comments or variables are skipped",
+			data:          "### Setup\n@token = abc\n# nothing to send\n\n### Ping\nGET https://x.com/ping\n###\n\n",
+			wantTemplates: []model.Template{{Name: "Ping", Method: "GET", URL: "https://x.com/ping"}},
+			wantVariables: map[string]string{"token": "abc"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := ParseHTTPFileData(tt.data, t.TempDir())
+			if err != nil {
+				t.Fatalf("ParseHTTPFileData() error = %v", err)
+			}
+			if !reflect.DeepEqual(file.Templates, tt.wantTemplates) {
+				t.Errorf("templates =\n%+v\nwant\n%+v", file.Templates, tt.wantTemplates)
+			}
+			wantVariables := tt.wantVariables
+			if wantVariables == nil {
+				wantVariables = map[string]string{}
+			}
+			if !reflect.DeepEqual(file.Variables, wantVariables) {
+				t.Errorf("variables = %v, want %v", file.Variables, wantVariables)
+			}
+		})
+	}
+}
+
+func TestParseHTTPFileDataBodyInclude(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "pet.json"), []byte("{\"name\": \"{{pet}}\"}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := ParseHTTPFileData("@pet = Rex\nPOST https://x.com/pets\nContent-Type: application/json\n\n< ./pet.json\n", dir)
+	if err != nil {
+		t.Fatalf("ParseHTTPFileData() error = %v", err)
+	}
+	if got := file.Templates[0].Body; got != `{"name": "Rex"}` {
+		t.Errorf("body = %q, want the included file with variables substituted", got)
+	}
+}
+
+func TestParseHTTPFileDataErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "empty file", data: "", want: "no requests found"},
+		{name: "only comments", data: "# nothing here\n###\n// still nothing\n", want: "no requests found"},
+		{name: "bad request line", data: "### one\nGET https://x.com\n### two\n\nfetch the pets\n", want: "line 5: expected a request line"},
+		{name: "bad header", data: "GET https://x.com\nAccept application/json\n", want: "line 2: expected a header"},
+		{name: "missing include", data: "POST https://x.com\n\n< ./missing.json\n", want: "line 3: failed to read body file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseHTTPFileData(tt.data, t.TempDir())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseHTTPFileData() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
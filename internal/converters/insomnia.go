@@ -0,0 +1,180 @@
+package converters
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+type insomniaExport struct {
+	Type         string             `json:"_type"`
+	ExportFormat int                `json:"__export_format"`
+	ExportDate   string             `json:"__export_date"`
+	ExportSource string             `json:"__export_source"`
+	Resources    []insomniaResource `json:"resources"`
+}
+
+// insomniaResource is a workspace, request group (folder) or request
+type insomniaResource struct {
+	ID             string          `json:"_id"`
+	Type           string          `json:"_type"`
+	ParentID       *string         `json:"parentId"`
+	Name           string          `json:"name"`
+	Method         string          `json:"method,omitempty"`
+	URL            string          `json:"url,omitempty"`
+	Body           *insomniaBody   `json:"body,omitempty"`
+	Parameters     []insomniaParam `json:"parameters,omitempty"`
+	Headers        []insomniaParam `json:"headers,omitempty"`
+	Authentication map[string]any  `json:"authentication,omitempty"`
+}
+
+type insomniaBody struct {
+	MimeType string          `json:"mimeType"`
+	Text     string          `json:"text,omitempty"`
+	Params   []insomniaParam `json:"params,omitempty"`
+}
+
+type insomniaParam struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Type     string `json:"type,omitempty"`
+	FileName string `json:"fileName,omitempty"`
+}
+
+// ExportInsomnia renders templates as an Insomnia v4 export with a workspace named name
+func ExportInsomnia(name string, templates []model.Template) ([]byte, error) {
+	workspaceID := insomniaID("wrk", name)
+	export := insomniaExport{
+		Type:         "export",
+		ExportFormat: 4,
+		ExportDate:   time.Now().UTC().Format(time.RFC3339),
+		ExportSource: "apix",
+		Resources: []insomniaResource{
+			{ID: workspaceID, Type: "workspace", Name: name},
+		},
+	}
+
+	groups := make(map[string]string)
+	for _, template := range templates {
+		folders, base := splitFolder(template.Name)
+
+		parentID := workspaceID
+		for i, folder := range folders {
+			folderPath := path.Join(folders[:i+1]...)
+			id, exists := groups[folderPath]
+			if !exists {
+				id = insomniaID("fld", folderPath)
+				groups[folderPath] = id
+				export.Resources = append(export.Resources, insomniaResource{
+					ID: id, Type: "request_group", ParentID: stringPtr(parentID), Name: folder,
+				})
+			}
+			parentID = id
+		}
+
+		request := toInsomniaRequest(template)
+		request.ID = insomniaID("req", template.Name)
+		request.ParentID = stringPtr(parentID)
+		request.Name = base
+		export.Resources = append(export.Resources, request)
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Insomnia export: %w", err)
+	}
+	return data, nil
+}
+
+func toInsomniaRequest(template model.Template) insomniaResource {
+	request := insomniaResource{
+		Type:           "request",
+		Method:         template.Method,
+		URL:            insomniaVariables(template.URL),
+		Authentication: toInsomniaAuth(template.Auth),
+	}
+
+	for _, key := range sortedKeys(template.Headers) {
+		request.Headers = append(request.Headers, insomniaParam{Name: key, Value: insomniaVariables(template.Headers[key])})
+	}
+	for _, key := range sortedKeys(template.QueryParams) {
+		request.Parameters = append(request.Parameters, insomniaParam{Name: key, Value: insomniaVariables(template.QueryParams[key])})
+	}
+
+	body := templateBody(template)
+	switch body.mode {
+	case "raw":
+		request.Body = &insomniaBody{MimeType: body.contentType, Text: insomniaVariables(body.text)}
+	case "urlencoded":
+		request.Body = &insomniaBody{MimeType: "application/x-www-form-urlencoded"}
+		for _, field := range formFields(body.fields) {
+			request.Body.Params = append(request.Body.Params, insomniaParam{Name: field[0], Value: insomniaVariables(field[1])})
+		}
+	case "formdata":
+		request.Body = &insomniaBody{MimeType: "multipart/form-data"}
+		for _, key := range sortedKeys(template.FormData) {
+			request.Body.Params = append(request.Body.Params, insomniaParam{Name: key, Value: insomniaVariables(template.FormData[key])})
+		}
+		for _, key := range sortedKeys(template.Files) {
+			request.Body.Params = append(request.Body.Params, insomniaParam{Name: key, Type: "file", FileName: template.Files[key]})
+		}
+	}
+
+	return request
+}
+
+func toInsomniaAuth(auth *model.Auth) map[string]any {
+	if auth == nil {
+		return nil
+	}
+
+	switch auth.Type {
+	case "bearer":
+		return map[string]any{"type": "bearer", "token": insomniaVariables(auth.Primary)}
+	case "basic":
+		return map[string]any{
+			"type":     "basic",
+			"username": insomniaVariables(auth.Primary),
+			"password": insomniaVariables(auth.Secondary),
+		}
+	case "apikey":
+		return map[string]any{
+			"type":  "apikey",
+			"key":   auth.Primary,
+			"value": insomniaVariables(auth.Secondary),
+			"addTo": insomniaAPIKeyLocation(auth),
+		}
+	case "none":
+		return map[string]any{"type": "none"}
+	default:
+		return nil
+	}
+}
+
+// insomniaAPIKeyLocation returns Insomnia's name for where API key auth sends the key
+func insomniaAPIKeyLocation(auth *model.Auth) string {
+	if location := apiKeyLocation(auth); location != "query" {
+		return location
+	}
+	return "queryParams"
+}
+
+// insomniaVariables rewrites {{name}} placeholders to Insomnia's {{ _.name }} environment syntax
+func insomniaVariables(value string) string {
+	return templateVariable.ReplaceAllString(value, "{{ _.$1 }}")
+}
+
+// insomniaID derives a stable resource ID, so repeated exports update the same Insomnia resources
+func insomniaID(prefix, name string) string {
+	sum := sha1.Sum([]byte(prefix + "/" + name))
+	return prefix + "_" + hex.EncodeToString(sum[:])[:32]
+}
+
+func stringPtr(value string) *string {
+	return &value
+}
@@ -0,0 +1,254 @@
+package converters
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+// openAPIVersion is the OpenAPI version of exported documents
+const openAPIVersion = "3.0.3"
+
+// implicitHeaders are described by the request body or security schemes rather than as parameters
+var implicitHeaders = []string{"Accept", "Authorization", "Content-Type", "Cookie"}
+
+// urlHost matches the scheme and host of an absolute URL
+var urlHost = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.\-]*://[^/?#]*`)
+
+// pathParameter matches {name} segments of an OpenAPI path
+var pathParameter = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// nonIdentifier matches runs of characters not allowed in operation IDs
+var nonIdentifier = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// ExportOpenAPI derives a skeletal OpenAPI 3 document from template methods, paths, query
+// parameters, headers, sample bodies and auth. Templates sharing a method and path are
+// described by the first of them.
+func ExportOpenAPI(name string, templates []model.Template) ([]byte, error) {
+	var servers []map[string]any
+	seenServers := make(map[string]bool)
+	paths := make(map[string]map[string]any)
+	securitySchemes := make(map[string]any)
+
+	for _, template := range templates {
+		server, apiPath := splitServer(template.URL)
+		if server != "" && !seenServers[server] {
+			seenServers[server] = true
+			servers = append(servers, openAPIServer(server))
+		}
+
+		method := strings.ToLower(template.Method)
+		if paths[apiPath] == nil {
+			paths[apiPath] = make(map[string]any)
+		}
+		if _, exists := paths[apiPath][method]; exists {
+			continue
+		}
+
+		operation := openAPIOperation(template, apiPath)
+		if scheme, definition := openAPISecurity(template.Auth); scheme != "" {
+			securitySchemes[scheme] = definition
+			operation["security"] = []map[string][]string{{scheme: {}}}
+		}
+		paths[apiPath][method] = operation
+	}
+
+	document := map[string]any{
+		"openapi": openAPIVersion,
+		"info": map[string]any{
+			"title":       name,
+			"version":     "1.0.0",
+			"description": "Generated by apix from saved templates",
+		},
+		"paths": paths,
+	}
+	if len(servers) > 0 {
+		document["servers"] = servers
+	}
+	if len(securitySchemes) > 0 {
+		document["components"] = map[string]any{"securitySchemes": securitySchemes}
+	}
+
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal OpenAPI document: %w", err)
+	}
+	return data, nil
+}
+
+// splitServer splits a template URL into its server ("https://host" or a leading {{variable})
+// and an OpenAPI path with {{name}} placeholders turned into {name}
+func splitServer(rawURL string) (string, string) {
+	rawURL, _, _ = strings.Cut(rawURL, "?")
+
+	var server string
+	if loc := urlHost.FindStringIndex(rawURL); loc != nil {
+		server = rawURL[:loc[1]]
+	} else if strings.HasPrefix(rawURL, "{{") {
+		if end := strings.Index(rawURL, "}}"); end >= 0 {
+			server = rawURL[:end+2]
+		}
+	}
+
+	apiPath := strings.TrimSuffix(rawURL[len(server):], "/")
+	if !strings.HasPrefix(apiPath, "/") {
+		apiPath = "/" + apiPath
+	}
+	return server, templateVariable.ReplaceAllString(apiPath, "{$1}")
+}
+
+// openAPIServer describes a server; a {{variable}} server becomes a server variable
+func openAPIServer(server string) map[string]any {
+	match := templateVariable.FindStringSubmatch(server)
+	if match == nil {
+		return map[string]any{"url": server}
+	}
+	return map[string]any{
+		"url": "{" + match[1] + "}",
+		"variables": map[string]any{
+			match[1]: map[string]any{
+				"default":     "http://localhost",
+				"description": fmt.Sprintf("Value of the apix variable {{%s}}", match[1]),
+			},
+		},
+	}
+}
+
+func openAPIOperation(template model.Template, apiPath string) map[string]any {
+	operation := map[string]any{
+		"summary":     template.Name,
+		"operationId": operationID(template.Name),
+		"responses": map[string]any{
+			"200": map[string]any{"description": "Successful response"},
+		},
+	}
+
+	var parameters []map[string]any
+	for _, match := range pathParameter.FindAllStringSubmatch(apiPath, -1) {
+		parameters = append(parameters, map[string]any{
+			"name": match[1], "in": "path", "required": true, "schema": map[string]any{"type": "string"},
+		})
+	}
+	for _, key := range sortedKeys(template.QueryParams) {
+		parameters = append(parameters, map[string]any{
+			"name": key, "in": "query", "schema": map[string]any{"type": "string"}, "example": template.QueryParams[key],
+		})
+	}
+	for _, key := range sortedKeys(template.Headers) {
+		if isImplicitHeader(key, template.Auth) {
+			continue
+		}
+		parameters = append(parameters, map[string]any{
+			"name": key, "in": "header", "schema": map[string]any{"type": "string"}, "example": template.Headers[key],
+		})
+	}
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+
+	if content := openAPIContent(template); content != nil {
+		operation["requestBody"] = map[string]any{"content": content}
+	}
+	return operation
+}
+
+// openAPIContent describes the request body by media type, with a schema inferred from the sample
+func openAPIContent(template model.Template) map[string]any {
+	body := templateBody(template)
+	switch body.mode {
+	case "raw":
+		var sample any
+		if json.Unmarshal([]byte(body.text), &sample) == nil {
+			return map[string]any{"application/json": map[string]any{"schema": inferSchema(sample), "example": sample}}
+		}
+		contentType := body.contentType
+		if contentType == "" {
+			contentType = "text/plain"
+		}
+		return map[string]any{contentType: map[string]any{"schema": map[string]any{"type": "string"}, "example": body.text}}
+	case "urlencoded":
+		properties := make(map[string]any)
+		for _, field := range formFields(body.fields) {
+			properties[field[0]] = map[string]any{"type": "string", "example": field[1]}
+		}
+		return map[string]any{"application/x-www-form-urlencoded": map[string]any{
+			"schema": map[string]any{"type": "object", "properties": properties},
+		}}
+	case "formdata":
+		properties := make(map[string]any)
+		for key, value := range template.FormData {
+			properties[key] = map[string]any{"type": "string", "example": value}
+		}
+		for key := range template.Files {
+			properties[key] = map[string]any{"type": "string", "format": "binary"}
+		}
+		return map[string]any{"multipart/form-data": map[string]any{
+			"schema": map[string]any{"type": "object", "properties": properties},
+		}}
+	default:
+		return nil
+	}
+}
+
+// inferSchema builds a JSON schema matching a sample value
+func inferSchema(sample any) map[string]any {
+	switch v := sample.(type) {
+	case map[string]any:
+		properties := make(map[string]any, len(v))
+		for key, value := range v {
+			properties[key] = inferSchema(value)
+		}
+		return map[string]any{"type": "object", "properties": properties}
+	case []any:
+		if len(v) == 0 {
+			return map[string]any{"type": "array", "items": map[string]any{}}
+		}
+		return map[string]any{"type": "array", "items": inferSchema(v[0])}
+	case float64:
+		if v == float64(int64(v)) {
+			return map[string]any{"type": "integer"}
+		}
+		return map[string]any{"type": "number"}
+	case bool:
+		return map[string]any{"type": "boolean"}
+	case nil:
+		return map[string]any{"nullable": true}
+	default:
+		return map[string]any{"type": "string"}
+	}
+}
+
+// openAPISecurity returns the security scheme name and definition for a template's auth
+func openAPISecurity(auth *model.Auth) (string, map[string]any) {
+	if auth == nil {
+		return "", nil
+	}
+
+	switch auth.Type {
+	case "bearer":
+		return "bearerAuth", map[string]any{"type": "http", "scheme": "bearer"}
+	case "basic":
+		return "basicAuth", map[string]any{"type": "http", "scheme": "basic"}
+	case "apikey":
+		return "apiKey_" + operationID(auth.Primary), map[string]any{"type": "apiKey", "in": apiKeyLocation(auth), "name": auth.Primary}
+	default:
+		return "", nil
+	}
+}
+
+func isImplicitHeader(name string, auth *model.Auth) bool {
+	for _, header := range implicitHeaders {
+		if strings.EqualFold(name, header) {
+			return true
+		}
+	}
+	return auth != nil && auth.Type == "apikey" && apiKeyLocation(auth) == "header" && strings.EqualFold(name, auth.Primary)
+}
+
+// operationID turns a template name into an identifier such as users_get_user
+func operationID(name string) string {
+	return strings.Trim(nonIdentifier.ReplaceAllString(name, "_"), "_")
+}
@@ -0,0 +1,360 @@
+package converters
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+// PostmanSchema is the schema URL of Postman Collection v2.1
+const PostmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+type postmanCollection struct {
+	Info     postmanInfo       `json:"info"`
+	Item     []postmanItem     `json:"item"`
+	Auth     *postmanAuth      `json:"auth,omitempty"`
+	Variable []postmanKeyValue `json:"variable,omitempty"`
+}
+
+type postmanInfo struct {
+	PostmanID string `json:"_postman_id,omitempty"`
+	Name      string `json:"name"`
+	Schema    string `json:"schema"`
+}
+
+// postmanItem is either a folder (Item set) or a request (Request set)
+type postmanItem struct {
+	Name    string          `json:"name"`
+	Item    []postmanItem   `json:"item,omitempty"`
+	Request *postmanRequest `json:"request,omitempty"`
+	Auth    *postmanAuth    `json:"auth,omitempty"`
+}
+
+type postmanRequest struct {
+	Method string            `json:"method"`
+	Header []postmanKeyValue `json:"header"`
+	URL    postmanURL        `json:"url"`
+	Body   *postmanBody      `json:"body,omitempty"`
+	Auth   *postmanAuth      `json:"auth,omitempty"`
+}
+
+type postmanURL struct {
+	Raw      string            `json:"raw"`
+	Host     []string          `json:"host,omitempty"`
+	Path     []string          `json:"path,omitempty"`
+	Query    []postmanKeyValue `json:"query,omitempty"`
+	Variable []postmanKeyValue `json:"variable,omitempty"`
+}
+
+// UnmarshalJSON accepts both the object form and the plain string form of a URL
+func (u *postmanURL) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		u.Raw = raw
+		return nil
+	}
+
+	type plain postmanURL
+	return json.Unmarshal(data, (*plain)(u))
+}
+
+type postmanBody struct {
+	Mode       string            `json:"mode"`
+	Raw        string            `json:"raw,omitempty"`
+	URLEncoded []postmanKeyValue `json:"urlencoded,omitempty"`
+	FormData   []postmanKeyValue `json:"formdata,omitempty"`
+	Options    *postmanBodyOpts  `json:"options,omitempty"`
+}
+
+type postmanBodyOpts struct {
+	Raw struct {
+		Language string `json:"language"`
+	} `json:"raw"`
+}
+
+type postmanAuth struct {
+	Type   string            `json:"type"`
+	Bearer []postmanKeyValue `json:"bearer,omitempty"`
+	Basic  []postmanKeyValue `json:"basic,omitempty"`
+	APIKey []postmanKeyValue `json:"apikey,omitempty"`
+}
+
+type postmanKeyValue struct {
+	Key      string `json:"key"`
+	Value    any    `json:"value"`
+	Type     string `json:"type,omitempty"`
+	Src      any    `json:"src,omitempty"`
+	Disabled bool   `json:"disabled,omitempty"`
+	Enabled  *bool  `json:"enabled,omitempty"`
+}
+
+// value returns the entry's value as a string
+func (kv postmanKeyValue) value() string {
+	switch v := kv.Value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// active reports whether the entry is enabled
+func (kv postmanKeyValue) active() bool {
+	return !kv.Disabled && (kv.Enabled == nil || *kv.Enabled)
+}
+
+type postmanEnvironment struct {
+	Name   string            `json:"name"`
+	Values []postmanKeyValue `json:"values"`
+}
+
+// IsPostmanEnvironment reports whether data looks like a Postman environment export
+func IsPostmanEnvironment(data []byte) bool {
+	var probe struct {
+		Values json.RawMessage `json:"values"`
+		Item   json.RawMessage `json:"item"`
+	}
+	return json.Unmarshal(data, &probe) == nil && probe.Values != nil && probe.Item == nil
+}
+
+// ParsePostmanCollection converts a Postman v2.1 collection into an apix collection.
+// Folders become nested collections in their original order. Collection variables are returned separately.
+func ParsePostmanCollection(data []byte) (*model.Collection, map[string]string, error) {
+	var source postmanCollection
+	if err := json.Unmarshal(data, &source); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse Postman collection: %w", err)
+	}
+
+	if source.Info.Schema != "" && !strings.Contains(source.Info.Schema, "v2.1") {
+		return nil, nil, fmt.Errorf("unsupported Postman schema %s (export as Collection v2.1)", source.Info.Schema)
+	}
+	if source.Item == nil {
+		return nil, nil, fmt.Errorf("failed to parse Postman collection: no items found")
+	}
+
+	collection := convertPostmanFolder(SanitizeName(source.Info.Name), source.Item, source.Auth)
+
+	variables := make(map[string]string)
+	for _, variable := range source.Variable {
+		if variable.active() {
+			variables[variable.Key] = variable.value()
+		}
+	}
+
+	return &collection, variables, nil
+}
+
+// ParsePostmanEnvironment converts a Postman environment export into an apix environment
+func ParsePostmanEnvironment(data []byte) (*model.Environment, error) {
+	var source postmanEnvironment
+	if err := json.Unmarshal(data, &source); err != nil {
+		return nil, fmt.Errorf("failed to parse Postman environment: %w", err)
+	}
+	if source.Name == "" {
+		return nil, fmt.Errorf("failed to parse Postman environment: missing name")
+	}
+
+	environment := &model.Environment{
+		Name:      SanitizeName(source.Name),
+		Variables: make(map[string]string),
+	}
+	for _, value := range source.Values {
+		if value.active() {
+			environment.Variables[value.Key] = value.value()
+		}
+	}
+	return environment, nil
+}
+
+func convertPostmanFolder(name string, items []postmanItem, auth *postmanAuth) model.Collection {
+	collection := model.Collection{
+		Name: name,
+		Auth: convertPostmanAuth(auth),
+	}
+
+	names := newNameSet()
+	for _, item := range items {
+		itemName := names.unique(SanitizeName(item.Name))
+		collection.Order = append(collection.Order, itemName)
+
+		if item.Request == nil {
+			collection.Folders = append(collection.Folders, convertPostmanFolder(itemName, item.Item, item.Auth))
+			continue
+		}
+
+		template := convertPostmanRequest(*item.Request)
+		template.Name = itemName
+		collection.Templates = append(collection.Templates, template)
+	}
+
+	return collection
+}
+
+// postmanPathVariable matches :name path segments
+var postmanPathVariable = regexp.MustCompile(`/:([A-Za-z_][A-Za-z0-9_]*)`)
+
+func convertPostmanRequest(request postmanRequest) model.Template {
+	template := model.Template{
+		Method:  strings.ToUpper(request.Method),
+		Headers: make(map[string]string),
+	}
+	if template.Method == "" {
+		template.Method = "GET"
+	}
+
+	for _, header := range request.Header {
+		if header.active() && header.Key != "" {
+			template.Headers[header.Key] = header.value()
+		}
+	}
+
+	// URL: keep the raw form (which already uses {{variables}}) without its query string
+	rawURL, rawQuery, _ := strings.Cut(request.URL.Raw, "?")
+	template.URL = rawURL
+
+	pathValues := make(map[string]string)
+	for _, variable := range request.URL.Variable {
+		pathValues[variable.Key] = variable.value()
+	}
+	template.URL = postmanPathVariable.ReplaceAllStringFunc(template.URL, func(match string) string {
+		name := match[2:]
+		if value := pathValues[name]; value != "" {
+			return "/" + value
+		}
+		return "/{{" + name + "}}"
+	})
+
+	template.QueryParams = make(map[string]string)
+	if request.URL.Query != nil {
+		for _, param := range request.URL.Query {
+			if param.active() && param.Key != "" {
+				template.QueryParams[param.Key] = param.value()
+			}
+		}
+	} else if rawQuery != "" {
+		for _, pair := range strings.Split(rawQuery, "&") {
+			key, value, _ := strings.Cut(pair, "=")
+			if key != "" {
+				template.QueryParams[key] = value
+			}
+		}
+	}
+
+	if request.Body != nil {
+		convertPostmanBody(*request.Body, &template)
+	}
+
+	if request.Auth != nil {
+		template.Auth = convertPostmanAuth(request.Auth)
+	}
+
+	if len(template.Headers) == 0 {
+		template.Headers = nil
+	}
+	if len(template.QueryParams) == 0 {
+		template.QueryParams = nil
+	}
+	return template
+}
+
+func convertPostmanBody(body postmanBody, template *model.Template) {
+	switch body.Mode {
+	case "raw":
+		if body.Raw == "" {
+			return
+		}
+		template.Body = body.Raw
+		if body.Options != nil && body.Options.Raw.Language == "json" && !hasKey(template.Headers, "Content-Type") {
+			template.Headers["Content-Type"] = "application/json"
+		}
+	case "urlencoded":
+		values := url.Values{}
+		for _, field := range body.URLEncoded {
+			if field.active() {
+				values.Add(field.Key, field.value())
+			}
+		}
+		template.Body = values.Encode()
+		if !hasKey(template.Headers, "Content-Type") {
+			template.Headers["Content-Type"] = "application/x-www-form-urlencoded"
+		}
+	case "formdata":
+		for _, field := range body.FormData {
+			if !field.active() {
+				continue
+			}
+			if field.Type == "file" {
+				if template.Files == nil {
+					template.Files = make(map[string]string)
+				}
+				template.Files[field.Key] = postmanFileSource(field.Src)
+				continue
+			}
+			if template.FormData == nil {
+				template.FormData = make(map[string]string)
+			}
+			template.FormData[field.Key] = field.value()
+		}
+	}
+}
+
+// postmanFileSource returns the path of a form-data file, which Postman stores as a string or a list
+func postmanFileSource(src any) string {
+	switch v := src.(type) {
+	case string:
+		return v
+	case []any:
+		if len(v) > 0 {
+			return fmt.Sprint(v[0])
+		}
+	}
+	return ""
+}
+
+// convertPostmanAuth maps bearer, basic and API key auth. "noauth" becomes auth type none so the
+// request does not inherit its folder's auth; other types are dropped.
+func convertPostmanAuth(auth *postmanAuth) *model.Auth {
+	if auth == nil {
+		return nil
+	}
+
+	switch auth.Type {
+	case "bearer":
+		return &model.Auth{Type: "bearer", Primary: postmanAuthValue(auth.Bearer, "token")}
+	case "basic":
+		return &model.Auth{
+			Type:      "basic",
+			Primary:   postmanAuthValue(auth.Basic, "username"),
+			Secondary: postmanAuthValue(auth.Basic, "password"),
+		}
+	case "apikey":
+		location := postmanAuthValue(auth.APIKey, "in")
+		if location != "query" {
+			location = ""
+		}
+		header := postmanAuthValue(auth.APIKey, "key")
+		if header == "" {
+			header = "X-API-Key"
+		}
+		return &model.Auth{Type: "apikey", Primary: header, Secondary: postmanAuthValue(auth.APIKey, "value"), Location: location}
+	case "noauth":
+		return &model.Auth{Type: "none"}
+	default:
+		return nil
+	}
+}
+
+func postmanAuthValue(values []postmanKeyValue, key string) string {
+	for _, value := range values {
+		if value.Key == key {
+			return value.value()
+		}
+	}
+	return ""
+}
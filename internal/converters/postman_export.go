@@ -0,0 +1,142 @@
+package converters
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+// ExportPostman renders templates as a Postman v2.1 collection
+func ExportPostman(name string, templates []model.Template) ([]byte, error) {
+	collection := postmanCollection{
+		Info: postmanInfo{Name: name, Schema: PostmanSchema},
+		Item: []postmanItem{},
+	}
+
+	for _, template := range templates {
+		folders, base := splitFolder(template.Name)
+
+		items := &collection.Item
+		for _, folder := range folders {
+			items = postmanFolder(items, folder)
+		}
+		*items = append(*items, postmanItem{Name: base, Request: toPostmanRequest(template)})
+	}
+
+	data, err := json.MarshalIndent(collection, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Postman collection: %w", err)
+	}
+	return data, nil
+}
+
+// postmanFolder returns the items of the named folder in items, adding the folder if needed
+func postmanFolder(items *[]postmanItem, name string) *[]postmanItem {
+	for i := range *items {
+		if (*items)[i].Request == nil && (*items)[i].Name == name {
+			return &(*items)[i].Item
+		}
+	}
+	*items = append(*items, postmanItem{Name: name, Item: []postmanItem{}})
+	return &(*items)[len(*items)-1].Item
+}
+
+func toPostmanRequest(template model.Template) *postmanRequest {
+	request := &postmanRequest{
+		Method: template.Method,
+		Header: []postmanKeyValue{},
+		URL:    postmanURL{Raw: template.URL},
+		Auth:   toPostmanAuth(template.Auth),
+	}
+
+	for _, key := range sortedKeys(template.Headers) {
+		request.Header = append(request.Header, postmanKeyValue{Key: key, Value: template.Headers[key]})
+	}
+
+	if len(template.QueryParams) > 0 {
+		var query []string
+		for _, key := range sortedKeys(template.QueryParams) {
+			value := template.QueryParams[key]
+			request.URL.Query = append(request.URL.Query, postmanKeyValue{Key: key, Value: value})
+			query = append(query, key+"="+value)
+		}
+		request.URL.Raw += "?" + strings.Join(query, "&")
+	}
+
+	body := templateBody(template)
+	switch body.mode {
+	case "raw":
+		request.Body = &postmanBody{Mode: "raw", Raw: body.text}
+		if strings.Contains(body.contentType, "json") {
+			request.Body.Options = &postmanBodyOpts{}
+			request.Body.Options.Raw.Language = "json"
+		}
+	case "urlencoded":
+		request.Body = &postmanBody{Mode: "urlencoded"}
+		for _, field := range formFields(body.fields) {
+			request.Body.URLEncoded = append(request.Body.URLEncoded, postmanKeyValue{Key: field[0], Value: field[1]})
+		}
+	case "formdata":
+		request.Body = &postmanBody{Mode: "formdata"}
+		for _, key := range sortedKeys(template.FormData) {
+			request.Body.FormData = append(request.Body.FormData, postmanKeyValue{Key: key, Value: template.FormData[key], Type: "text"})
+		}
+		for _, key := range sortedKeys(template.Files) {
+			request.Body.FormData = append(request.Body.FormData, postmanKeyValue{Key: key, Src: template.Files[key], Type: "file"})
+		}
+	}
+
+	return request
+}
+
+func toPostmanAuth(auth *model.Auth) *postmanAuth {
+	if auth == nil {
+		return nil
+	}
+
+	switch auth.Type {
+	case "bearer":
+		return &postmanAuth{Type: "bearer", Bearer: []postmanKeyValue{{Key: "token", Value: auth.Primary, Type: "string"}}}
+	case "basic":
+		return &postmanAuth{Type: "basic", Basic: []postmanKeyValue{
+			{Key: "username", Value: auth.Primary, Type: "string"},
+			{Key: "password", Value: auth.Secondary, Type: "string"},
+		}}
+	case "apikey":
+		key, value, location := auth.Primary, auth.Secondary, apiKeyLocation(auth)
+		if location == "cookie" {
+			// Postman sends API keys in a header or query parameter only
+			key, value, location = "Cookie", auth.Primary+"="+auth.Secondary, "header"
+		}
+		return &postmanAuth{Type: "apikey", APIKey: []postmanKeyValue{
+			{Key: "key", Value: key, Type: "string"},
+			{Key: "value", Value: value, Type: "string"},
+			{Key: "in", Value: location, Type: "string"},
+		}}
+	case "none":
+		return &postmanAuth{Type: "noauth"}
+	default:
+		return nil
+	}
+}
+
+// formFields flattens url-encoded fields into name/value pairs sorted by name
+func formFields(values url.Values) [][2]string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var fields [][2]string
+	for _, key := range keys {
+		for _, value := range values[key] {
+			fields = append(fields, [2]string{key, value})
+		}
+	}
+	return fields
+}
@@ -0,0 +1,225 @@
+package converters
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+func readTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParsePostmanCollection(t *testing.T) {
+	collection, variables, err := ParsePostmanCollection(readTestdata(t, "petstore.postman_collection.json"))
+	if err != nil {
+		t.Fatalf("ParsePostmanCollection() error = %v", err)
+	}
+
+	want := &model.Collection{
+		Name:  "Petstore API",
+		Order: []string{"Pets", "Owners - Admin", "Health", "Legacy OAuth"},
+		Auth:  &model.Auth{Type: "bearer", Primary: "{{token}}"},
+		Templates: []model.Template{
+			{
+				Name:        "Health",
+				Method:      "GET",
+				URL:         "https://status.example.com/health",
+				QueryParams: map[string]string{"verbose": "1"},
+			},
+			{
+				// oauth2 is not supported, so the request inherits the collection's bearer token
+				Name:   "Legacy OAuth",
+				Method: "GET",
+				URL:    "{{base_url}}/legacy",
+			},
+		},
+		Folders: []model.Collection{
+			{
+				Name:  "Pets",
+				Order: []string{"List pets", "Get pet", "Create pet", "Delete pet"},
+				Templates: []model.Template{
+					{
+						Name:        "List pets",
+						Method:      "GET",
+						URL:         "{{base_url}}/pets",
+						Headers:     map[string]string{"Accept": "application/json"},
+						QueryParams: map[string]string{"limit": "20"},
+					},
+					{
+						Name:   "Get pet",
+						Method: "GET",
+						URL:    "{{base_url}}/pets/{{petId}}",
+					},
+					{
+						Name:    "Create pet",
+						Method:  "POST",
+						URL:     "{{base_url}}/pets",
+						Headers: map[string]string{"Content-Type": "application/json"},
+						Body:    "{\n    \"name\": \"Rex\",\n    \"tag\": \"dog\"\n}",
+					},
+					{
+						Name:   "Delete pet",
+						Method: "DELETE",
+						URL:    "{{base_url}}/pets/42",
+						Auth:   &model.Auth{Type: "none"},
+					},
+				},
+			},
+			{
+				Name:  "Owners - Admin",
+				Order: []string{"Login", "Upload avatar", "Login-2"},
+				Auth:  &model.Auth{Type: "apikey", Primary: "api_key", Secondary: "{{api_key}}", Location: "query"},
+				Templates: []model.Template{
+					{
+						Name:    "Login",
+						Method:  "POST",
+						URL:     "{{base_url}}/login",
+						Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+						Body:    "password=secret&username=ada",
+					},
+					{
+						Name:     "Upload avatar",
+						Method:   "POST",
+						URL:      "{{base_url}}/owners/avatar",
+						FormData: map[string]string{"name": "Rex"},
+						Files:    map[string]string{"avatar": "/tmp/avatar.png"},
+					},
+					{
+						Name:        "Login-2",
+						Method:      "POST",
+						URL:         "{{base_url}}/login",
+						QueryParams: map[string]string{"mode": "basic"},
+						Auth:        &model.Auth{Type: "basic", Primary: "ada", Secondary: "secret"},
+					},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(collection, want) {
+		t.Errorf("ParsePostmanCollection() =\n%+v\nwant\n%+v", collection, want)
+	}
+
+	wantVariables := map[string]string{"base_url": "https://petstore.example.com/v1", "page_size": "20"}
+	if !reflect.DeepEqual(variables, wantVariables) {
+		t.Errorf("variables = %v, want %v", variables, wantVariables)
+	}
+}
+
+func TestParsePostmanCollectionErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "invalid JSON",
+			data: `{"info": `,
+			want: "failed to parse Postman collection",
+		},
+		{
+			name: "v2.0 schema",
+			data: `{"info": {"name": "old", "schema": "https://schema.getpostman.com/json/collection/v2.0.0/collection.json"}, "item": []}`,
+			want: "unsupported Postman schema",
+		},
+		{
+			name: "no items",
+			data: `{"info": {"name": "empty", "schema": "` + PostmanSchema + `"}}`,
+			want: "no items found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ParsePostmanCollection([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParsePostmanCollection() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePostmanEnvironment(t *testing.T) {
+	data := readTestdata(t, "petstore.postman_environment.json")
+	if !IsPostmanEnvironment(data) {
+		t.Error("IsPostmanEnvironment() = false for an environment export")
+	}
+	if IsPostmanEnvironment(readTestdata(t, "petstore.postman_collection.json")) {
+		t.Error("IsPostmanEnvironment() = true for a collection export")
+	}
+
+	environment, err := ParsePostmanEnvironment(data)
+	if err != nil {
+		t.Fatalf("ParsePostmanEnvironment() error = %v", err)
+	}
+	want := &model.Environment{
+		Name: "Petstore Staging",
+		Variables: map[string]string{
+			"base_url": "https://staging.petstore.example.com/v1",
+			"token":    "staging-token",
+		},
+	}
+	if !reflect.DeepEqual(environment, want) {
+		t.Errorf("ParsePostmanEnvironment() = %+v, want %+v", environment, want)
+	}
+
+	if _, err := ParsePostmanEnvironment([]byte(`{"values": []}`)); err == nil || !strings.Contains(err.Error(), "missing name") {
+		t.Errorf("ParsePostmanEnvironment() without a name error = %v, want missing name", err)
+	}
+}
+
+func TestExportPostman(t *testing.T) {
+	data, err := ExportPostman("petstore", exportTemplates())
+	if err != nil {
+		t.Fatalf("ExportPostman() error = %v", err)
+	}
+	assertGolden(t, "petstore.postman_export.golden.json", data)
+}
+
+func TestExportPostmanRoundTrip(t *testing.T) {
+	data, err := ExportPostman("petstore", exportTemplates())
+	if err != nil {
+		t.Fatalf("ExportPostman() error = %v", err)
+	}
+	collection, _, err := ParsePostmanCollection(data)
+	if err != nil {
+		t.Fatalf("ParsePostmanCollection() error = %v", err)
+	}
+
+	got := make(map[string]model.Template)
+	flattenCollection(*collection, "", got)
+
+	for _, template := range exportTemplates() {
+		want := template
+		if template.Name == "keys/cookie" {
+			// Postman has no cookie API keys, so the export sends a Cookie header instead
+			want.Auth = &model.Auth{Type: "apikey", Primary: "Cookie", Secondary: "session={{session}}"}
+		}
+		if !reflect.DeepEqual(got[template.Name], want) {
+			t.Errorf("%s after a round trip = %+v, want %+v", template.Name, got[template.Name], want)
+		}
+	}
+	if len(got) != len(exportTemplates()) {
+		t.Errorf("round trip returned %d templates, want %d", len(got), len(exportTemplates()))
+	}
+}
+
+// flattenCollection collects the templates of a collection by their folder-qualified names
+func flattenCollection(collection model.Collection, prefix string, templates map[string]model.Template) {
+	for _, template := range collection.Templates {
+		template.Name = prefix + template.Name
+		templates[template.Name] = template
+	}
+	for _, folder := range collection.Folders {
+		flattenCollection(folder, prefix+folder.Name+"/", templates)
+	}
+}
@@ -0,0 +1,423 @@
+package httpclient
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+// PassphraseEnv supplies the auth profile passphrase to scripts
+const PassphraseEnv = "APIX_PASSPHRASE"
+
+// ErrAuthProfilesLocked is returned when encrypted auth profiles are used before they are unlocked
+var ErrAuthProfilesLocked = fmt.Errorf("auth profiles are encrypted: set %s or unlock them in interactive mode", PassphraseEnv)
+
+// ErrWrongPassphrase is returned when a passphrase doesn't unlock the auth profiles
+var ErrWrongPassphrase = errors.New("wrong passphrase")
+
+// Auth profiles are encrypted with AES-256-GCM under a key derived from the passphrase with
+// scrypt. The key parameters and a check value live in auth-profiles/.encryption; each profile
+// file keeps its name, type, active flag and hosts readable and its credentials encrypted.
+const encryptionFile = ".encryption"
+
+// encryptionCheck is encrypted under the key to recognise a wrong passphrase
+const encryptionCheck = "apix auth profiles"
+
+// encryptionParams describe how the profile key is derived
+type encryptionParams struct {
+	KDF   string `json:"kdf"`
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	Salt  string `json:"salt"`
+	Check string `json:"check"`
+}
+
+// encryptedProfile is the file format of an encrypted auth profile. Its fields other than
+// Encrypted have the same names as in a plaintext profile, so either can be read into it
+// to find the active profile and its hosts without decrypting.
+type encryptedProfile struct {
+	Name      string   `json:"name"`
+	Type      string   `json:"type"`
+	Active    bool     `json:"active"`
+	Hosts     []string `json:"hosts,omitempty"`
+	Encrypted string   `json:"encrypted"`
+}
+
+// profileKey is the derived key once the profiles are unlocked
+var profileKey []byte
+
+func encryptionPath() string {
+	return filepath.Join(authProfilesDir(), encryptionFile)
+}
+
+// AuthProfilesEncrypted reports whether auth profiles are encrypted at rest
+func AuthProfilesEncrypted() bool {
+	_, err := os.Stat(encryptionPath())
+	return err == nil
+}
+
+// AuthProfilesLocked reports whether auth profiles are encrypted and not yet unlocked.
+// The passphrase in $APIX_PASSPHRASE is tried first.
+func AuthProfilesLocked() bool {
+	return unlockFromEnv() != nil
+}
+
+// UnlockAuthProfiles derives the profile key from passphrase
+func UnlockAuthProfiles(passphrase string) error {
+	params, err := readEncryptionParams()
+	if err != nil {
+		return err
+	}
+	if params == nil {
+		return nil
+	}
+
+	key, err := deriveProfileKey(passphrase, params)
+	if err != nil {
+		return err
+	}
+
+	check, err := decryptWithKey(key, params.Check, nil)
+	if err != nil || subtle.ConstantTimeCompare(check, []byte(encryptionCheck)) != 1 {
+		return ErrWrongPassphrase
+	}
+
+	profileKey = key
+	return nil
+}
+
+// isLockedError reports whether err means the profile key is unavailable, as opposed to a broken file
+func isLockedError(err error) bool {
+	return errors.Is(err, ErrAuthProfilesLocked) || errors.Is(err, ErrWrongPassphrase)
+}
+
+// unlockFromEnv makes sure the profile key is available, using $APIX_PASSPHRASE if needed
+func unlockFromEnv() error {
+	if profileKey != nil || !AuthProfilesEncrypted() {
+		return nil
+	}
+
+	passphrase := os.Getenv(PassphraseEnv)
+	if passphrase == "" {
+		return ErrAuthProfilesLocked
+	}
+	if err := UnlockAuthProfiles(passphrase); err != nil {
+		return fmt.Errorf("failed to unlock auth profiles with %s: %w", PassphraseEnv, err)
+	}
+	return nil
+}
+
+// EncryptAuthProfiles encrypts every auth profile under a new passphrase. Plaintext profiles
+// are migrated, and profiles that are already encrypted are re-encrypted, which rotates the passphrase.
+func EncryptAuthProfiles(passphrase string) (int, error) {
+	if passphrase == "" {
+		return 0, errors.New("passphrase must not be empty")
+	}
+
+	profiles, err := GetAuthProfiles()
+	if err != nil {
+		return 0, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return 0, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	params := &encryptionParams{KDF: "scrypt", N: 1 << 15, R: 8, P: 1, Salt: base64.StdEncoding.EncodeToString(salt)}
+
+	key, err := deriveProfileKey(passphrase, params)
+	if err != nil {
+		return 0, err
+	}
+	if params.Check, err = encryptWithKey(key, []byte(encryptionCheck), nil); err != nil {
+		return 0, err
+	}
+
+	data, err := json.MarshalIndent(params, "", "  ")
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal encryption settings: %w", err)
+	}
+
+	// The profiles are replaced first and the encryption settings last, so the key they are
+	// read with changes only once every profile has been re-encrypted
+	files := make([]fileWrite, 0, len(profiles)+1)
+	for _, profile := range profiles {
+		path, err := authProfilePath(profile.Name)
+		if err != nil {
+			return 0, err
+		}
+		encoded, err := encodeProfile(profile, key)
+		if err != nil {
+			return 0, err
+		}
+		files = append(files, fileWrite{path: path, data: encoded})
+	}
+	files = append(files, fileWrite{path: encryptionPath(), data: data})
+
+	if err := os.MkdirAll(authProfilesDir(), 0755); err != nil {
+		return 0, fmt.Errorf("failed to create auth-profiles directory: %w", err)
+	}
+	if err := replaceFiles(files); err != nil {
+		return 0, err
+	}
+
+	profileKey = key
+	return len(profiles), nil
+}
+
+// DecryptAuthProfiles stores every auth profile as plaintext again and turns encryption off
+func DecryptAuthProfiles() (int, error) {
+	profiles, err := GetAuthProfiles()
+	if err != nil {
+		return 0, err
+	}
+
+	files := make([]fileWrite, 0, len(profiles))
+	for _, profile := range profiles {
+		path, err := authProfilePath(profile.Name)
+		if err != nil {
+			return 0, err
+		}
+		data, err := encodeProfile(profile, nil)
+		if err != nil {
+			return 0, err
+		}
+		files = append(files, fileWrite{path: path, data: data})
+	}
+	if err := replaceFiles(files); err != nil {
+		return 0, err
+	}
+
+	if err := os.Remove(encryptionPath()); err != nil && !os.IsNotExist(err) {
+		return 0, fmt.Errorf("failed to remove encryption settings: %w", err)
+	}
+	profileKey = nil
+	return len(profiles), nil
+}
+
+// encodeProfile returns the file contents of a profile, encrypting it when key is set
+func encodeProfile(profile *model.AuthProfile, key []byte) ([]byte, error) {
+	data, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal auth profile: %w", err)
+	}
+	if key == nil {
+		return data, nil
+	}
+
+	encrypted, err := encryptWithKey(key, data, []byte(profile.Name))
+	if err != nil {
+		return nil, err
+	}
+	data, err = json.MarshalIndent(encryptedProfile{
+		Name:      profile.Name,
+		Type:      profile.Type,
+		Active:    profile.Active,
+		Hosts:     profile.Hosts,
+		Encrypted: encrypted,
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal auth profile: %w", err)
+	}
+	return data, nil
+}
+
+// decodeProfile parses the file contents of a profile, decrypting it if needed
+func decodeProfile(data []byte) (*model.AuthProfile, error) {
+	var envelope encryptedProfile
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, err
+	}
+
+	if envelope.Encrypted != "" {
+		if err := unlockFromEnv(); err != nil {
+			return nil, err
+		}
+		var err error
+		if data, err = decryptWithKey(profileKey, envelope.Encrypted, []byte(envelope.Name)); err != nil {
+			return nil, fmt.Errorf("failed to decrypt: %w", err)
+		}
+	}
+
+	var profile model.AuthProfile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, err
+	}
+	profile.Active = envelope.Active
+	return &profile, nil
+}
+
+func readEncryptionParams() (*encryptionParams, error) {
+	data, err := os.ReadFile(encryptionPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encryption settings: %w", err)
+	}
+
+	var params encryptionParams
+	if err := json.Unmarshal(data, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse encryption settings: %w", err)
+	}
+	if params.KDF != "scrypt" {
+		return nil, fmt.Errorf("unsupported key derivation function %q", params.KDF)
+	}
+	return &params, nil
+}
+
+func deriveProfileKey(passphrase string, params *encryptionParams) ([]byte, error) {
+	salt, err := base64.StdEncoding.DecodeString(params.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid salt in encryption settings: %w", err)
+	}
+
+	key, err := scrypt.Key([]byte(passphrase), salt, params.N, params.R, params.P, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return key, nil
+}
+
+// encryptWithKey seals plaintext with AES-GCM, returning base64 of the nonce followed by the ciphertext
+func encryptWithKey(key, plaintext, additionalData []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, plaintext, additionalData)), nil
+}
+
+func decryptWithKey(key []byte, encoded string, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(data) < gcm.NonceSize() {
+		return nil, errors.New("malformed ciphertext")
+	}
+	return gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], additionalData)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// writeFileAtomic replaces path with data through a temporary file, so a profile is never left half-written
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := writeTempFile(path, data)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// writeTempFile writes data to a new temporary file next to path and returns its name
+func writeTempFile(path string, data []byte) (string, error) {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write %s: %w", filepath.Base(path), err)
+	}
+	return file.Name(), nil
+}
+
+// fileWrite is one file replaced by replaceFiles
+type fileWrite struct {
+	path string
+	data []byte
+}
+
+// replaceFiles replaces several files as a unit: every file is written to a temporary file
+// before any is renamed into place, in order, and if a rename fails the files already
+// replaced get their previous contents back
+func replaceFiles(files []fileWrite) error {
+	temps := make([]string, len(files))
+	removeTemps := func() {
+		for _, tmp := range temps {
+			if tmp != "" {
+				os.Remove(tmp)
+			}
+		}
+	}
+
+	previous := make([][]byte, len(files))
+	for i, file := range files {
+		data, err := os.ReadFile(file.path)
+		if err != nil && !os.IsNotExist(err) {
+			removeTemps()
+			return fmt.Errorf("failed to read %s: %w", filepath.Base(file.path), err)
+		}
+		previous[i] = data
+
+		if temps[i], err = writeTempFile(file.path, file.data); err != nil {
+			removeTemps()
+			return err
+		}
+	}
+
+	for i, file := range files {
+		if err := os.Rename(temps[i], file.path); err != nil {
+			removeTemps()
+			errs := []error{fmt.Errorf("failed to write %s: %w", filepath.Base(file.path), err)}
+			for j := i - 1; j >= 0; j-- {
+				errs = append(errs, restoreFile(files[j].path, previous[j]))
+			}
+			return errors.Join(errs...)
+		}
+		temps[i] = ""
+	}
+	return nil
+}
+
+// restoreFile puts back the previous contents of path, removing it if it did not exist
+func restoreFile(path string, previous []byte) error {
+	if previous == nil {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to roll back %s: %w", filepath.Base(path), err)
+		}
+		return nil
+	}
+	if err := writeFileAtomic(path, previous); err != nil {
+		return fmt.Errorf("failed to roll back %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
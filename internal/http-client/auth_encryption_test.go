@@ -0,0 +1,154 @@
+package httpclient
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+// useTestProfileKey forgets the unlocked profile key when the test ends
+func useTestProfileKey(t *testing.T) {
+	t.Helper()
+	oldKey := profileKey
+	t.Cleanup(func() { profileKey = oldKey })
+	profileKey = nil
+	t.Setenv(PassphraseEnv, "")
+}
+
+func TestEncryptAuthProfiles(t *testing.T) {
+	useTestConfig(t)
+	useTestProfileKey(t)
+
+	for _, profile := range []*model.AuthProfile{
+		{Name: "api", Type: "bearer", Token: "PLAINTOKEN", Active: true},
+		{Name: "admin", Type: "basic", Username: "root", Password: "PLAINPASSWORD"},
+	} {
+		if err := SaveAuthProfile(profile); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	count, err := EncryptAuthProfiles("first passphrase")
+	if err != nil || count != 2 {
+		t.Fatalf("EncryptAuthProfiles() = %d, %v", count, err)
+	}
+	assertNoPlaintext(t, "PLAINTOKEN", "PLAINPASSWORD")
+
+	// Rotating the passphrase re-encrypts the profiles under the new key only
+	if _, err := EncryptAuthProfiles("second passphrase"); err != nil {
+		t.Fatal(err)
+	}
+	profileKey = nil
+	if err := UnlockAuthProfiles("first passphrase"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("old passphrase: err = %v, want ErrWrongPassphrase", err)
+	}
+	if _, err := GetAuthProfiles(); !errors.Is(err, ErrAuthProfilesLocked) {
+		t.Errorf("locked profiles: err = %v, want ErrAuthProfilesLocked", err)
+	}
+	if err := UnlockAuthProfiles("second passphrase"); err != nil {
+		t.Fatal(err)
+	}
+	profile, err := GetAuthProfile("api")
+	if err != nil || profile.Token != "PLAINTOKEN" || !profile.Active {
+		t.Fatalf("GetAuthProfile(api) = %+v, %v", profile, err)
+	}
+
+	if count, err := DecryptAuthProfiles(); err != nil || count != 2 {
+		t.Fatalf("DecryptAuthProfiles() = %d, %v", count, err)
+	}
+	if AuthProfilesEncrypted() {
+		t.Error("profiles are still marked as encrypted")
+	}
+	profileKey = nil
+	if profile, err := GetAuthProfile("admin"); err != nil || profile.Password != "PLAINPASSWORD" {
+		t.Errorf("GetAuthProfile(admin) = %+v, %v", profile, err)
+	}
+	assertNoTempFiles(t, authProfilesDir())
+}
+
+func assertNoPlaintext(t *testing.T, secrets ...string) {
+	t.Helper()
+	entries, err := os.ReadDir(authProfilesDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(authProfilesDir(), entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, secret := range secrets {
+			if strings.Contains(string(data), secret) {
+				t.Errorf("%s contains %s in plaintext", entry.Name(), secret)
+			}
+		}
+	}
+}
+
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			t.Errorf("temporary file %s was left behind", entry.Name())
+		}
+	}
+}
+
+func TestReplaceFilesRollsBack(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.json")
+	created := filepath.Join(dir, "created.json")
+	blocked := filepath.Join(dir, "blocked.json")
+
+	writeTestFile(t, existing, "old")
+	// A non-empty directory can't be replaced by a rename
+	writeTestFile(t, filepath.Join(blocked, "file"), "")
+
+	err := replaceFiles([]fileWrite{
+		{path: existing, data: []byte("new")},
+		{path: created, data: []byte("new")},
+		{path: blocked, data: []byte("new")},
+	})
+	if err == nil {
+		t.Fatal("expected replacing a directory to fail")
+	}
+
+	if data, err := os.ReadFile(existing); err != nil || string(data) != "old" {
+		t.Errorf("existing.json = %q, %v, want the old contents back", data, err)
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Errorf("created.json should have been removed again, stat err = %v", err)
+	}
+	assertNoTempFiles(t, dir)
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "profile.json")
+
+	for _, content := range []string{"first", "second"} {
+		if err := writeFileAtomic(path, []byte(content)); err != nil {
+			t.Fatal(err)
+		}
+		if data, _ := os.ReadFile(path); string(data) != content {
+			t.Errorf("profile.json = %q, want %q", data, content)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	assertNoTempFiles(t, dir)
+}
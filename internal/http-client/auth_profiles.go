@@ -0,0 +1,320 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+// authProfilesDir returns the directory holding one JSON file per auth profile
+func authProfilesDir() string {
+	return filepath.Join(ConfigPath, "auth-profiles")
+}
+
+// GetAuthProfiles loads every auth profile. Profiles that can't be read are reported
+// in the returned error alongside the ones that could.
+func GetAuthProfiles() ([]*model.AuthProfile, error) {
+	entries, err := os.ReadDir(authProfilesDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read auth-profiles directory: %w", err)
+	}
+
+	var profiles []*model.AuthProfile
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		profile, err := readAuthProfile(filepath.Join(authProfilesDir(), entry.Name()))
+		if isLockedError(err) {
+			return nil, err
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, errors.Join(errs...)
+}
+
+// authProfilePath returns the file of the auth profile with the given name. Names that
+// would put the file outside the auth-profiles directory are rejected.
+func authProfilePath(name string) (string, error) {
+	if err := checkFileName("auth profile", name); err != nil {
+		return "", err
+	}
+	return filepath.Join(authProfilesDir(), name+".json"), nil
+}
+
+// GetAuthProfile loads the auth profile with the given name
+func GetAuthProfile(name string) (*model.AuthProfile, error) {
+	path, err := authProfilePath(name)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := readAuthProfile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("auth profile '%s' not found", name)
+	}
+	return profile, err
+}
+
+func readAuthProfile(path string) (*model.AuthProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read auth profile file %s: %w", filepath.Base(path), err)
+	}
+
+	profile, err := decodeProfile(data)
+	if isLockedError(err) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse auth profile file %s: %w", filepath.Base(path), err)
+	}
+	return profile, nil
+}
+
+// SaveAuthProfile saves a single auth profile to a JSON file, encrypted when encryption is on
+func SaveAuthProfile(profile *model.AuthProfile) error {
+	path, err := authProfilePath(profile.Name)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(authProfilesDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create auth-profiles directory: %w", err)
+	}
+
+	if err := unlockFromEnv(); err != nil {
+		return err
+	}
+	data, err := encodeProfile(profile, profileKey)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, data)
+}
+
+// DeleteAuthProfile deletes the JSON file for an auth profile
+func DeleteAuthProfile(name string) error {
+	path, err := authProfilePath(name)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete auth profile file: %w", err)
+	}
+	return nil
+}
+
+// ProfileAuth returns the credentials of an auth profile as request auth
+func ProfileAuth(profile *model.AuthProfile) (*model.Auth, error) {
+	if expiry := TokenExpiry(profile); expiry != nil && time.Now().After(*expiry) {
+		return nil, fmt.Errorf("the token of auth profile '%s' expired at %s; update the profile or give it a login template",
+			profile.Name, expiry.Local().Format("2006-01-02 15:04:05"))
+	}
+
+	switch profile.Type {
+	case "bearer", "oauth":
+		if profile.Token == "" {
+			return nil, fmt.Errorf("token is empty in auth profile '%s'", profile.Name)
+		}
+		return &model.Auth{Type: "bearer", Primary: profile.Token, Profile: profile.Name}, nil
+	case "apikey":
+		if profile.APIKey == "" {
+			return nil, fmt.Errorf("API key is empty in auth profile '%s'", profile.Name)
+		}
+		name := profile.Header
+		if name == "" {
+			name = DefaultAPIKeyName(profile.Location)
+		}
+		return &model.Auth{Type: "apikey", Primary: name, Secondary: profile.APIKey, Location: profile.Location, Profile: profile.Name}, nil
+	case "basic":
+		if profile.Username == "" || profile.Password == "" {
+			return nil, fmt.Errorf("username or password is empty in auth profile '%s'", profile.Name)
+		}
+		return &model.Auth{Type: "basic", Primary: profile.Username, Secondary: profile.Password, Profile: profile.Name}, nil
+	case "digest":
+		if profile.Username == "" || profile.Password == "" {
+			return nil, fmt.Errorf("username or password is empty in auth profile '%s'", profile.Name)
+		}
+		return &model.Auth{Type: "digest", Primary: profile.Username, Secondary: profile.Password, Profile: profile.Name}, nil
+	case "aws":
+		auth := &model.Auth{Type: "aws", Primary: profile.AccessKey, Secondary: profile.SecretKey, Profile: profile.Name, AWS: profile.AWS}
+		if err := ValidateAWSSigning(auth); err != nil {
+			return nil, fmt.Errorf("auth profile '%s': %w", profile.Name, err)
+		}
+		return auth, nil
+	case "hmac":
+		if profile.SecretKey == "" {
+			return nil, fmt.Errorf("HMAC secret is empty in auth profile '%s'", profile.Name)
+		}
+		if err := ValidateHMACSigning(profile.HMAC); err != nil {
+			return nil, fmt.Errorf("auth profile '%s': %w", profile.Name, err)
+		}
+		return &model.Auth{Type: "hmac", Primary: profile.AccessKey, Secondary: profile.SecretKey, Profile: profile.Name, HMAC: profile.HMAC}, nil
+	default:
+		return nil, fmt.Errorf("authentication type '%s' in auth profile '%s' is not supported", profile.Type, profile.Name)
+	}
+}
+
+// DefaultAPIKeyName returns the name an API key is sent under when none is given
+func DefaultAPIKeyName(location string) string {
+	if location == "" || location == "header" {
+		return "X-API-Key"
+	}
+	return "api_key"
+}
+
+// ProfileReference returns auth that refers to a profile by name, leaving its credentials
+// to be loaded when the request is sent
+func ProfileReference(profile *model.AuthProfile) *model.Auth {
+	authType := profile.Type
+	if authType == "oauth" {
+		authType = "bearer"
+	}
+	return &model.Auth{Type: authType, Profile: profile.Name}
+}
+
+// ProfileAllowsHost reports whether a profile scoped to hosts may be sent to rawURL. Patterns
+// match the host name, or the host and port when they include one, and may contain * wildcards.
+func ProfileAllowsHost(hosts []string, rawURL string) bool {
+	if len(hosts) == 0 {
+		return true
+	}
+
+	parsed := parseRequestURL(rawURL)
+	if parsed == nil {
+		return false
+	}
+
+	for _, pattern := range hosts {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		host := strings.ToLower(parsed.Hostname())
+		if strings.Contains(pattern, ":") {
+			host = strings.ToLower(parsed.Host)
+		}
+		if matched, err := path.Match(pattern, host); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRequestURL parses a request URL, which may leave out the scheme, returning nil if it has no host
+func parseRequestURL(rawURL string) *url.URL {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return nil
+	}
+	return parsed
+}
+
+// GetActiveAuthProfileName returns the name of the active auth profile, or "" if there is none.
+// Encrypted profiles don't need to be unlocked.
+func GetActiveAuthProfileName() (string, error) {
+	active, err := activeProfileSummary()
+	if err != nil || active == nil {
+		return "", err
+	}
+	return active.Name, nil
+}
+
+// activeProfileSummary reads the readable fields of the active profile
+func activeProfileSummary() (*encryptedProfile, error) {
+	entries, err := os.ReadDir(authProfilesDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read auth-profiles directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(authProfilesDir(), entry.Name()))
+		if err != nil {
+			continue
+		}
+		var summary encryptedProfile
+		if err := json.Unmarshal(data, &summary); err == nil && summary.Active {
+			return &summary, nil
+		}
+	}
+	return nil, nil
+}
+
+// defaultAuth returns auth referring to the active profile, for requests to rawURL that have
+// no auth of their own. It returns nil when there is no active profile, the profile isn't
+// scoped to the host, or the request sets its own Authorization header.
+func defaultAuth(rawURL string, headers map[string]string) (*model.Auth, error) {
+	active, err := activeProfileSummary()
+	if err != nil || active == nil {
+		return nil, err
+	}
+	if !ProfileAllowsHost(active.Hosts, rawURL) {
+		return nil, nil
+	}
+
+	for key := range headers {
+		if strings.EqualFold(key, "Authorization") {
+			return nil, nil
+		}
+	}
+	return ProfileReference(&model.AuthProfile{Name: active.Name, Type: active.Type}), nil
+}
+
+// resolveProfileAuth fills in the credentials of auth that refers to a profile, refusing
+// to send them to a host outside the profile's scope. The profile's hosts are returned
+// so that redirects can be held to the same scope.
+func resolveProfileAuth(auth *model.Auth, rawURL string) (*model.Auth, []string, error) {
+	if auth == nil || auth.Profile == "" || auth.Type == "none" {
+		return auth, nil, nil
+	}
+
+	profile, err := GetAuthProfile(auth.Profile)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ProfileAllowsHost(profile.Hosts, rawURL) {
+		host := rawURL
+		if parsed := parseRequestURL(rawURL); parsed != nil {
+			host = parsed.Host
+		}
+		return nil, nil, fmt.Errorf("auth profile '%s' is limited to %s and isn't sent to %s",
+			profile.Name, strings.Join(profile.Hosts, ", "), host)
+	}
+	if profile.Type == "oauth" {
+		err = EnsureOAuthToken(profile)
+	} else {
+		err = checkTokenExpiry(profile)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resolved, err := ProfileAuth(profile)
+	return resolved, profile.Hosts, err
+}
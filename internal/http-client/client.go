@@ -3,11 +3,14 @@ package httpclient
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -16,20 +19,76 @@ import (
 	"github.com/Esa824/apix/internal/model"
 )
 
-var ConfigPath = ".config/"
-
 type Client struct {
 	resty *resty.Client
+	// timeout bounds a whole request, retries included
+	timeout time.Duration
 }
 
+// NewClient creates a client configured from the current Settings
 func NewClient(timeout time.Duration) *Client {
+	behavior := Settings.Behavior
+	network := Settings.Network
+
+	userAgent := network.UserAgent
+	if userAgent == "" {
+		userAgent = defaultUserAgent
+	}
+
+	retryDelay := time.Duration(behavior.RetryDelay) * time.Second
 	client := resty.New().
 		SetTimeout(timeout).
-		SetHeader("User-Agent", "GoRestyClient/1.0")
+		SetHeader("User-Agent", userAgent).
+		SetRetryCount(behavior.MaxRetries).
+		SetRetryWaitTime(retryDelay).
+		SetRetryMaxWaitTime(retryDelay).
+		AddRetryCondition(retryIdempotent).
+		SetLogger(silentLogger{})
+
+	// Signs requests that use "aws" or "hmac" auth
+	client.SetPreRequestHook(signRequest)
+
+	if behavior.FollowRedirects {
+		client.SetRedirectPolicy(resty.FlexibleRedirectPolicy(behavior.MaxRedirects), stripCredentialsOnRedirect)
+	} else {
+		client.SetRedirectPolicy(resty.NoRedirectPolicy())
+	}
+
+	if !behavior.ValidateSSL {
+		client.SetTLSClientConfig(&tls.Config{InsecureSkipVerify: true})
+	}
+
+	if network.ProxyEnabled && network.ProxyURL != "" {
+		client.SetProxy(network.ProxyURL)
+	}
 
-	return &Client{resty: client}
+	if transport, err := client.Transport(); err == nil {
+		transport.DisableCompression = !network.CompressionEnabled
+	}
+
+	return &Client{resty: client, timeout: timeout}
+}
+
+// retryIdempotent retries failed requests only when repeating them is safe,
+// so a POST or PATCH that reached the server is never sent twice
+func retryIdempotent(response *resty.Response, err error) bool {
+	if err == nil || response == nil || response.Request == nil {
+		return false
+	}
+	switch response.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
 }
 
+// silentLogger discards resty's log output; errors are returned to the caller instead
+type silentLogger struct{}
+
+func (silentLogger) Errorf(format string, v ...any) {}
+func (silentLogger) Warnf(format string, v ...any)  {}
+func (silentLogger) Debugf(format string, v ...any) {}
+
 type RequestOptions struct {
 	Id          int
 	Method      string
@@ -37,6 +96,7 @@ type RequestOptions struct {
 	Headers     map[string]string
 	QueryParams map[string]string
 	Files       map[string]string
+	FormData    map[string]string
 	Body        any
 	Cookies     map[string]string
 	Auth        *model.Auth
@@ -46,27 +106,56 @@ type RequestOptions struct {
 	Name        string
 }
 
+// Do sends a request, substituting {{variables}} from the active environment.
+// History and templates keep the unresolved placeholders.
 func (c *Client) Do(opts RequestOptions, saveToHistory bool) (*resty.Response, error) {
-	req := c.resty.R()
+	resolved, err := ResolveVariables(opts)
+	if err != nil {
+		return nil, err
+	}
+	if ContainsRedacted(resolved) {
+		return nil, fmt.Errorf("%w: replace %q with the real values or {{variables}} before sending it", ErrRedactedValues, Settings.Redaction.Marker)
+	}
+	if resolved.Auth == nil {
+		// Requests without auth of their own use the active profile
+		if resolved.Auth, err = defaultAuth(resolved.URL, resolved.Headers); err != nil {
+			return nil, err
+		}
+	}
+	var profileHosts []string
+	if resolved.Auth, profileHosts, err = resolveProfileAuth(resolved.Auth, resolved.URL); err != nil {
+		return nil, err
+	}
 
-	if opts.Context != nil {
-		req = req.SetContext(opts.Context)
+	if resolved.Context == nil {
+		resolved.Context = context.Background()
+	}
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		resolved.Context, cancel = context.WithTimeout(resolved.Context, c.timeout)
+		defer cancel()
 	}
 
-	if opts.Headers != nil {
-		req = req.SetHeaders(opts.Headers)
+	req := c.resty.R().SetContext(resolved.Context)
+
+	if resolved.Headers != nil {
+		req = req.SetHeaders(resolved.Headers)
+	}
+
+	if resolved.QueryParams != nil {
+		req = req.SetQueryParams(resolved.QueryParams)
 	}
 
-	if opts.QueryParams != nil {
-		req = req.SetQueryParams(opts.QueryParams)
+	if resolved.Files != nil {
+		req = req.SetFiles(resolved.Files)
 	}
 
-	if opts.Files != nil {
-		req = req.SetFiles(opts.Files)
+	if resolved.FormData != nil {
+		req = req.SetMultipartFormData(resolved.FormData)
 	}
 
-	if opts.Cookies != nil {
-		for k, v := range opts.Cookies {
+	if resolved.Cookies != nil {
+		for k, v := range resolved.Cookies {
 			req = req.SetCookie(&http.Cookie{
 				Name:  k,
 				Value: v,
@@ -74,26 +163,57 @@ func (c *Client) Do(opts RequestOptions, saveToHistory bool) (*resty.Response, e
 		}
 	}
 
-	if opts.Body != nil {
-		req = req.SetBody(opts.Body)
+	if resolved.Body != nil {
+		req = req.SetBody(resolved.Body)
 	}
 
-	if opts.Auth != nil {
-		switch opts.Auth.Type {
+	if resolved.Auth != nil {
+		switch resolved.Auth.Type {
 		case "bearer":
-			req = req.SetAuthToken(opts.Auth.Primary)
+			req = req.SetAuthToken(resolved.Auth.Primary)
 		case "apikey":
-			req = req.SetHeader(opts.Auth.Primary, opts.Auth.Secondary)
+			switch resolved.Auth.Location {
+			case "", "header":
+				req = req.SetHeader(resolved.Auth.Primary, resolved.Auth.Secondary)
+			case "query":
+				req = req.SetQueryParam(resolved.Auth.Primary, resolved.Auth.Secondary)
+			case "cookie":
+				req = req.SetCookie(&http.Cookie{Name: resolved.Auth.Primary, Value: resolved.Auth.Secondary})
+			default:
+				return nil, fmt.Errorf("unsupported API key location '%s': use header, query or cookie", resolved.Auth.Location)
+			}
 		case "basic":
-			req = req.SetBasicAuth(opts.Auth.Primary, opts.Auth.Secondary)
+			req = req.SetBasicAuth(resolved.Auth.Primary, resolved.Auth.Secondary)
+		case "digest":
+			req = req.SetDigestAuth(resolved.Auth.Primary, resolved.Auth.Secondary)
+		case "aws", "hmac":
+			ctx, err := withSigner(resolved.Context, resolved.Auth)
+			if err != nil {
+				return nil, err
+			}
+			req = req.SetContext(ctx)
 		}
 
+		req = req.SetContext(withCredentials(req.Context(), resolved.Auth, profileHosts))
 	}
 
-	response, err := req.Execute(opts.Method, opts.URL)
+	// Remembers why the last attempt failed, for when the timeout cuts the retries short
+	var attemptErr error
+	req.AddRetryCondition(func(_ *resty.Response, err error) bool {
+		attemptErr = err
+		return false
+	})
+
+	response, err := req.Execute(resolved.Method, resolved.URL)
+	if errors.Is(err, context.DeadlineExceeded) && attemptErr != nil && !errors.Is(attemptErr, context.DeadlineExceeded) {
+		err = fmt.Errorf("%w after %s, last attempt failed: %w", context.DeadlineExceeded, c.timeout, attemptErr)
+	}
+	if err != nil && saveToHistory && Settings.Behavior.SaveFailedRequests {
+		UpdateHistory(NewHistoryEntry(opts, nil, err))
+	}
 	if err == nil {
 		if saveToHistory {
-			UpdateHistory(opts)
+			UpdateHistory(NewHistoryEntry(opts, response, nil))
 		}
 
 		if opts.IsTemplate {
@@ -104,6 +224,7 @@ func (c *Client) Do(opts RequestOptions, saveToHistory bool) (*resty.Response, e
 				Headers:     opts.Headers,
 				QueryParams: opts.QueryParams,
 				Files:       opts.Files,
+				FormData:    opts.FormData,
 				Auth:        opts.Auth,
 				Body:        opts.Body,
 			})
@@ -150,6 +271,24 @@ func (c *Client) Patch(url string, headers map[string]string, body any) (*resty.
 	}, true)
 }
 
+func (c *Client) Head(url string, headers map[string]string, query map[string]string) (*resty.Response, error) {
+	return c.Do(RequestOptions{
+		Method:      "HEAD",
+		URL:         url,
+		Headers:     headers,
+		QueryParams: query,
+	}, true)
+}
+
+func (c *Client) Options(url string, headers map[string]string, query map[string]string) (*resty.Response, error) {
+	return c.Do(RequestOptions{
+		Method:      "OPTIONS",
+		URL:         url,
+		Headers:     headers,
+		QueryParams: query,
+	}, true)
+}
+
 func (c *Client) Delete(url string, headers map[string]string, body any) (*resty.Response, error) {
 	return c.Do(RequestOptions{
 		Method:  "DELETE",
@@ -159,74 +298,6 @@ func (c *Client) Delete(url string, headers map[string]string, body any) (*resty
 	}, true)
 }
 
-// GetHistory reads and returns all request history from the history file
-func GetHistory() ([]RequestOptions, error) {
-	filepath := filepath.Join(ConfigPath, "history")
-
-	// Check if file exists
-	if _, err := os.Stat(filepath); os.IsNotExist(err) {
-		// File doesn't exist, return empty array
-		return []RequestOptions{}, nil
-	}
-
-	// Read file contents
-	data, err := os.ReadFile(filepath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read history file: %w", err)
-	}
-
-	// Handle empty file
-	if len(data) == 0 {
-		return []RequestOptions{}, nil
-	}
-
-	// Unmarshal into array
-	var history []RequestOptions
-	err = json.Unmarshal(data, &history)
-	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal history data: %w", err)
-	}
-
-	return history, nil
-}
-
-// UpdateHistory appends a new request to the history and saves it
-func UpdateHistory(request RequestOptions) error {
-	// Get existing history
-	history, err := GetHistory()
-	if err != nil {
-		return fmt.Errorf("failed to get existing history: %w", err)
-	}
-
-	// Append new request to history
-	history = append(history, request)
-
-	if len(history) > 0 {
-		history[len(history)-1].Id = len(history) - 1
-	}
-
-	// Marshal updated history
-	data, err := json.MarshalIndent(history, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal history: %w", err)
-	}
-
-	// Write to file
-	filepath := filepath.Join(ConfigPath, "history")
-	err = os.WriteFile(filepath, data, 0600)
-	if err != nil {
-		return fmt.Errorf("failed to write history file: %w", err)
-	}
-
-	return nil
-}
-
-// DeleteHistory deletes the history file
-func DeleteHistory() error {
-	filepath := filepath.Join(ConfigPath, "history")
-	return fmt.Errorf("failed to delete history: %w", os.Remove(filepath))
-}
-
 // initTemplatesDir ensures the templates directory exists
 func initTemplatesDir() error {
 	templatesDir := filepath.Join(ConfigPath, "templates")
@@ -235,6 +306,9 @@ func initTemplatesDir() error {
 
 // saveTemplate saves a single template to a JSON file
 func saveTemplate(template *model.Template) error {
+	if err := checkFileName("template", template.Name); err != nil {
+		return err
+	}
 	if err := initTemplatesDir(); err != nil {
 		return fmt.Errorf("failed to create templates directory: %w", err)
 	}
@@ -243,7 +317,7 @@ func saveTemplate(template *model.Template) error {
 	filename := fmt.Sprintf("%s.json", template.Name)
 	filepath := filepath.Join(templatesDir, filename)
 
-	data, err := json.MarshalIndent(template, "", "  ")
+	data, err := json.MarshalIndent(templateForSave(*template), "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal template: %w", err)
 	}
@@ -305,7 +379,7 @@ func loadTemplates() (map[string]*model.Template, error) {
 	return templates, nil
 }
 
-// GetTemplates returns all saved templates as a slice (maintains compatibility with existing API)
+// GetTemplates returns all saved templates sorted by name
 func GetTemplates() ([]model.Template, error) {
 	templatesMap, err := loadTemplates()
 	if err != nil {
@@ -317,6 +391,9 @@ func GetTemplates() ([]model.Template, error) {
 		templates = append(templates, *template)
 	}
 
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
 	return templates, nil
 }
 
@@ -338,14 +415,16 @@ func UpdateTemplate(updated model.Template, oldName string) error {
 		return fmt.Errorf("template with name '%s' not found", oldName)
 	}
 
-	// If the name changed, delete the old file first
+	// Write the new file before deleting the old one, so a failed rename keeps the template
+	if err := saveTemplate(&updated); err != nil {
+		return err
+	}
 	if updated.Name != oldName {
 		if err := deleteTemplateFile(oldName); err != nil {
 			return fmt.Errorf("failed to delete old template file: %w", err)
 		}
 	}
-
-	return saveTemplate(&updated)
+	return nil
 }
 
 // DeleteTemplate removes a template by name
@@ -402,3 +481,19 @@ func GetTemplateByName(name string) (*model.Template, error) {
 
 	return nil, fmt.Errorf("template with name '%s' not found", name)
 }
+
+// TemplateRequestOptions builds the request options for executing a template
+func TemplateRequestOptions(template model.Template) RequestOptions {
+	return RequestOptions{
+		Method:      template.Method,
+		URL:         template.URL,
+		Headers:     template.Headers,
+		QueryParams: template.QueryParams,
+		Files:       template.Files,
+		FormData:    template.FormData,
+		Auth:        template.Auth,
+		Body:        template.Body,
+		Time:        time.Now(),
+		Name:        template.Name,
+	}
+}
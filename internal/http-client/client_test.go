@@ -0,0 +1,125 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+// useTestConfig points the config directory, settings and run state at fresh defaults for one test
+func useTestConfig(t *testing.T) {
+	t.Helper()
+	oldConfigPath, oldSettings := ConfigPath, Settings
+	oldVariables, oldEnvironment := runtimeVariables, environmentOverride
+	t.Cleanup(func() {
+		ConfigPath, Settings = oldConfigPath, oldSettings
+		runtimeVariables, environmentOverride = oldVariables, oldEnvironment
+	})
+	if err := InitConfigDir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	Settings = DefaultSettings()
+	runtimeVariables, environmentOverride = make(map[string]string), ""
+}
+
+// droppingServer closes every connection without responding, counting the attempts
+func droppingServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &attempts
+}
+
+func TestClientRetriesIdempotentMethodsOnly(t *testing.T) {
+	tests := []struct {
+		method   string
+		attempts int32
+	}{
+		{method: http.MethodGet, attempts: 3},
+		{method: http.MethodPut, attempts: 3},
+		{method: http.MethodDelete, attempts: 3},
+		{method: http.MethodPost, attempts: 1},
+		{method: http.MethodPatch, attempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			useTestConfig(t)
+			Settings.Behavior.MaxRetries = 2
+			Settings.Behavior.RetryDelay = 0
+			server, attempts := droppingServer(t)
+
+			_, err := NewClient(time.Second).Do(RequestOptions{Method: tt.method, URL: server.URL}, false)
+			if err == nil {
+				t.Fatal("expected an error from a dropped connection")
+			}
+			if got := attempts.Load(); got != tt.attempts {
+				t.Errorf("%s was sent %d times, want %d", tt.method, got, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestClientTimeoutIncludesRetries(t *testing.T) {
+	useTestConfig(t)
+	Settings.Behavior.MaxRetries = 3
+	Settings.Behavior.RetryDelay = 1
+	server, _ := droppingServer(t)
+
+	start := time.Now()
+	_, err := NewClient(300*time.Millisecond).Do(RequestOptions{Method: http.MethodGet, URL: server.URL}, false)
+	if err == nil {
+		t.Fatal("expected an error from a dropped connection")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("request took %s, want it bounded by the 300ms timeout", elapsed)
+	}
+}
+
+func TestUpdateTemplateRename(t *testing.T) {
+	useTestConfig(t)
+	if err := SaveTemplate(model.Template{Name: "foo", Method: "GET", URL: "https://x.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"bar/baz", "../x", `..\x`, "..", ".", " "} {
+		template, err := GetTemplateByName("foo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		template.Name = name
+		if err := UpdateTemplate(*template, "foo"); err == nil {
+			t.Errorf("UpdateTemplate() to %q succeeded, want an invalid name error", name)
+		}
+		if _, err := GetTemplateByName("foo"); err != nil {
+			t.Errorf("template foo is gone after a failed rename to %q: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(ConfigPath, "x.json")); !os.IsNotExist(err) {
+		t.Errorf("a rename wrote outside the templates directory: %v", err)
+	}
+
+	template, _ := GetTemplateByName("foo")
+	template.Name = "bar"
+	if err := UpdateTemplate(*template, "foo"); err != nil {
+		t.Fatalf("UpdateTemplate() error = %v", err)
+	}
+	if _, err := GetTemplateByName("bar"); err != nil {
+		t.Errorf("renamed template not found: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(ConfigPath, "templates", "foo.json")); !os.IsNotExist(err) {
+		t.Errorf("old template file still exists after the rename: %v", err)
+	}
+}
@@ -0,0 +1,340 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+// collectionFile holds a collection's order and defaults inside its folder
+const collectionFile = "collection.json"
+
+// collectionDir returns the directory of a collection path such as "api/users"
+func collectionDir(collectionPath string) (string, error) {
+	clean := path.Clean(filepath.ToSlash(collectionPath))
+	if clean == "." || clean == "" || path.IsAbs(clean) || strings.HasPrefix(clean, "..") {
+		return "", fmt.Errorf("invalid collection path '%s'", collectionPath)
+	}
+	return filepath.Join(ConfigPath, "templates", filepath.FromSlash(clean)), nil
+}
+
+// GetCollections returns the top-level collections sorted by name
+func GetCollections() ([]model.Collection, error) {
+	templatesDir := filepath.Join(ConfigPath, "templates")
+
+	entries, err := os.ReadDir(templatesDir)
+	if os.IsNotExist(err) {
+		return []model.Collection{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read templates directory: %w", err)
+	}
+
+	collections := []model.Collection{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		collection, err := LoadCollection(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, *collection)
+	}
+	return collections, nil
+}
+
+// GetCollectionPaths returns the paths of all collections, including nested ones, sorted
+func GetCollectionPaths() ([]string, error) {
+	collections, err := GetCollections()
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	var walk func(prefix string, collection model.Collection)
+	walk = func(prefix string, collection model.Collection) {
+		current := path.Join(prefix, collection.Name)
+		paths = append(paths, current)
+		for _, folder := range collection.Folders {
+			walk(current, folder)
+		}
+	}
+	for _, collection := range collections {
+		walk("", collection)
+	}
+
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// LoadCollection reads a collection with its templates and sub-collections
+func LoadCollection(collectionPath string) (*model.Collection, error) {
+	dir, err := collectionDir(collectionPath)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("collection '%s' not found", collectionPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read collection '%s': %w", collectionPath, err)
+	}
+
+	collection := &model.Collection{}
+	if data, err := os.ReadFile(filepath.Join(dir, collectionFile)); err == nil {
+		if err := json.Unmarshal(data, collection); err != nil {
+			return nil, fmt.Errorf("failed to parse %s in collection '%s': %w", collectionFile, collectionPath, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s in collection '%s': %w", collectionFile, collectionPath, err)
+	}
+	// The folder name is the collection's identity
+	collection.Name = filepath.Base(dir)
+
+	for _, entry := range entries {
+		name := entry.Name()
+
+		if entry.IsDir() {
+			folder, err := LoadCollection(path.Join(collectionPath, name))
+			if err != nil {
+				return nil, err
+			}
+			collection.Folders = append(collection.Folders, *folder)
+			continue
+		}
+
+		if name == collectionFile || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template file %s: %w", name, err)
+		}
+
+		var template model.Template
+		if err := json.Unmarshal(data, &template); err != nil {
+			return nil, fmt.Errorf("failed to parse template file %s: %w", name, err)
+		}
+		if template.Name == "" {
+			template.Name = strings.TrimSuffix(name, ".json")
+		}
+		collection.Templates = append(collection.Templates, template)
+	}
+
+	rank := collectionRank(collection.Order)
+	sort.SliceStable(collection.Templates, func(i, j int) bool {
+		return rank(collection.Templates[i].Name, collection.Templates[j].Name)
+	})
+	sort.SliceStable(collection.Folders, func(i, j int) bool {
+		return rank(collection.Folders[i].Name, collection.Folders[j].Name)
+	})
+
+	return collection, nil
+}
+
+// SaveCollection writes a collection's order and defaults, creating its folder if needed
+func SaveCollection(collectionPath string, collection model.Collection) error {
+	dir, err := collectionDir(collectionPath)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create collection directory: %w", err)
+	}
+
+	collection.Name = filepath.Base(dir)
+	if Settings.Redaction.RedactTemplates {
+		r := newRedactor()
+		collection.Headers = r.headers(collection.Headers)
+		collection.Auth = r.auth(collection.Auth)
+	}
+	data, err := json.MarshalIndent(collection, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal collection: %w", err)
+	}
+
+	return os.WriteFile(filepath.Join(dir, collectionFile), data, 0600)
+}
+
+// SaveCollectionTemplate saves a template into a collection folder
+func SaveCollectionTemplate(collectionPath string, template model.Template) error {
+	dir, err := collectionDir(collectionPath)
+	if err != nil {
+		return err
+	}
+
+	if template.Name+".json" == collectionFile {
+		return fmt.Errorf("'%s' is reserved for collection settings", strings.TrimSuffix(collectionFile, ".json"))
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create collection directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(templateForSave(template), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal template: %w", err)
+	}
+
+	return os.WriteFile(filepath.Join(dir, fmt.Sprintf("%s.json", template.Name)), data, 0600)
+}
+
+// ImportCollection writes a whole collection tree, with its templates and folders, under collectionPath.
+// An existing collection is only replaced when overwrite is set.
+func ImportCollection(collectionPath string, collection model.Collection, overwrite bool) error {
+	dir, err := collectionDir(collectionPath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(dir); err == nil {
+		if !overwrite {
+			return fmt.Errorf("collection '%s' already exists", collectionPath)
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("failed to replace collection '%s': %w", collectionPath, err)
+		}
+	}
+
+	return saveCollectionTree(collectionPath, collection)
+}
+
+func saveCollectionTree(collectionPath string, collection model.Collection) error {
+	if err := SaveCollection(collectionPath, collection); err != nil {
+		return err
+	}
+	for _, template := range collection.Templates {
+		if err := SaveCollectionTemplate(collectionPath, template); err != nil {
+			return err
+		}
+	}
+	for _, folder := range collection.Folders {
+		if err := saveCollectionTree(path.Join(collectionPath, folder.Name), folder); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectionDefaults are the values a collection passes down to its contents
+type collectionDefaults struct {
+	baseURL string
+	headers map[string]string
+	auth    *model.Auth
+}
+
+// CollectionTemplates flattens a collection into the templates to execute, in order.
+// Inherited base URL, headers and auth are applied and names are prefixed with their folder path.
+func CollectionTemplates(collection model.Collection) []model.Template {
+	var templates []model.Template
+	walkCollection(collection, "", collectionDefaults{}, &templates)
+	return templates
+}
+
+func walkCollection(collection model.Collection, prefix string, inherited collectionDefaults, templates *[]model.Template) {
+	defaults := collectionDefaults{
+		baseURL: inherited.baseURL,
+		headers: make(map[string]string),
+		auth:    inherited.auth,
+	}
+	mergeHeaders(defaults.headers, inherited.headers)
+	mergeHeaders(defaults.headers, collection.Headers)
+	if collection.BaseURL != "" {
+		defaults.baseURL = collection.BaseURL
+	}
+	if collection.Auth != nil {
+		defaults.auth = collection.Auth
+	}
+
+	rank := collectionRank(collection.Order)
+	ti, fi := 0, 0
+	for ti < len(collection.Templates) || fi < len(collection.Folders) {
+		takeTemplate := fi >= len(collection.Folders) ||
+			(ti < len(collection.Templates) && rank(collection.Templates[ti].Name, collection.Folders[fi].Name))
+
+		if takeTemplate {
+			template := applyCollectionDefaults(collection.Templates[ti], defaults)
+			template.Name = path.Join(prefix, template.Name)
+			*templates = append(*templates, template)
+			ti++
+		} else {
+			folder := collection.Folders[fi]
+			walkCollection(folder, path.Join(prefix, folder.Name), defaults, templates)
+			fi++
+		}
+	}
+}
+
+// applyCollectionDefaults fills in what a template does not set itself
+func applyCollectionDefaults(template model.Template, defaults collectionDefaults) model.Template {
+	if defaults.baseURL != "" && !isAbsoluteURL(template.URL) {
+		template.URL = strings.TrimRight(defaults.baseURL, "/") + "/" + strings.TrimLeft(template.URL, "/")
+	}
+
+	if len(defaults.headers) > 0 {
+		headers := make(map[string]string, len(defaults.headers)+len(template.Headers))
+		mergeHeaders(headers, defaults.headers)
+		mergeHeaders(headers, template.Headers)
+		template.Headers = headers
+	}
+
+	if template.Auth == nil {
+		template.Auth = defaults.auth
+	}
+	return template
+}
+
+// mergeHeaders copies src into dst. A header in src replaces the one in dst with the same
+// name in any case, so that Authorization and authorization are not both sent.
+func mergeHeaders(dst, src map[string]string) {
+	for name, value := range src {
+		for existing := range dst {
+			if strings.EqualFold(existing, name) {
+				delete(dst, existing)
+			}
+		}
+		dst[name] = value
+	}
+}
+
+// isAbsoluteURL reports whether a template URL already names its host
+func isAbsoluteURL(url string) bool {
+	return strings.Contains(url, "://") || strings.HasPrefix(url, "{{")
+}
+
+// collectionRank returns a less function ordering names by their position in order,
+// with unlisted names last and sorted alphabetically
+func collectionRank(order []string) func(a, b string) bool {
+	position := make(map[string]int, len(order))
+	for i, name := range order {
+		if _, exists := position[name]; !exists {
+			position[name] = i
+		}
+	}
+
+	index := func(name string) int {
+		if i, ok := position[name]; ok {
+			return i
+		}
+		return len(order)
+	}
+
+	return func(a, b string) bool {
+		if index(a) != index(b) {
+			return index(a) < index(b)
+		}
+		return a < b
+	}
+}
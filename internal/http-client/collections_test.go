@@ -0,0 +1,171 @@
+package httpclient
+
+import (
+	"reflect"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+func TestCollectionRank(t *testing.T) {
+	tests := []struct {
+		name  string
+		order []string
+		items []string
+		want  []string
+	}{
+		{
+			name:  "no order sorts by name",
+			items: []string{"delete", "create", "list"},
+			want:  []string{"create", "delete", "list"},
+		},
+		{
+			name:  "listed items first, in order",
+			order: []string{"login", "create", "delete"},
+			items: []string{"delete", "create", "login"},
+			want:  []string{"login", "create", "delete"},
+		},
+		{
+			name:  "unlisted items last, by name",
+			order: []string{"login"},
+			items: []string{"zeta", "alpha", "login"},
+			want:  []string{"login", "alpha", "zeta"},
+		},
+		{
+			name:  "order names that do not exist are ignored",
+			order: []string{"missing", "b"},
+			items: []string{"c", "a", "b"},
+			want:  []string{"b", "a", "c"},
+		},
+		{
+			name:  "duplicates keep their first position",
+			order: []string{"b", "a", "b"},
+			items: []string{"a", "b"},
+			want:  []string{"b", "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := slices.Clone(tt.items)
+			rank := collectionRank(tt.order)
+			sort.SliceStable(items, func(i, j int) bool { return rank(items[i], items[j]) })
+			if !reflect.DeepEqual(items, tt.want) {
+				t.Errorf("sorted %v, want %v", items, tt.want)
+			}
+		})
+	}
+}
+
+func templateNames(templates []model.Template) []string {
+	names := make([]string, len(templates))
+	for i, template := range templates {
+		names[i] = template.Name
+	}
+	return names
+}
+
+func TestCollectionTemplates(t *testing.T) {
+	collection := model.Collection{
+		Name:    "api",
+		Order:   []string{"login", "users", "health"},
+		BaseURL: "https://api.example.com/",
+		Headers: map[string]string{"Accept": "application/json"},
+		Auth:    &model.Auth{Type: "bearer", Primary: "{{token}}"},
+		Templates: []model.Template{
+			{Name: "login", URL: "/login", Auth: &model.Auth{Type: "none"}},
+			{Name: "health", URL: "https://status.example.com/health"},
+		},
+		Folders: []model.Collection{
+			{
+				Name:      "users",
+				Headers:   map[string]string{"accept": "application/vnd.users+json", "X-Team": "users"},
+				Templates: []model.Template{{Name: "create", URL: "users"}, {Name: "list", URL: "users", Headers: map[string]string{"x-team": "list"}}},
+			},
+		},
+	}
+
+	templates := CollectionTemplates(collection)
+
+	wantNames := []string{"login", "users/create", "users/list", "health"}
+	if names := templateNames(templates); !reflect.DeepEqual(names, wantNames) {
+		t.Fatalf("order = %v, want %v", names, wantNames)
+	}
+
+	login, create, list, health := templates[0], templates[1], templates[2], templates[3]
+	if login.URL != "https://api.example.com/login" || login.Auth.Type != "none" {
+		t.Errorf("login = %s with %s auth, want the base URL and its own auth", login.URL, login.Auth.Type)
+	}
+	if create.URL != "https://api.example.com/users" || create.Auth == nil || create.Auth.Type != "bearer" {
+		t.Errorf("create should inherit the base URL and auth, got %s and %v", create.URL, create.Auth)
+	}
+	wantCreate := map[string]string{"accept": "application/vnd.users+json", "X-Team": "users"}
+	if !reflect.DeepEqual(create.Headers, wantCreate) {
+		t.Errorf("folder headers should override collection headers in any case, got %v", create.Headers)
+	}
+	wantList := map[string]string{"accept": "application/vnd.users+json", "x-team": "list"}
+	if !reflect.DeepEqual(list.Headers, wantList) {
+		t.Errorf("template headers should override folder headers in any case, got %v", list.Headers)
+	}
+	if health.URL != "https://status.example.com/health" {
+		t.Errorf("absolute URLs should be kept, got %s", health.URL)
+	}
+	if collection.Templates[0].URL != "/login" {
+		t.Errorf("CollectionTemplates modified the collection")
+	}
+}
+
+func TestLoadCollectionOrder(t *testing.T) {
+	useTestConfig(t)
+
+	err := ImportCollection("api", model.Collection{
+		Order:     []string{"login", "users"},
+		Templates: []model.Template{{Name: "zeta", URL: "/z"}, {Name: "alpha", URL: "/a"}, {Name: "login", URL: "/login"}},
+		Folders: []model.Collection{
+			{Name: "users", Order: []string{"delete", "create"}, Templates: []model.Template{{Name: "create"}, {Name: "delete"}}},
+			{Name: "admin", Templates: []model.Template{{Name: "stats"}}},
+		},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collection, err := LoadCollection("api")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"login", "users/delete", "users/create", "admin/stats", "alpha", "zeta"}
+	if names := templateNames(CollectionTemplates(*collection)); !reflect.DeepEqual(names, want) {
+		t.Errorf("order = %v, want %v", names, want)
+	}
+
+	paths, err := GetCollectionPaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"api", "api/admin", "api/users"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("GetCollectionPaths() = %v, want %v", paths, want)
+	}
+
+	if err := ImportCollection("api", model.Collection{}, false); err == nil {
+		t.Error("importing over an existing collection without overwrite should fail")
+	}
+}
+
+func TestCollectionDir(t *testing.T) {
+	useTestConfig(t)
+
+	for _, collectionPath := range []string{"", ".", "..", "../outside", "/abs", "a/../../b"} {
+		if _, err := collectionDir(collectionPath); err == nil {
+			t.Errorf("collectionDir(%q) should be rejected", collectionPath)
+		}
+	}
+	for _, collectionPath := range []string{"api", "api/users", "api/./users/"} {
+		if _, err := collectionDir(collectionPath); err != nil {
+			t.Errorf("collectionDir(%q): %v", collectionPath, err)
+		}
+	}
+}
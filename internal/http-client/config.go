@@ -0,0 +1,258 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ConfigPath is the directory holding settings, history, templates and auth profiles.
+// It is set by InitConfigDir at startup.
+var ConfigPath = defaultConfigDir()
+
+// ConfigDirEnv overrides the config directory when set
+const ConfigDirEnv = "APIX_CONFIG_DIR"
+
+// legacyConfigPaths are the config roots used by earlier versions, relative to the working directory
+var legacyConfigPaths = []string{".config", filepath.Join("testconfigs", "config1")}
+
+// configEntries are the files and directories apix owns inside a config root
+var configEntries = []string{"settings.json", "history", "history.jsonl", "templates", "auth-profiles"}
+
+// ResolveConfigDir picks the config directory from, in order: the --config-dir flag,
+// $APIX_CONFIG_DIR, $XDG_CONFIG_HOME/apix and the platform user config directory
+func ResolveConfigDir(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if dir := os.Getenv(ConfigDirEnv); dir != "" {
+		return dir
+	}
+	return defaultConfigDir()
+}
+
+// defaultConfigDir returns $XDG_CONFIG_HOME/apix, falling back to the platform config directory
+func defaultConfigDir() string {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "apix")
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, "apix")
+	}
+	return ".apix"
+}
+
+// InitConfigDir makes dir the config directory, creating it if needed
+func InitConfigDir(dir string) error {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve config directory: %w", err)
+	}
+
+	if err := os.MkdirAll(absDir, 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	ConfigPath = absDir
+	return nil
+}
+
+// checkFileName rejects a name of the given kind that can't be stored as a file of its own
+// inside a config subdirectory: empty names, "." and "..", and names with path separators
+func checkFileName(kind, name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("%s name cannot be empty", kind)
+	}
+	if name == "." || name == ".." || strings.ContainsAny(name, `/\`) || filepath.Base(name) != name {
+		return fmt.Errorf("invalid %s name '%s': it can't contain path separators", kind, name)
+	}
+	return nil
+}
+
+// AutoMigrateLegacyConfig runs MigrateLegacyConfig on the default legacy roots while the config
+// directory is still empty, so that upgrading keeps the files of earlier versions. Once the config
+// directory has files, migration only runs through apix config migrate.
+func AutoMigrateLegacyConfig() (moved, skipped []string, err error) {
+	entries, err := os.ReadDir(ConfigPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read config directory: %w", err)
+	}
+	if len(entries) > 0 {
+		return nil, nil, nil
+	}
+	return MigrateLegacyConfig(nil, false)
+}
+
+// MigrateLegacyConfig moves the apix files left in the config roots of earlier versions into
+// the config directory. The roots default to ./.config and ./testconfigs/config1. Only files
+// that parse as apix data are moved; other files are returned as skipped and left in place.
+func MigrateLegacyConfig(roots []string, dryRun bool) (moved, skipped []string, err error) {
+	if len(roots) == 0 {
+		roots = legacyConfigPaths
+	}
+
+	for _, root := range roots {
+		rootMoved, rootSkipped, err := migrateConfigDir(root, ConfigPath, dryRun)
+		moved = append(moved, rootMoved...)
+		skipped = append(skipped, rootSkipped...)
+		if err != nil {
+			return moved, skipped, err
+		}
+	}
+	return moved, skipped, nil
+}
+
+// migrateConfigDir moves the apix files found in legacy into dir.
+// Files that already exist in dir are left where they are.
+func migrateConfigDir(legacy, dir string, dryRun bool) (moved, skipped []string, err error) {
+	absLegacy, err := filepath.Abs(legacy)
+	if err != nil || absLegacy == dir {
+		return nil, nil, nil
+	}
+
+	// Running from the home directory makes ".config" the user's real config root
+	if userConfig, err := os.UserConfigDir(); err == nil && absLegacy == userConfig {
+		return nil, nil, nil
+	}
+
+	if info, err := os.Stat(absLegacy); err != nil || !info.IsDir() {
+		return nil, nil, nil
+	}
+
+	for _, entry := range configEntries {
+		source := filepath.Join(absLegacy, entry)
+		if _, err := os.Stat(source); err != nil {
+			continue
+		}
+
+		err := filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+
+			rel, err := filepath.Rel(absLegacy, path)
+			if err != nil {
+				return err
+			}
+			target := filepath.Join(dir, rel)
+			if _, err := os.Stat(target); err == nil || !isApixFile(entry, path) {
+				skipped = append(skipped, path)
+				return nil
+			}
+
+			if !dryRun {
+				if err := moveFile(path, target); err != nil {
+					return fmt.Errorf("failed to migrate %s: %w", path, err)
+				}
+			}
+			moved = append(moved, path)
+			return nil
+		})
+		if err != nil {
+			return moved, skipped, err
+		}
+
+		if !dryRun {
+			removeEmptyDirs(source)
+		}
+	}
+	return moved, skipped, nil
+}
+
+// isApixFile reports whether path, found under the config entry named entry, holds apix data
+// rather than another tool's file that happens to live in the same directory
+func isApixFile(entry, path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	switch entry {
+	case "settings.json":
+		_, err := ParseSettings(data, "json")
+		return err == nil
+	case "history":
+		var entries []map[string]json.RawMessage
+		if json.Unmarshal(data, &entries) != nil {
+			return false
+		}
+		for _, entry := range entries {
+			if !hasFields(entry, "Method", "URL") {
+				return false
+			}
+		}
+		return true
+	case "history.jsonl":
+		for _, line := range bytes.Split(bytes.TrimSpace(data), []byte("\n")) {
+			var entry map[string]json.RawMessage
+			if json.Unmarshal(line, &entry) != nil || !hasFields(entry, "Method", "URL") {
+				return false
+			}
+		}
+		return true
+	case "templates":
+		var template map[string]json.RawMessage
+		return filepath.Ext(path) == ".json" && json.Unmarshal(data, &template) == nil && hasFields(template, "method", "url")
+	case "auth-profiles":
+		var profile map[string]json.RawMessage
+		return filepath.Ext(path) == ".json" && json.Unmarshal(data, &profile) == nil && hasFields(profile, "name", "type")
+	default:
+		return false
+	}
+}
+
+func hasFields(object map[string]json.RawMessage, names ...string) bool {
+	for _, name := range names {
+		if _, ok := object[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// moveFile renames source to target, copying when they are on different filesystems
+func moveFile(source, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(source, target); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(target)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Remove(source)
+}
+
+// removeEmptyDirs removes dir and its subdirectories if they are empty
+func removeEmptyDirs(dir string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			removeEmptyDirs(filepath.Join(dir, entry.Name()))
+		}
+	}
+	os.Remove(dir)
+}
@@ -0,0 +1,131 @@
+package httpclient
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIsApixFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   string
+		file    string
+		content string
+		want    bool
+	}{
+		{name: "template", entry: "templates", file: "get.json", content: `{"name":"get","method":"GET","url":"http://x"}`, want: true},
+		{name: "other tool's json", entry: "templates", file: "eslint.json", content: `{"extends":"standard"}`, want: false},
+		{name: "not json", entry: "templates", file: "notes.json", content: `hello`, want: false},
+		{name: "template without .json", entry: "templates", file: "get.bak", content: `{"method":"GET","url":"http://x"}`, want: false},
+		{name: "legacy history", entry: "history", file: "history", content: `[{"Method":"GET","URL":"http://x"}]`, want: true},
+		{name: "history of another tool", entry: "history", file: "history", content: `[{"cmd":"ls"}]`, want: false},
+		{name: "history lines", entry: "history.jsonl", file: "history.jsonl", content: "{\"Method\":\"GET\",\"URL\":\"a\"}\n{\"Method\":\"POST\",\"URL\":\"b\"}\n", want: true},
+		{name: "other lines", entry: "history.jsonl", file: "history.jsonl", content: "{\"Method\":\"GET\",\"URL\":\"a\"}\n{\"event\":1}\n", want: false},
+		{name: "auth profile", entry: "auth-profiles", file: "p.json", content: `{"name":"p","type":"bearer","token":"t"}`, want: true},
+		{name: "other profile", entry: "auth-profiles", file: "p.json", content: `{"aws_profile":"default"}`, want: false},
+		{name: "settings", entry: "settings.json", file: "settings.json", content: `{"version":"1.1.0"}`, want: true},
+		{name: "other settings", entry: "settings.json", file: "settings.json", content: `{"editor.fontSize":12}`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			writeTestFile(t, path, tt.content)
+			if got := isApixFile(tt.entry, path); got != tt.want {
+				t.Errorf("isApixFile(%q, %s) = %v, want %v", tt.entry, tt.content, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrateLegacyConfig(t *testing.T) {
+	legacy := t.TempDir()
+	writeTestFile(t, filepath.Join(legacy, "templates", "get.json"), `{"name":"get","method":"GET","url":"http://x"}`)
+	writeTestFile(t, filepath.Join(legacy, "templates", "eslint.json"), `{"extends":"standard"}`)
+	writeTestFile(t, filepath.Join(legacy, "other", "file.txt"), "untouched")
+
+	oldConfigPath := ConfigPath
+	t.Cleanup(func() { ConfigPath = oldConfigPath })
+	if err := InitConfigDir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	moved, skipped, err := MigrateLegacyConfig([]string{legacy}, true)
+	if err != nil || len(moved) != 1 || len(skipped) != 1 {
+		t.Fatalf("dry run: moved %v, skipped %v, err %v", moved, skipped, err)
+	}
+	if _, err := os.Stat(filepath.Join(legacy, "templates", "get.json")); err != nil {
+		t.Fatalf("dry run moved a file: %v", err)
+	}
+
+	if _, _, err := MigrateLegacyConfig([]string{legacy}, false); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{
+		filepath.Join(ConfigPath, "templates", "get.json"),
+		filepath.Join(legacy, "templates", "eslint.json"),
+		filepath.Join(legacy, "other", "file.txt"),
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expected %s to exist: %v", path, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(legacy, "templates", "get.json")); !os.IsNotExist(err) {
+		t.Errorf("get.json was not moved out of the legacy directory")
+	}
+}
+
+func TestAutoMigrateLegacyConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		existing  string
+		wantMoved int
+	}{
+		{name: "empty config directory", wantMoved: 1},
+		{name: "config directory in use", existing: "settings.json", wantMoved: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			work := t.TempDir()
+			writeTestFile(t, filepath.Join(work, ".config", "templates", "get.json"), `{"name":"get","method":"GET","url":"http://x"}`)
+			writeTestFile(t, filepath.Join(work, ".config", "templates", "eslint.json"), `{"extends":"standard"}`)
+			t.Chdir(work)
+
+			oldConfigPath := ConfigPath
+			t.Cleanup(func() { ConfigPath = oldConfigPath })
+			if err := InitConfigDir(t.TempDir()); err != nil {
+				t.Fatal(err)
+			}
+			if tt.existing != "" {
+				writeTestFile(t, filepath.Join(ConfigPath, tt.existing), `{"version":"1.1.0"}`)
+			}
+
+			moved, _, err := AutoMigrateLegacyConfig()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(moved) != tt.wantMoved {
+				t.Fatalf("moved %v, want %d file(s)", moved, tt.wantMoved)
+			}
+			_, err = os.Stat(filepath.Join(ConfigPath, "templates", "get.json"))
+			if migrated := err == nil; migrated != (tt.wantMoved > 0) {
+				t.Errorf("get.json migrated = %v, want %v", migrated, tt.wantMoved > 0)
+			}
+			if _, err := os.Stat(filepath.Join(work, ".config", "templates", "eslint.json")); err != nil {
+				t.Errorf("a file that is not apix data was moved: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+// environmentOverride is the environment selected with --env for this run only
+var environmentOverride string
+
+// environmentsDir returns the directory holding one JSON file per environment
+func environmentsDir() string {
+	return filepath.Join(ConfigPath, "environments")
+}
+
+// GetEnvironments returns all saved environments sorted by name
+func GetEnvironments() ([]model.Environment, error) {
+	dir := environmentsDir()
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []model.Environment{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read environments directory: %w", err)
+	}
+
+	environments := make([]model.Environment, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read environment file %s: %w", entry.Name(), err)
+		}
+
+		var environment model.Environment
+		if err := json.Unmarshal(data, &environment); err != nil {
+			return nil, fmt.Errorf("failed to parse environment file %s: %w", entry.Name(), err)
+		}
+		if environment.Variables == nil {
+			environment.Variables = make(map[string]string)
+		}
+		environments = append(environments, environment)
+	}
+
+	sort.Slice(environments, func(i, j int) bool {
+		return environments[i].Name < environments[j].Name
+	})
+	return environments, nil
+}
+
+// GetEnvironment returns the environment with the given name
+func GetEnvironment(name string) (*model.Environment, error) {
+	environments, err := GetEnvironments()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, environment := range environments {
+		if environment.Name == name {
+			return &environment, nil
+		}
+	}
+	return nil, fmt.Errorf("environment '%s' not found", name)
+}
+
+// environmentPath returns the file of the environment with the given name. Names that
+// would put the file outside the environments directory are rejected.
+func environmentPath(name string) (string, error) {
+	if err := checkFileName("environment", name); err != nil {
+		return "", err
+	}
+	return filepath.Join(environmentsDir(), name+".json"), nil
+}
+
+// SaveEnvironment writes an environment to its own file
+func SaveEnvironment(environment model.Environment) error {
+	path, err := environmentPath(environment.Name)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(environmentsDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create environments directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(environment, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal environment: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write environment file: %w", err)
+	}
+	return nil
+}
+
+// DeleteEnvironment removes an environment by name
+func DeleteEnvironment(name string) error {
+	path, err := environmentPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("environment '%s' not found", name)
+		}
+		return fmt.Errorf("failed to delete environment file: %w", err)
+	}
+	return nil
+}
+
+// SetActiveEnvironment marks name as the active environment. An empty name deactivates all environments.
+func SetActiveEnvironment(name string) error {
+	environments, err := GetEnvironments()
+	if err != nil {
+		return err
+	}
+
+	found := name == ""
+	for _, environment := range environments {
+		active := environment.Name == name
+		found = found || active
+		if environment.Active == active {
+			continue
+		}
+		environment.Active = active
+		if err := SaveEnvironment(environment); err != nil {
+			return err
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("environment '%s' not found", name)
+	}
+	return nil
+}
+
+// UseEnvironment selects an environment for the current run without changing the saved active one
+func UseEnvironment(name string) error {
+	if name != "" {
+		if _, err := GetEnvironment(name); err != nil {
+			return err
+		}
+	}
+	environmentOverride = name
+	return nil
+}
+
+// GetActiveEnvironment returns the environment used for variable substitution, or nil if there is none
+func GetActiveEnvironment() (*model.Environment, error) {
+	if environmentOverride != "" {
+		return GetEnvironment(environmentOverride)
+	}
+
+	environments, err := GetEnvironments()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, environment := range environments {
+		if environment.Active {
+			return &environment, nil
+		}
+	}
+	return nil, nil
+}
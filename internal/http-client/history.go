@@ -0,0 +1,173 @@
+package httpclient
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// HistoryEntry is a sent request together with what came back: the response metadata and
+// a snapshot of its body, or the error when the request failed
+type HistoryEntry struct {
+	RequestOptions
+	Status          string            `json:",omitempty"`
+	StatusCode      int               `json:",omitempty"`
+	Duration        time.Duration     `json:",omitempty"`
+	Size            int64             `json:",omitempty"`
+	ResponseHeaders map[string]string `json:",omitempty"`
+	ResponseBody    string            `json:",omitempty"`
+	BodyTruncated   bool              `json:",omitempty"`
+	Error           string            `json:",omitempty"`
+}
+
+// Failed reports whether the request failed without receiving a response
+func (e HistoryEntry) Failed() bool {
+	return e.Error != ""
+}
+
+// NewHistoryEntry records opts with the response it received, or with err if it failed.
+// The body snapshot is limited to Settings.Behavior.HistoryBodyLimit KB and left out for binary bodies.
+func NewHistoryEntry(opts RequestOptions, response *resty.Response, err error) HistoryEntry {
+	if opts.Time.IsZero() {
+		opts.Time = time.Now()
+	}
+	opts.Context = nil
+
+	entry := HistoryEntry{RequestOptions: opts}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if response == nil || response.RawResponse == nil {
+		return entry
+	}
+
+	entry.Status = response.Status()
+	entry.StatusCode = response.StatusCode()
+	entry.Duration = response.Time()
+	entry.Size = response.Size()
+
+	if len(response.Header()) > 0 {
+		entry.ResponseHeaders = make(map[string]string, len(response.Header()))
+		for key, values := range response.Header() {
+			entry.ResponseHeaders[key] = strings.Join(values, ", ")
+		}
+	}
+
+	body := response.Body()
+	limit := Settings.Behavior.HistoryBodyLimit * 1024
+	if limit > 0 && len(body) > 0 && utf8.Valid(body) {
+		if len(body) > limit {
+			body = body[:limit]
+			// Don't cut a multi-byte character in half
+			for len(body) > 0 && !utf8.Valid(body) {
+				body = body[:len(body)-1]
+			}
+			entry.BodyTruncated = true
+		}
+		entry.ResponseBody = string(body)
+	}
+	return entry
+}
+
+// GetHistory returns the recorded requests within the retention limits, oldest first
+func GetHistory() ([]HistoryEntry, error) {
+	if err := migrateLegacyHistory(); err != nil {
+		return nil, err
+	}
+
+	history, err := readHistory(historyPath())
+	if err != nil {
+		return nil, err
+	}
+	return retainHistory(history, time.Now()), nil
+}
+
+// GetHistoryEntry returns the history entry with the given ID
+func GetHistoryEntry(id int) (*HistoryEntry, error) {
+	history, err := GetHistory()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range history {
+		if history[i].Id == id {
+			return &history[i], nil
+		}
+	}
+	return nil, fmt.Errorf("history entry %d not found", id)
+}
+
+// SearchHistory returns the history entries matching filter, oldest first
+func SearchHistory(filter HistoryFilter) ([]HistoryEntry, error) {
+	if err := ValidateStatusFilter(filter.Status); err != nil {
+		return nil, err
+	}
+
+	history, err := GetHistory()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []HistoryEntry
+	for _, entry := range history {
+		if filter.Matches(entry) {
+			matches = append(matches, entry)
+		}
+	}
+
+	if filter.Limit > 0 && len(matches) > filter.Limit {
+		matches = matches[len(matches)-filter.Limit:]
+	}
+	return matches, nil
+}
+
+// HistoryFilter selects history entries. Empty fields match everything.
+type HistoryFilter struct {
+	Method      string    // request method, case-insensitive
+	Status      string    // a status code ("404"), a class ("5xx") or "error" for failed requests
+	URLContains string    // substring of the request URL
+	Since       time.Time // only entries recorded at or after this time
+	Limit       int       // keep only the newest matches, 0 = all
+}
+
+// statusFilterPattern matches status codes and classes such as "404" and "5xx"
+var statusFilterPattern = regexp.MustCompile(`^[1-5]([0-9]{2}|xx)$`)
+
+// ValidateStatusFilter checks the value of HistoryFilter.Status
+func ValidateStatusFilter(status string) error {
+	status = strings.ToLower(status)
+	if status == "" || status == "error" || statusFilterPattern.MatchString(status) {
+		return nil
+	}
+	return fmt.Errorf("invalid status filter %q (use a code such as 404, a class such as 5xx, or error)", status)
+}
+
+// Matches reports whether entry satisfies every field of the filter
+func (f HistoryFilter) Matches(entry HistoryEntry) bool {
+	if f.Method != "" && !strings.EqualFold(entry.Method, f.Method) {
+		return false
+	}
+	if f.URLContains != "" && !strings.Contains(entry.URL, f.URLContains) {
+		return false
+	}
+	if !f.Since.IsZero() && entry.Time.Before(f.Since) {
+		return false
+	}
+
+	switch status := strings.ToLower(f.Status); {
+	case status == "":
+		return true
+	case status == "error":
+		return entry.Failed()
+	case entry.StatusCode == 0:
+		return false
+	case strings.HasSuffix(status, "xx"):
+		return entry.StatusCode/100 == int(status[0]-'0')
+	default:
+		return fmt.Sprint(entry.StatusCode) == status
+	}
+}
@@ -0,0 +1,360 @@
+package httpclient
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// History is stored as JSON Lines, one entry per line, so recording a request appends a
+// single line instead of rewriting the file. Writers from parallel apix processes take a
+// lock file first; readers skip lines that are incomplete or corrupt.
+const (
+	historyFile       = "history.jsonl"
+	legacyHistoryFile = "history" // the JSON array written by earlier versions
+
+	// historyLockTimeout is how long a writer waits for another process to release the lock
+	historyLockTimeout = 5 * time.Second
+	// historyStaleLock is the age after which a lock is assumed to be left by a crashed process
+	historyStaleLock = 30 * time.Second
+	// historyPruneInterval is how many appends pass between applying the retention limits to the file
+	historyPruneInterval = 100
+	// maxHistoryLine bounds a single entry, which holds at most a 1 MB body snapshot
+	maxHistoryLine = 16 * 1024 * 1024
+)
+
+func historyPath() string {
+	return filepath.Join(ConfigPath, historyFile)
+}
+
+// UpdateHistory appends entry to the history, assigning it the next ID. IDs are never reused,
+// so they stay valid as older entries are pruned.
+func UpdateHistory(entry HistoryEntry) error {
+	if err := os.MkdirAll(ConfigPath, 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	unlock, err := lockHistory()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := migrateLegacyHistoryLocked(); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(historyPath(), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer file.Close()
+
+	lastID, err := lastHistoryID(file)
+	if err != nil {
+		return fmt.Errorf("failed to read history file: %w", err)
+	}
+	entry.Id = lastID + 1
+	if Settings.Redaction.RedactHistory {
+		entry = RedactHistoryEntry(entry)
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history entry: %w", err)
+	}
+	// Start on a new line if an interrupted write left a partial one
+	terminated, err := endsWithNewline(file)
+	if err != nil {
+		return fmt.Errorf("failed to read history file: %w", err)
+	}
+	if !terminated {
+		line = append([]byte{'\n'}, line...)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+
+	if entry.Id%historyPruneInterval == 0 {
+		_, err := pruneHistoryLocked()
+		return err
+	}
+	return nil
+}
+
+// DeleteHistory deletes all recorded history
+func DeleteHistory() error {
+	unlock, err := lockHistory()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for _, name := range []string{historyFile, legacyHistoryFile} {
+		if err := os.Remove(filepath.Join(ConfigPath, name)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete history: %w", err)
+		}
+	}
+	return nil
+}
+
+// PruneHistory removes the entries outside the retention limits from the history file now,
+// rather than at the next prune interval, and returns how many it removed
+func PruneHistory() (int, error) {
+	unlock, err := lockHistory()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	if err := migrateLegacyHistoryLocked(); err != nil {
+		return 0, err
+	}
+	return pruneHistoryLocked()
+}
+
+// retainHistory drops entries older than Settings.Behavior.HistoryMaxAge days and all but the
+// newest Settings.Behavior.HistoryMaxEntries. A limit of 0 disables it.
+func retainHistory(history []HistoryEntry, now time.Time) []HistoryEntry {
+	if maxAge := Settings.Behavior.HistoryMaxAge; maxAge > 0 {
+		cutoff := now.AddDate(0, 0, -maxAge)
+		first := 0
+		for first < len(history) && history[first].Time.Before(cutoff) {
+			first++
+		}
+		history = history[first:]
+	}
+
+	if maxEntries := Settings.Behavior.HistoryMaxEntries; maxEntries > 0 && len(history) > maxEntries {
+		history = history[len(history)-maxEntries:]
+	}
+	return history
+}
+
+// pruneHistoryLocked rewrites the history file with only the retained entries and returns how
+// many were removed. The caller holds the lock.
+func pruneHistoryLocked() (int, error) {
+	history, err := readHistory(historyPath())
+	if err != nil {
+		return 0, err
+	}
+
+	retained := retainHistory(history, time.Now())
+	if len(retained) == len(history) {
+		return 0, nil
+	}
+	if err := writeHistory(historyPath(), retained); err != nil {
+		return 0, err
+	}
+	return len(history) - len(retained), nil
+}
+
+// readHistory reads the entries of a JSON Lines history file, skipping lines that don't decode
+func readHistory(path string) ([]HistoryEntry, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return []HistoryEntry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+	defer file.Close()
+
+	history := []HistoryEntry{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxHistoryLine)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var entry HistoryEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			// A write interrupted by a crash leaves a partial line behind
+			continue
+		}
+		history = append(history, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	return history, nil
+}
+
+// writeHistory replaces the history file with entries, writing a temporary file first so
+// that readers never see a half-written history
+func writeHistory(path string, history []HistoryEntry) error {
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	for _, entry := range history {
+		if err := encoder.Encode(entry); err != nil {
+			return fmt.Errorf("failed to marshal history entry: %w", err)
+		}
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	return nil
+}
+
+// lastHistoryID returns the ID of the last entry in the history file, or -1 if it has none.
+// Only the end of the file is read, one chunk at a time, until a complete entry is found.
+func lastHistoryID(file *os.File) (int, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return -1, err
+	}
+
+	const chunkSize = 64 * 1024
+	var carry []byte // the start of a line whose beginning is in an earlier chunk
+	for end := info.Size(); end > 0; {
+		start := max(end-chunkSize, 0)
+		chunk := make([]byte, end-start)
+		if _, err := file.ReadAt(chunk, start); err != nil {
+			return -1, err
+		}
+		data := append(chunk, carry...)
+
+		// data always ends at a line boundary, so everything after its last newline is a whole line
+		for {
+			i := bytes.LastIndexByte(data, '\n')
+			if i < 0 {
+				break
+			}
+			if id, ok := historyLineID(data[i+1:]); ok {
+				return id, nil
+			}
+			data = data[:i]
+		}
+		if start == 0 {
+			if id, ok := historyLineID(data); ok {
+				return id, nil
+			}
+		}
+
+		carry = data
+		end = start
+	}
+	return -1, nil
+}
+
+// endsWithNewline reports whether file is empty or ends with a newline
+func endsWithNewline(file *os.File) (bool, error) {
+	info, err := file.Stat()
+	if err != nil || info.Size() == 0 {
+		return true, err
+	}
+	last := make([]byte, 1)
+	if _, err := file.ReadAt(last, info.Size()-1); err != nil {
+		return false, err
+	}
+	return last[0] == '\n', nil
+}
+
+func historyLineID(line []byte) (int, bool) {
+	var entry struct{ Id *int }
+	if err := json.Unmarshal(bytes.TrimSpace(line), &entry); err != nil || entry.Id == nil {
+		return 0, false
+	}
+	return *entry.Id, true
+}
+
+// lockHistory takes the history lock, waiting for other apix processes to release it.
+// The returned function releases it.
+func lockHistory() (func(), error) {
+	path := historyPath() + ".lock"
+	deadline := time.Now().Add(historyLockTimeout)
+
+	for {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			fmt.Fprintf(file, "%d\n", os.Getpid())
+			file.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock history: %w", err)
+		}
+
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > historyStaleLock {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("failed to lock history: %s is held by another apix process", path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// migrateLegacyHistory converts the JSON array history of earlier versions to JSON Lines
+func migrateLegacyHistory() error {
+	if _, err := os.Stat(filepath.Join(ConfigPath, legacyHistoryFile)); os.IsNotExist(err) {
+		return nil
+	}
+
+	unlock, err := lockHistory()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return migrateLegacyHistoryLocked()
+}
+
+// migrateLegacyHistoryLocked is migrateLegacyHistory for callers that hold the lock.
+// Entries keep their IDs and go before any already in the JSON Lines file.
+func migrateLegacyHistoryLocked() error {
+	legacyPath := filepath.Join(ConfigPath, legacyHistoryFile)
+	data, err := os.ReadFile(legacyPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	var legacy []HistoryEntry
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return fmt.Errorf("failed to unmarshal history data: %w", err)
+		}
+	}
+
+	// Entries from before redaction existed get the same treatment as new ones
+	if Settings.Redaction.RedactHistory {
+		for i := range legacy {
+			legacy[i] = RedactHistoryEntry(legacy[i])
+		}
+	}
+
+	current, err := readHistory(historyPath())
+	if err != nil {
+		return err
+	}
+
+	// Keep IDs increasing when entries were already appended to the new file
+	for i := range current {
+		current[i].Id += len(legacy)
+	}
+	if err := writeHistory(historyPath(), append(legacy, current...)); err != nil {
+		return err
+	}
+
+	if err := os.Remove(legacyPath); err != nil {
+		return fmt.Errorf("failed to remove old history file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,346 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// historyIDs returns the IDs of the entries in the history file, in file order
+func historyIDs(t *testing.T) []int {
+	t.Helper()
+	history, err := readHistory(historyPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := []int{}
+	for _, entry := range history {
+		ids = append(ids, entry.Id)
+	}
+	return ids
+}
+
+func newTestEntry(method string, sent time.Time) HistoryEntry {
+	return HistoryEntry{RequestOptions: RequestOptions{Method: method, URL: "https://x.com", Time: sent}}
+}
+
+func TestLockHistory(t *testing.T) {
+	useTestConfig(t)
+	lockPath := historyPath() + ".lock"
+
+	first, err := lockHistory()
+	if err != nil {
+		t.Fatalf("lockHistory() error = %v", err)
+	}
+	if _, err := os.Stat(lockPath); err != nil {
+		t.Fatalf("lock file missing while the lock is held: %v", err)
+	}
+
+	// A second writer waits until the first releases the lock
+	released := make(chan time.Time, 1)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		released <- time.Now()
+		first()
+	}()
+	unlock, err := lockHistory()
+	if err != nil {
+		t.Fatalf("lockHistory() while held error = %v", err)
+	}
+	if acquired := time.Now(); acquired.Before(<-released) {
+		t.Error("lockHistory() returned before the lock was released")
+	}
+	unlock()
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Errorf("lock file left behind after unlock: %v", err)
+	}
+
+	// A lock left by a crashed process is taken over once it is stale
+	if err := os.WriteFile(lockPath, []byte("12345\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * historyStaleLock)
+	if err := os.Chtimes(lockPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	unlock, err = lockHistory()
+	if err != nil {
+		t.Fatalf("lockHistory() with a stale lock error = %v", err)
+	}
+	unlock()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("taking over a stale lock took %v", elapsed)
+	}
+}
+
+func TestUpdateHistoryParallel(t *testing.T) {
+	useTestConfig(t)
+
+	const writers, perWriter = 10, 15
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*perWriter)
+	for range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range perWriter {
+				errs <- UpdateHistory(newTestEntry("GET", time.Now()))
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("UpdateHistory() error = %v", err)
+		}
+	}
+
+	ids := historyIDs(t)
+	if len(ids) != writers*perWriter {
+		t.Fatalf("history has %d entries, want %d", len(ids), writers*perWriter)
+	}
+	for i, id := range ids {
+		if id != i {
+			t.Fatalf("entry %d has ID %d, want IDs unique and increasing: %v", i, id, ids)
+		}
+	}
+}
+
+func TestHistoryTruncatedLastLine(t *testing.T) {
+	useTestConfig(t)
+
+	for range 2 {
+		if err := UpdateHistory(newTestEntry("GET", time.Now())); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A crash in the middle of a write leaves a partial line without a newline
+	file, err := os.OpenFile(historyPath(), os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"Id":2,"Method":"PO`)
+	file.Close()
+
+	if got := historyIDs(t); !reflect.DeepEqual(got, []int{0, 1}) {
+		t.Errorf("IDs with a truncated line = %v, want [0 1]", got)
+	}
+
+	if err := UpdateHistory(newTestEntry("DELETE", time.Now())); err != nil {
+		t.Fatal(err)
+	}
+	history, err := GetHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 3 || history[2].Id != 2 || history[2].Method != "DELETE" {
+		t.Errorf("history after appending past a truncated line = %+v, want a third DELETE entry with ID 2", history)
+	}
+}
+
+func TestMigrateLegacyHistory(t *testing.T) {
+	useTestConfig(t)
+
+	legacy := []HistoryEntry{
+		newTestEntry("GET", time.Now().Add(-3*time.Hour)),
+		newTestEntry("POST", time.Now().Add(-2*time.Hour)),
+		newTestEntry("PUT", time.Now().Add(-time.Hour)),
+	}
+	for i := range legacy {
+		legacy[i].Id = i
+	}
+	data, err := json.Marshal(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(ConfigPath, legacyHistoryFile), data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Entries already appended by a newer version before the legacy file was migrated
+	if err := writeHistory(historyPath(), []HistoryEntry{
+		{RequestOptions: RequestOptions{Id: 0, Method: "PATCH", Time: time.Now()}},
+		{RequestOptions: RequestOptions{Id: 1, Method: "DELETE", Time: time.Now()}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := GetHistory()
+	if err != nil {
+		t.Fatalf("GetHistory() error = %v", err)
+	}
+	var got []string
+	for _, entry := range history {
+		got = append(got, entry.Method)
+	}
+	if want := []string{"GET", "POST", "PUT", "PATCH", "DELETE"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("methods after migration = %v, want %v", got, want)
+	}
+	if ids := historyIDs(t); !reflect.DeepEqual(ids, []int{0, 1, 2, 3, 4}) {
+		t.Errorf("IDs after migration = %v, want the new entries shifted after the legacy ones", ids)
+	}
+	if _, err := os.Stat(filepath.Join(ConfigPath, legacyHistoryFile)); !os.IsNotExist(err) {
+		t.Errorf("legacy history file still exists: %v", err)
+	}
+
+	if err := UpdateHistory(newTestEntry("HEAD", time.Now())); err != nil {
+		t.Fatal(err)
+	}
+	if ids := historyIDs(t); ids[len(ids)-1] != 5 {
+		t.Errorf("next ID after migration = %d, want 5", ids[len(ids)-1])
+	}
+}
+
+func TestRetainHistory(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	history := []HistoryEntry{
+		newTestEntry("GET", now.AddDate(0, 0, -40)),
+		newTestEntry("GET", now.AddDate(0, 0, -20)),
+		newTestEntry("GET", now.AddDate(0, 0, -5)),
+		newTestEntry("GET", now.Add(-time.Hour)),
+	}
+	for i := range history {
+		history[i].Id = i
+	}
+
+	tests := []struct {
+		name       string
+		maxEntries int
+		maxAge     int
+		want       []int
+	}{
+		{name: "no limits", want: []int{0, 1, 2, 3}},
+		{name: "count", maxEntries: 2, want: []int{2, 3}},
+		{name: "count above size", maxEntries: 10, want: []int{0, 1, 2, 3}},
+		{name: "age", maxAge: 30, want: []int{1, 2, 3}},
+		{name: "age and count", maxAge: 30, maxEntries: 1, want: []int{3}},
+		{name: "short age", maxAge: 1, want: []int{3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestConfig(t)
+			Settings.Behavior.HistoryMaxEntries = tt.maxEntries
+			Settings.Behavior.HistoryMaxAge = tt.maxAge
+
+			ids := []int{}
+			for _, entry := range retainHistory(history, now) {
+				ids = append(ids, entry.Id)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("retainHistory() IDs = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestPruneHistory(t *testing.T) {
+	useTestConfig(t)
+	Settings.Behavior.HistoryMaxEntries = 3
+
+	for range 5 {
+		if err := UpdateHistory(newTestEntry("GET", time.Now())); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Entries beyond the limit are hidden before they are pruned from the file
+	if ids := historyIDs(t); len(ids) != 5 {
+		t.Fatalf("history file has %d entries before pruning, want 5", len(ids))
+	}
+
+	removed, err := PruneHistory()
+	if err != nil {
+		t.Fatalf("PruneHistory() error = %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("PruneHistory() removed %d, want 2", removed)
+	}
+	if ids := historyIDs(t); !reflect.DeepEqual(ids, []int{2, 3, 4}) {
+		t.Errorf("IDs after pruning = %v, want [2 3 4]", ids)
+	}
+
+	if removed, err := PruneHistory(); err != nil || removed != 0 {
+		t.Errorf("PruneHistory() again = %d, %v, want 0, nil", removed, err)
+	}
+}
+
+func TestHistoryFilterMatches(t *testing.T) {
+	sent := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	ok := HistoryEntry{
+		RequestOptions: RequestOptions{Method: "POST", URL: "https://x.com/users/1", Time: sent},
+		Status:         "201 Created",
+		StatusCode:     201,
+	}
+	notFound := HistoryEntry{
+		RequestOptions: RequestOptions{Method: "GET", URL: "https://x.com/pets", Time: sent},
+		Status:         "404 Not Found",
+		StatusCode:     404,
+	}
+	failed := HistoryEntry{
+		RequestOptions: RequestOptions{Method: "GET", URL: "https://down.example.com", Time: sent},
+		Error:          "connection refused",
+	}
+
+	tests := []struct {
+		name   string
+		filter HistoryFilter
+		want   []bool // matches for ok, notFound and failed
+	}{
+		{name: "empty filter", filter: HistoryFilter{}, want: []bool{true, true, true}},
+		{name: "method ignores case", filter: HistoryFilter{Method: "post"}, want: []bool{true, false, false}},
+		{name: "status code", filter: HistoryFilter{Status: "404"}, want: []bool{false, true, false}},
+		{name: "status class", filter: HistoryFilter{Status: "2XX"}, want: []bool{true, false, false}},
+		{name: "error", filter: HistoryFilter{Status: "error"}, want: []bool{false, false, true}},
+		{name: "URL substring", filter: HistoryFilter{URLContains: "/users"}, want: []bool{true, false, false}},
+		{name: "since at the entry time", filter: HistoryFilter{Since: sent}, want: []bool{true, true, true}},
+		{name: "since after the entry time", filter: HistoryFilter{Since: sent.Add(time.Second)}, want: []bool{false, false, false}},
+		{name: "all fields", filter: HistoryFilter{Method: "GET", Status: "4xx", URLContains: "pets", Since: sent}, want: []bool{false, true, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, entry := range []HistoryEntry{ok, notFound, failed} {
+				if got := tt.filter.Matches(entry); got != tt.want[i] {
+					t.Errorf("Matches(%s %s) = %v, want %v", entry.Method, entry.URL, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateStatusFilter(t *testing.T) {
+	tests := []struct {
+		status  string
+		wantErr bool
+	}{
+		{status: ""},
+		{status: "error"},
+		{status: "ERROR"},
+		{status: "200"},
+		{status: "599"},
+		{status: "5xx"},
+		{status: "4XX"},
+		{status: "600", wantErr: true},
+		{status: "099", wantErr: true},
+		{status: "6xx", wantErr: true},
+		{status: "20", wantErr: true},
+		{status: "2000", wantErr: true},
+		{status: "x5x", wantErr: true},
+		{status: "failed", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			err := ValidateStatusFilter(tt.status)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateStatusFilter(%q) error = %v, want error %v", tt.status, err, tt.wantErr)
+			}
+		})
+	}
+}
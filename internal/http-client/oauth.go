@@ -0,0 +1,364 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+// OAuth 2.0 grants supported by auth profiles
+const (
+	GrantClientCredentials = "client_credentials"
+	GrantPassword          = "password"
+	GrantAuthorizationCode = "authorization_code"
+)
+
+const (
+	// oauthExpirySkew renews tokens this long before they expire, so they don't lapse in flight
+	oauthExpirySkew = 30 * time.Second
+	// oauthLoginTimeout bounds the wait for the browser to come back to the loopback listener
+	oauthLoginTimeout = 5 * time.Minute
+	// oauthCallbackPath is the path of the loopback redirect URI
+	oauthCallbackPath = "/callback"
+)
+
+// oauthTokenResponse is a token endpoint response, successful or not (RFC 6749 sections 5.1 and 5.2)
+type oauthTokenResponse struct {
+	AccessToken      string      `json:"access_token"`
+	TokenType        string      `json:"token_type"`
+	ExpiresIn        json.Number `json:"expires_in"`
+	RefreshToken     string      `json:"refresh_token"`
+	Error            string      `json:"error"`
+	ErrorDescription string      `json:"error_description"`
+}
+
+// ValidateOAuthConfig checks that an OAuth profile has what its grant needs
+func ValidateOAuthConfig(config *model.OAuthConfig) error {
+	if config == nil {
+		return errors.New("OAuth settings are missing")
+	}
+
+	switch config.Grant {
+	case GrantClientCredentials, GrantPassword:
+	case GrantAuthorizationCode:
+		if err := validateEndpoint("authorization URL", config.AuthURL); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported OAuth grant '%s' (use %s, %s or %s)",
+			config.Grant, GrantClientCredentials, GrantPassword, GrantAuthorizationCode)
+	}
+
+	if err := validateEndpoint("token URL", config.TokenURL); err != nil {
+		return err
+	}
+	if config.ClientID == "" {
+		return errors.New("client ID is required")
+	}
+	if config.ClientAuth != "" && config.ClientAuth != "basic" && config.ClientAuth != "body" {
+		return fmt.Errorf("unsupported client authentication '%s' (use basic or body)", config.ClientAuth)
+	}
+	if config.RedirectPort < 0 || config.RedirectPort > 65535 {
+		return fmt.Errorf("redirect port %d is out of range", config.RedirectPort)
+	}
+	return nil
+}
+
+func validateEndpoint(name, endpoint string) error {
+	parsed, err := url.Parse(endpoint)
+	if endpoint == "" || err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%s must be an http or https URL", name)
+	}
+	return nil
+}
+
+// OAuthTokenValid reports whether the cached access token of profile can still be used
+func OAuthTokenValid(profile *model.AuthProfile) bool {
+	if profile.Token == "" {
+		return false
+	}
+	expiry := TokenExpiry(profile)
+	return expiry == nil || time.Now().Add(oauthExpirySkew).Before(*expiry)
+}
+
+// EnsureOAuthToken makes sure an OAuth profile has a valid access token, refreshing or
+// fetching a new one when the cached token is missing or about to expire. New tokens are
+// saved to the profile.
+func EnsureOAuthToken(profile *model.AuthProfile) error {
+	if err := ValidateOAuthConfig(profile.OAuth); err != nil {
+		return fmt.Errorf("auth profile '%s': %w", profile.Name, err)
+	}
+	if OAuthTokenValid(profile) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), RequestTimeout())
+	defer cancel()
+
+	var refreshErr error
+	if profile.OAuth.RefreshToken != "" {
+		if refreshErr = refreshOAuthToken(ctx, profile); refreshErr == nil {
+			return SaveAuthProfile(profile)
+		}
+	}
+
+	if profile.OAuth.Grant == GrantAuthorizationCode {
+		// Logging in again needs a browser, so it can't happen in the middle of a request
+		if refreshErr != nil {
+			return fmt.Errorf("failed to refresh the token of auth profile '%s': %w; log in again with 'apix auth login %s'", profile.Name, refreshErr, profile.Name)
+		}
+		return fmt.Errorf("auth profile '%s' has no valid token; log in with 'apix auth login %s'", profile.Name, profile.Name)
+	}
+
+	if err := LoginOAuth(ctx, profile, nil); err != nil {
+		return err
+	}
+	return SaveAuthProfile(profile)
+}
+
+// LoginOAuth fetches a new access token for profile using its grant. The authorization code
+// grant passes the authorization URL to openURL and waits for the browser to be redirected
+// to a loopback listener. The caller saves the profile.
+func LoginOAuth(ctx context.Context, profile *model.AuthProfile, openURL func(string)) error {
+	config := profile.OAuth
+	if err := ValidateOAuthConfig(config); err != nil {
+		return fmt.Errorf("auth profile '%s': %w", profile.Name, err)
+	}
+
+	form := url.Values{}
+	switch config.Grant {
+	case GrantClientCredentials:
+		form.Set("grant_type", GrantClientCredentials)
+	case GrantPassword:
+		if profile.Username == "" || profile.Password == "" {
+			return fmt.Errorf("username or password is empty in auth profile '%s'", profile.Name)
+		}
+		form.Set("grant_type", GrantPassword)
+		form.Set("username", profile.Username)
+		form.Set("password", profile.Password)
+	case GrantAuthorizationCode:
+		if openURL == nil {
+			return fmt.Errorf("auth profile '%s' needs a browser login", profile.Name)
+		}
+		return loginWithAuthorizationCode(ctx, profile, openURL)
+	}
+	if len(config.Scopes) > 0 {
+		form.Set("scope", strings.Join(config.Scopes, " "))
+	}
+	if config.Audience != "" {
+		form.Set("audience", config.Audience)
+	}
+
+	token, err := requestOAuthToken(ctx, config, form)
+	if err != nil {
+		return fmt.Errorf("failed to fetch a token for auth profile '%s': %w", profile.Name, err)
+	}
+	applyOAuthToken(profile, token)
+	return nil
+}
+
+// refreshOAuthToken exchanges the refresh token of profile for a new access token
+func refreshOAuthToken(ctx context.Context, profile *model.AuthProfile) error {
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", profile.OAuth.RefreshToken)
+
+	token, err := requestOAuthToken(ctx, profile.OAuth, form)
+	if err != nil {
+		return err
+	}
+	applyOAuthToken(profile, token)
+	return nil
+}
+
+// loginWithAuthorizationCode runs the authorization code grant with PKCE (RFC 7636), receiving
+// the code on a loopback redirect URI as described in RFC 8252
+func loginWithAuthorizationCode(ctx context.Context, profile *model.AuthProfile, openURL func(string)) error {
+	config := profile.OAuth
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", config.RedirectPort))
+	if err != nil {
+		return fmt.Errorf("failed to start the redirect listener: %w", err)
+	}
+	redirectURI := fmt.Sprintf("http://127.0.0.1:%d%s", listener.Addr().(*net.TCPAddr).Port, oauthCallbackPath)
+
+	verifier, err := randomURLString(32)
+	if err != nil {
+		listener.Close()
+		return err
+	}
+	state, err := randomURLString(16)
+	if err != nil {
+		listener.Close()
+		return err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	authURL, err := url.Parse(config.AuthURL)
+	if err != nil {
+		listener.Close()
+		return fmt.Errorf("invalid authorization URL: %w", err)
+	}
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", config.ClientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("state", state)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	query.Set("code_challenge_method", "S256")
+	if len(config.Scopes) > 0 {
+		query.Set("scope", strings.Join(config.Scopes, " "))
+	}
+	if config.Audience != "" {
+		query.Set("audience", config.Audience)
+	}
+	authURL.RawQuery = query.Encode()
+
+	type callbackResult struct {
+		code string
+		err  error
+	}
+	results := make(chan callbackResult, 1)
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != oauthCallbackPath {
+			http.NotFound(w, r)
+			return
+		}
+
+		params := r.URL.Query()
+		var result callbackResult
+		switch {
+		case params.Get("state") != state:
+			result.err = errors.New("authorization response has an unexpected state")
+		case params.Get("error") != "":
+			result.err = oauthError(params.Get("error"), params.Get("error_description"))
+		case params.Get("code") == "":
+			result.err = errors.New("authorization response has no code")
+		default:
+			result.code = params.Get("code")
+		}
+
+		if result.err != nil {
+			http.Error(w, "Login failed: "+result.err.Error(), http.StatusBadRequest)
+		} else {
+			fmt.Fprintln(w, "Login complete. You can close this window and return to apix.")
+		}
+		select {
+		case results <- result:
+		default:
+		}
+	})}
+	go server.Serve(listener)
+	defer server.Close()
+
+	openURL(authURL.String())
+
+	ctx, cancel := context.WithTimeout(ctx, oauthLoginTimeout)
+	defer cancel()
+
+	var result callbackResult
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for the login to complete in the browser")
+	case result = <-results:
+	}
+	if result.err != nil {
+		return result.err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", GrantAuthorizationCode)
+	form.Set("code", result.code)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("code_verifier", verifier)
+
+	token, err := requestOAuthToken(ctx, config, form)
+	if err != nil {
+		return fmt.Errorf("failed to exchange the authorization code: %w", err)
+	}
+	applyOAuthToken(profile, token)
+	return nil
+}
+
+// requestOAuthToken posts form to the token endpoint, authenticating the client as configured
+func requestOAuthToken(ctx context.Context, config *model.OAuthConfig, form url.Values) (*oauthTokenResponse, error) {
+	req := NewClient(RequestTimeout()).resty.R().
+		SetContext(ctx).
+		SetHeader("Accept", "application/json")
+
+	if config.ClientSecret != "" && config.ClientAuth != "body" {
+		// Credentials are form-encoded before they go into the Authorization header (RFC 6749 section 2.3.1)
+		req.SetBasicAuth(url.QueryEscape(config.ClientID), url.QueryEscape(config.ClientSecret))
+	} else {
+		form.Set("client_id", config.ClientID)
+		if config.ClientSecret != "" {
+			form.Set("client_secret", config.ClientSecret)
+		}
+	}
+
+	response, err := req.SetFormDataFromValues(form).Post(config.TokenURL)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+
+	var token oauthTokenResponse
+	if err := json.Unmarshal(response.Body(), &token); err != nil {
+		if !response.IsSuccess() {
+			return nil, fmt.Errorf("token endpoint returned %s", response.Status())
+		}
+		return nil, fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if token.Error != "" {
+		return nil, oauthError(token.Error, token.ErrorDescription)
+	}
+	if !response.IsSuccess() {
+		return nil, fmt.Errorf("token endpoint returned %s", response.Status())
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("token response has no access_token")
+	}
+	if token.TokenType != "" && !strings.EqualFold(token.TokenType, "bearer") {
+		return nil, fmt.Errorf("unsupported token type '%s'", token.TokenType)
+	}
+	return &token, nil
+}
+
+// applyOAuthToken caches token in profile. A refresh token is kept when the response doesn't replace it.
+func applyOAuthToken(profile *model.AuthProfile, token *oauthTokenResponse) {
+	profile.Token = token.AccessToken
+	profile.Expiry = nil
+	if seconds, err := token.ExpiresIn.Int64(); err == nil && seconds > 0 {
+		expiry := time.Now().Add(time.Duration(seconds) * time.Second)
+		profile.Expiry = &expiry
+	}
+	if token.RefreshToken != "" {
+		profile.OAuth.RefreshToken = token.RefreshToken
+	}
+}
+
+func oauthError(code, description string) error {
+	if description != "" {
+		return fmt.Errorf("authorization server returned %s: %s", code, description)
+	}
+	return fmt.Errorf("authorization server returned %s", code)
+}
+
+// randomURLString returns n random bytes encoded for use in URLs
+func randomURLString(n int) (string, error) {
+	data := make([]byte, n)
+	if _, err := rand.Read(data); err != nil {
+		return "", fmt.Errorf("failed to generate random value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
@@ -0,0 +1,272 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+// tokenServer is a token endpoint that records the forms it receives
+type tokenServer struct {
+	*httptest.Server
+	requests atomic.Int32
+	forms    chan url.Values
+	respond  func(form url.Values) (int, map[string]any)
+}
+
+func newTokenServer(t *testing.T, respond func(form url.Values) (int, map[string]any)) *tokenServer {
+	server := &tokenServer{forms: make(chan url.Values, 10), respond: respond}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.requests.Add(1)
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if id, secret, ok := r.BasicAuth(); ok {
+			r.PostForm.Set("basic_auth", id+":"+secret)
+		}
+		server.forms <- r.PostForm
+
+		status, body := server.respond(r.PostForm)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func oauthProfile(tokenURL, grant string) *model.AuthProfile {
+	return &model.AuthProfile{
+		Name: "oauth",
+		Type: "oauth",
+		OAuth: &model.OAuthConfig{
+			Grant:        grant,
+			TokenURL:     tokenURL,
+			ClientID:     "client",
+			ClientSecret: "s3cret",
+			Scopes:       []string{"read", "write"},
+		},
+	}
+}
+
+func TestEnsureOAuthTokenFetchesAndCaches(t *testing.T) {
+	useTestConfig(t)
+	useTestProfileKey(t)
+	server := newTokenServer(t, func(form url.Values) (int, map[string]any) {
+		return http.StatusOK, map[string]any{"access_token": "AT1", "token_type": "Bearer", "expires_in": 3600, "refresh_token": "RT1"}
+	})
+
+	profile := oauthProfile(server.URL, GrantClientCredentials)
+	if err := EnsureOAuthToken(profile); err != nil {
+		t.Fatal(err)
+	}
+
+	form := <-server.forms
+	if form.Get("grant_type") != GrantClientCredentials || form.Get("scope") != "read write" || form.Get("basic_auth") != "client:s3cret" {
+		t.Errorf("unexpected token request %v", form)
+	}
+	if profile.Token != "AT1" || profile.OAuth.RefreshToken != "RT1" {
+		t.Errorf("token = %q, refresh token = %q", profile.Token, profile.OAuth.RefreshToken)
+	}
+	if profile.Expiry == nil || time.Until(*profile.Expiry) < 59*time.Minute {
+		t.Errorf("expiry = %v, want about an hour from now", profile.Expiry)
+	}
+
+	saved, err := GetAuthProfile("oauth")
+	if err != nil || saved.Token != "AT1" {
+		t.Fatalf("saved profile = %+v, %v", saved, err)
+	}
+
+	// A valid cached token is used without asking the server again
+	if err := EnsureOAuthToken(saved); err != nil {
+		t.Fatal(err)
+	}
+	if got := server.requests.Load(); got != 1 {
+		t.Errorf("token endpoint was called %d times, want 1", got)
+	}
+}
+
+func TestEnsureOAuthTokenRefreshesExpiredToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		expiry        time.Duration
+		refreshStatus int
+		wantGrants    []string
+		wantRefresh   string
+	}{
+		{
+			name:          "expired",
+			expiry:        -time.Minute,
+			refreshStatus: http.StatusOK,
+			wantGrants:    []string{"refresh_token"},
+			wantRefresh:   "RT1",
+		},
+		{
+			name:          "about to expire",
+			expiry:        10 * time.Second,
+			refreshStatus: http.StatusOK,
+			wantGrants:    []string{"refresh_token"},
+			wantRefresh:   "RT1",
+		},
+		{
+			name:          "refresh token rejected",
+			expiry:        -time.Minute,
+			refreshStatus: http.StatusBadRequest,
+			wantGrants:    []string{"refresh_token", GrantClientCredentials},
+			wantRefresh:   "RT2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestConfig(t)
+			useTestProfileKey(t)
+			server := newTokenServer(t, func(form url.Values) (int, map[string]any) {
+				if form.Get("grant_type") == "refresh_token" {
+					if tt.refreshStatus != http.StatusOK {
+						return tt.refreshStatus, map[string]any{"error": "invalid_grant"}
+					}
+					// No refresh_token in the response: the old one is kept
+					return http.StatusOK, map[string]any{"access_token": "AT2", "expires_in": "3600"}
+				}
+				return http.StatusOK, map[string]any{"access_token": "AT2", "expires_in": 3600, "refresh_token": "RT2"}
+			})
+
+			profile := oauthProfile(server.URL, GrantClientCredentials)
+			expiry := time.Now().Add(tt.expiry)
+			profile.Token, profile.Expiry, profile.OAuth.RefreshToken = "AT1", &expiry, "RT1"
+
+			if err := EnsureOAuthToken(profile); err != nil {
+				t.Fatal(err)
+			}
+
+			var grants []string
+			for len(server.forms) > 0 {
+				form := <-server.forms
+				grants = append(grants, form.Get("grant_type"))
+				if form.Get("grant_type") == "refresh_token" && form.Get("refresh_token") != "RT1" {
+					t.Errorf("refresh_token = %q, want RT1", form.Get("refresh_token"))
+				}
+			}
+			if strings.Join(grants, ",") != strings.Join(tt.wantGrants, ",") {
+				t.Errorf("grants = %v, want %v", grants, tt.wantGrants)
+			}
+			if profile.Token != "AT2" || profile.OAuth.RefreshToken != tt.wantRefresh {
+				t.Errorf("token = %q, refresh token = %q, want AT2 and %s", profile.Token, profile.OAuth.RefreshToken, tt.wantRefresh)
+			}
+		})
+	}
+}
+
+func TestLoginOAuthErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    map[string]any
+		wantErr string
+	}{
+		{name: "error response", status: http.StatusUnauthorized, body: map[string]any{"error": "invalid_client", "error_description": "unknown client"}, wantErr: "invalid_client: unknown client"},
+		{name: "no access token", status: http.StatusOK, body: map[string]any{"token_type": "Bearer"}, wantErr: "no access_token"},
+		{name: "unsupported token type", status: http.StatusOK, body: map[string]any{"access_token": "x", "token_type": "mac"}, wantErr: "unsupported token type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestConfig(t)
+			server := newTokenServer(t, func(url.Values) (int, map[string]any) { return tt.status, tt.body })
+
+			err := LoginOAuth(context.Background(), oauthProfile(server.URL, GrantClientCredentials), nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoginOAuthAuthorizationCodeWithPKCE(t *testing.T) {
+	useTestConfig(t)
+
+	var challenge string
+	server := newTokenServer(t, func(form url.Values) (int, map[string]any) {
+		verifier := form.Get("code_verifier")
+		sum := sha256.Sum256([]byte(verifier))
+		if len(verifier) < 43 || len(verifier) > 128 || base64.RawURLEncoding.EncodeToString(sum[:]) != challenge {
+			return http.StatusBadRequest, map[string]any{"error": "invalid_grant", "error_description": "PKCE verification failed"}
+		}
+		if form.Get("code") != "CODE" {
+			return http.StatusBadRequest, map[string]any{"error": "invalid_grant"}
+		}
+		return http.StatusOK, map[string]any{"access_token": "AT", "token_type": "bearer"}
+	})
+
+	profile := oauthProfile(server.URL+"/token", GrantAuthorizationCode)
+	profile.OAuth.AuthURL = server.URL + "/authorize?prompt=consent"
+
+	openURL := func(rawURL string) {
+		authURL, err := url.Parse(rawURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		query := authURL.Query()
+		if query.Get("code_challenge_method") != "S256" || query.Get("response_type") != "code" ||
+			query.Get("client_id") != "client" || query.Get("prompt") != "consent" {
+			t.Errorf("unexpected authorization URL %s", rawURL)
+		}
+		challenge = query.Get("code_challenge")
+
+		// The browser is redirected back to the loopback listener
+		callback := query.Get("redirect_uri") + "?" + url.Values{"code": {"CODE"}, "state": {query.Get("state")}}.Encode()
+		response, err := http.Get(callback)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+	}
+
+	if err := LoginOAuth(context.Background(), profile, openURL); err != nil {
+		t.Fatal(err)
+	}
+	if profile.Token != "AT" {
+		t.Errorf("token = %q, want AT", profile.Token)
+	}
+
+	form := <-server.forms
+	if form.Get("grant_type") != GrantAuthorizationCode || !strings.HasPrefix(form.Get("redirect_uri"), "http://127.0.0.1:") {
+		t.Errorf("unexpected token request %v", form)
+	}
+}
+
+func TestLoginOAuthRejectsWrongState(t *testing.T) {
+	useTestConfig(t)
+	server := newTokenServer(t, func(url.Values) (int, map[string]any) {
+		return http.StatusOK, map[string]any{"access_token": "AT"}
+	})
+
+	profile := oauthProfile(server.URL, GrantAuthorizationCode)
+	profile.OAuth.AuthURL = server.URL + "/authorize"
+
+	err := LoginOAuth(context.Background(), profile, func(rawURL string) {
+		authURL, _ := url.Parse(rawURL)
+		response, err := http.Get(authURL.Query().Get("redirect_uri") + "?code=CODE&state=forged")
+		if err == nil {
+			response.Body.Close()
+		}
+	})
+	if err == nil || !strings.Contains(err.Error(), "unexpected state") {
+		t.Errorf("err = %v, want an unexpected state error", err)
+	}
+	if server.requests.Load() != 0 {
+		t.Error("the code was exchanged despite the wrong state")
+	}
+}
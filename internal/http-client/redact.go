@@ -0,0 +1,399 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+// textURLPattern finds URLs inside free text such as error messages
+var textURLPattern = regexp.MustCompile(`[A-Za-z][A-Za-z0-9+.\-]*://[^\s"'<>]+`)
+
+// redactor masks the secrets named in Settings.Redaction. Values that reference {{variables}}
+// are kept, since the secret lives in the environment rather than in the request.
+type redactor struct {
+	settings model.RedactionSettings
+}
+
+func newRedactor() redactor {
+	return redactor{settings: Settings.Redaction}
+}
+
+// RedactRequest returns a copy of opts with sensitive auth, headers, cookies, query
+// parameters and body fields replaced by the redaction marker
+func RedactRequest(opts RequestOptions) RequestOptions {
+	r := newRedactor()
+	opts.URL = r.url(opts.URL)
+	opts.Headers = r.headers(opts.Headers)
+	opts.QueryParams = r.query(opts.QueryParams)
+	opts.FormData = r.form(opts.FormData)
+	opts.Cookies = r.all(opts.Cookies)
+	opts.Body = r.body(opts.Body)
+	opts.Auth = r.auth(opts.Auth)
+	return opts
+}
+
+// RedactHistoryEntry redacts the request of entry, the error it failed with and the response
+// headers and body it recorded
+func RedactHistoryEntry(entry HistoryEntry) HistoryEntry {
+	r := newRedactor()
+	entry.Error = r.text(entry.Error, r.secrets(entry.RequestOptions))
+	entry.RequestOptions = RedactRequest(entry.RequestOptions)
+	entry.ResponseHeaders = r.headers(entry.ResponseHeaders)
+	if redacted, ok := r.bodyText(entry.ResponseBody); ok {
+		entry.ResponseBody = redacted
+	}
+	return entry
+}
+
+// RedactTemplate returns a copy of template with its secrets redacted
+func RedactTemplate(template model.Template) model.Template {
+	r := newRedactor()
+	template.URL = r.url(template.URL)
+	template.Headers = r.headers(template.Headers)
+	template.QueryParams = r.query(template.QueryParams)
+	template.FormData = r.form(template.FormData)
+	template.Body = r.body(template.Body)
+	template.Auth = r.auth(template.Auth)
+	return template
+}
+
+// templateForSave applies the template redaction setting to a template about to be written
+func templateForSave(template model.Template) model.Template {
+	if !Settings.Redaction.RedactTemplates {
+		return template
+	}
+	return RedactTemplate(template)
+}
+
+// ErrRedactedValues is returned for requests that still hold the redaction marker, such as
+// templates saved with redaction on, because the marker would be sent in place of the secret
+var ErrRedactedValues = errors.New("request contains redacted values")
+
+// ContainsRedacted reports whether opts holds values replaced by the redaction marker,
+// which would be sent as-is. Auth that refers to a profile is loaded from it instead.
+func ContainsRedacted(opts RequestOptions) bool {
+	if opts.Auth != nil && opts.Auth.Profile != "" {
+		opts.Auth = nil
+	}
+	opts.Context = nil
+	data, err := json.Marshal(opts)
+	if err != nil {
+		return false
+	}
+	marker, _ := json.Marshal(Settings.Redaction.Marker)
+	return strings.Contains(string(data), strings.Trim(string(marker), `"`))
+}
+
+// mask returns the marker, or value itself if it is empty or references variables
+func (r redactor) mask(value string) string {
+	if value == "" || variablePattern.MatchString(value) {
+		return value
+	}
+	return r.settings.Marker
+}
+
+func (r redactor) sensitive(names []string, name string) bool {
+	for _, candidate := range names {
+		if strings.EqualFold(candidate, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactPairs copies pairs, masking the values whose keys are in names
+func (r redactor) redactPairs(pairs map[string]string, names []string) map[string]string {
+	if pairs == nil {
+		return nil
+	}
+
+	redacted := make(map[string]string, len(pairs))
+	for key, value := range pairs {
+		if r.sensitive(names, key) {
+			value = r.mask(value)
+		}
+		redacted[key] = value
+	}
+	return redacted
+}
+
+func (r redactor) headers(headers map[string]string) map[string]string {
+	return r.redactPairs(headers, r.settings.Headers)
+}
+
+func (r redactor) query(params map[string]string) map[string]string {
+	return r.redactPairs(params, r.settings.QueryParams)
+}
+
+// form masks multipart fields named by single-segment body paths, such as "password"
+func (r redactor) form(fields map[string]string) map[string]string {
+	return r.redactPairs(fields, r.formFields())
+}
+
+func (r redactor) formFields() []string {
+	var names []string
+	for _, path := range r.settings.BodyPaths {
+		if !strings.Contains(path, ".") {
+			names = append(names, path)
+		}
+	}
+	return names
+}
+
+// all masks every value, for cookies
+func (r redactor) all(pairs map[string]string) map[string]string {
+	if pairs == nil {
+		return nil
+	}
+
+	redacted := make(map[string]string, len(pairs))
+	for key, value := range pairs {
+		redacted[key] = r.mask(value)
+	}
+	return redacted
+}
+
+// url masks sensitive parameters in the query string of rawURL
+func (r redactor) url(rawURL string) string {
+	base, query, found := strings.Cut(rawURL, "?")
+	if !found {
+		return rawURL
+	}
+
+	params := strings.Split(query, "&")
+	for i, param := range params {
+		key, value, ok := strings.Cut(param, "=")
+		if !ok {
+			continue
+		}
+		if r.sensitive(r.settings.QueryParams, key) {
+			params[i] = key + "=" + r.mask(value)
+		}
+	}
+	return base + "?" + strings.Join(params, "&")
+}
+
+// text masks sensitive query parameters in the URLs found in text, and every occurrence of secrets.
+// Error messages quote the URL that was sent, with variables already substituted.
+func (r redactor) text(text string, secrets []string) string {
+	if text == "" {
+		return text
+	}
+
+	text = textURLPattern.ReplaceAllStringFunc(text, r.url)
+	for _, secret := range secrets {
+		if secret != "" && secret != r.settings.Marker {
+			text = strings.ReplaceAll(text, secret, r.settings.Marker)
+		}
+	}
+	return text
+}
+
+// secrets returns the values in opts that redaction masks, with their variables substituted,
+// so that they can also be masked where they appear as they were sent
+func (r redactor) secrets(opts RequestOptions) []string {
+	var values []string
+	addPairs := func(pairs map[string]string, names []string) {
+		for key, value := range pairs {
+			if r.sensitive(names, key) {
+				values = append(values, value)
+			}
+		}
+	}
+
+	addPairs(opts.Headers, r.settings.Headers)
+	addPairs(opts.QueryParams, r.settings.QueryParams)
+	addPairs(opts.FormData, r.formFields())
+	for _, value := range opts.Cookies {
+		values = append(values, value)
+	}
+	if opts.Auth != nil && opts.Auth.Type != "none" {
+		if opts.Auth.Type == "bearer" {
+			values = append(values, opts.Auth.Primary)
+		} else {
+			values = append(values, opts.Auth.Secondary)
+		}
+		if opts.Auth.AWS != nil {
+			values = append(values, opts.Auth.AWS.SessionToken)
+		}
+	}
+
+	vars, _ := Variables()
+	for i, value := range values {
+		values[i], _ = Interpolate(value, vars)
+	}
+	return values
+}
+
+// auth masks the secret part of auth: the token, API key or password. Usernames and
+// header names are kept. Credentials that match a saved auth profile get a reference to
+// it, so that the request still works when it is sent again.
+func (r redactor) auth(auth *model.Auth) *model.Auth {
+	if auth == nil || auth.Type == "none" {
+		return auth
+	}
+
+	redacted := *auth
+	if auth.Type == "bearer" {
+		redacted.Primary = r.mask(auth.Primary)
+	} else {
+		redacted.Secondary = r.mask(auth.Secondary)
+	}
+	if auth.AWS != nil && r.mask(auth.AWS.SessionToken) != auth.AWS.SessionToken {
+		aws := *auth.AWS
+		aws.SessionToken = r.mask(aws.SessionToken)
+		redacted.AWS = &aws
+	}
+
+	if redacted != *auth && redacted.Profile == "" {
+		redacted.Profile = matchingProfile(*auth)
+	}
+	return &redacted
+}
+
+// matchingProfile returns the name of the auth profile holding the credentials of auth, if any
+func matchingProfile(auth model.Auth) string {
+	profiles, _ := GetAuthProfiles()
+	for _, profile := range profiles {
+		profileAuth, err := ProfileAuth(profile)
+		if err != nil {
+			continue
+		}
+		if profileAuth.Type == auth.Type && profileAuth.Primary == auth.Primary && profileAuth.Secondary == auth.Secondary {
+			return profile.Name
+		}
+	}
+	return ""
+}
+
+// body masks the configured paths in JSON bodies, whether decoded or still text
+func (r redactor) body(body any) any {
+	switch b := body.(type) {
+	case string:
+		if redacted, ok := r.bodyText(b); ok {
+			return redacted
+		}
+		return b
+	case []byte:
+		if redacted, ok := r.bodyText(string(b)); ok {
+			return []byte(redacted)
+		}
+		return b
+	case map[string]any, []any:
+		return r.value(cloneJSON(b), nil)
+	default:
+		return body
+	}
+}
+
+// bodyText redacts a JSON document, reporting false if it isn't JSON or had nothing to redact
+func (r redactor) bodyText(text string) (string, bool) {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return text, false
+	}
+
+	var decoded any
+	if err := json.Unmarshal([]byte(trimmed), &decoded); err != nil {
+		return text, false
+	}
+
+	original, err := json.Marshal(decoded)
+	if err != nil {
+		return text, false
+	}
+
+	// Bodies are re-encoded only when something was masked, keeping their formatting otherwise
+	data, err := json.Marshal(r.value(decoded, nil))
+	if err != nil || string(data) == string(original) {
+		return text, false
+	}
+	return string(data), true
+}
+
+// value walks a decoded JSON value, masking the fields whose path matches a body path.
+// A single-segment path such as "password" matches that key at any depth.
+func (r redactor) value(v any, path []string) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, item := range v {
+			childPath := append(path[:len(path):len(path)], key)
+			if r.matchesBodyPath(childPath) {
+				if s, ok := item.(string); ok {
+					v[key] = r.mask(s)
+				} else if item != nil {
+					v[key] = r.settings.Marker
+				}
+				continue
+			}
+			v[key] = r.value(item, childPath)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = r.value(item, append(path[:len(path):len(path)], strconv.Itoa(i)))
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func (r redactor) matchesBodyPath(path []string) bool {
+	for _, bodyPath := range r.settings.BodyPaths {
+		segments := strings.Split(bodyPath, ".")
+		if len(segments) == 1 {
+			if strings.EqualFold(segments[0], path[len(path)-1]) {
+				return true
+			}
+			continue
+		}
+		if matchSegments(segments, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches a JSON path against a body path. Array indexes may be left out
+// of the body path, so "users.password" matches "users.0.password".
+func matchSegments(segments, path []string) bool {
+	if len(segments) == 0 || len(path) == 0 {
+		return len(segments) == 0 && len(path) == 0
+	}
+
+	if segments[0] == "*" || strings.EqualFold(segments[0], path[0]) {
+		if matchSegments(segments[1:], path[1:]) {
+			return true
+		}
+	}
+	if _, err := strconv.Atoi(path[0]); err == nil {
+		return matchSegments(segments, path[1:])
+	}
+	return false
+}
+
+// cloneJSON deep-copies a decoded JSON value so that redacting it leaves the original intact
+func cloneJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		clone := make(map[string]any, len(v))
+		for key, item := range v {
+			clone[key] = cloneJSON(item)
+		}
+		return clone
+	case []any:
+		clone := make([]any, len(v))
+		for i, item := range v {
+			clone[i] = cloneJSON(item)
+		}
+		return clone
+	default:
+		return v
+	}
+}
@@ -0,0 +1,215 @@
+package httpclient
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+func TestRedactRequest(t *testing.T) {
+	useTestConfig(t)
+
+	opts := RedactRequest(RequestOptions{
+		URL:         "https://api.example.com/items?api_key=abc123&page=2",
+		Headers:     map[string]string{"Authorization": "Bearer xyz", "Accept": "application/json", "X-Api-Key": "{{key}}"},
+		QueryParams: map[string]string{"token": "t0k3n", "q": "search"},
+		FormData:    map[string]string{"password": "hunter2", "user": "ada"},
+		Cookies:     map[string]string{"session": "s3ss"},
+		Body:        map[string]any{"user": "ada", "credentials": map[string]any{"password": "hunter2"}},
+		Auth:        &model.Auth{Type: "basic", Primary: "ada", Secondary: "hunter2"},
+	})
+
+	marker := Settings.Redaction.Marker
+	checks := []struct {
+		name, got, want string
+	}{
+		{name: "url", got: opts.URL, want: "https://api.example.com/items?api_key=" + marker + "&page=2"},
+		{name: "authorization header", got: opts.Headers["Authorization"], want: marker},
+		{name: "other header", got: opts.Headers["Accept"], want: "application/json"},
+		{name: "variable header", got: opts.Headers["X-Api-Key"], want: "{{key}}"},
+		{name: "token query", got: opts.QueryParams["token"], want: marker},
+		{name: "other query", got: opts.QueryParams["q"], want: "search"},
+		{name: "password field", got: opts.FormData["password"], want: marker},
+		{name: "user field", got: opts.FormData["user"], want: "ada"},
+		{name: "cookie", got: opts.Cookies["session"], want: marker},
+		{name: "body password", got: opts.Body.(map[string]any)["credentials"].(map[string]any)["password"].(string), want: marker},
+		{name: "auth user", got: opts.Auth.Primary, want: "ada"},
+		{name: "auth password", got: opts.Auth.Secondary, want: marker},
+	}
+	for _, check := range checks {
+		if check.got != check.want {
+			t.Errorf("%s = %q, want %q", check.name, check.got, check.want)
+		}
+	}
+}
+
+func TestRedactHistoryEntryError(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  RequestOptions
+		vars  map[string]string
+		err   string
+		leaks []string
+	}{
+		{
+			name:  "api key in query",
+			opts:  RequestOptions{URL: "http://127.0.0.1:1/", Auth: &model.Auth{Type: "apikey", Location: "query", Primary: "api_key", Secondary: "SUPERSECRET"}},
+			err:   `Get "http://127.0.0.1:1/?api_key=SUPERSECRET": dial tcp 127.0.0.1:1: connect: connection refused`,
+			leaks: []string{"SUPERSECRET"},
+		},
+		{
+			name:  "custom query name",
+			opts:  RequestOptions{URL: "http://127.0.0.1:1/", Auth: &model.Auth{Type: "apikey", Location: "query", Primary: "k", Secondary: "OTHERSECRET"}},
+			err:   `Get "http://127.0.0.1:1/?k=OTHERSECRET": connection refused`,
+			leaks: []string{"OTHERSECRET"},
+		},
+		{
+			name:  "sensitive query parameter in the URL",
+			opts:  RequestOptions{URL: "http://host/?access_token=abc&page=1"},
+			err:   `Get "http://host/?access_token=abc&page=1": timeout`,
+			leaks: []string{"access_token=abc"},
+		},
+		{
+			name:  "secret from a variable",
+			opts:  RequestOptions{URL: "http://host/", Headers: map[string]string{"X-Auth-Token": "{{token}}"}},
+			vars:  map[string]string{"token": "VARSECRET"},
+			err:   "server sent VARSECRET back",
+			leaks: []string{"VARSECRET"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestConfig(t)
+			OverrideVariables(tt.vars)
+
+			entry := RedactHistoryEntry(HistoryEntry{RequestOptions: tt.opts, Error: tt.err})
+			for _, leak := range tt.leaks {
+				if strings.Contains(entry.Error, leak) {
+					t.Errorf("error %q still contains %q", entry.Error, leak)
+				}
+			}
+			if !strings.Contains(entry.Error, Settings.Redaction.Marker) {
+				t.Errorf("error %q was not redacted", entry.Error)
+			}
+		})
+	}
+}
+
+func TestLegacyHistoryIsRedacted(t *testing.T) {
+	useTestConfig(t)
+
+	legacy, err := json.Marshal([]HistoryEntry{{
+		RequestOptions: RequestOptions{
+			Id:      1,
+			Method:  "GET",
+			URL:     "http://host/?token=LEGACYSECRET",
+			Headers: map[string]string{"Authorization": "Bearer LEGACYSECRET"},
+		},
+		Error: `Get "http://host/?token=LEGACYSECRET": connection refused`,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(ConfigPath, legacyHistoryFile), legacy, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := PruneHistory(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(historyPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "LEGACYSECRET") {
+		t.Errorf("migrated history still contains the secret: %s", data)
+	}
+}
+
+func TestTemplatesKeepSecretsByDefault(t *testing.T) {
+	useTestConfig(t)
+
+	template := model.Template{
+		Name:    "login",
+		Method:  "POST",
+		URL:     "https://api.example.com/login",
+		Headers: map[string]string{"X-API-Key": "k3y"},
+		Auth:    &model.Auth{Type: "bearer", Primary: "t0k3n"},
+	}
+	if err := SaveTemplate(template); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := GetTemplateByName("login")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Headers["X-API-Key"] != "k3y" || saved.Auth.Primary != "t0k3n" {
+		t.Errorf("saved template = %+v, want its secrets kept while redact_templates is off", saved)
+	}
+
+	Settings.Redaction.RedactTemplates = true
+	if err := SaveTemplate(template); err != nil {
+		t.Fatal(err)
+	}
+	if saved, _ := GetTemplateByName("login"); saved.Auth.Primary != Settings.Redaction.Marker {
+		t.Errorf("saved auth = %q with redact_templates on, want the marker", saved.Auth.Primary)
+	}
+}
+
+func TestDoRefusesRedactedValues(t *testing.T) {
+	useTestConfig(t)
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	t.Cleanup(server.Close)
+
+	marker := Settings.Redaction.Marker
+	runtimeVariables["token"] = marker
+
+	tests := []struct {
+		name string
+		opts RequestOptions
+	}{
+		{name: "header", opts: RequestOptions{Headers: map[string]string{"X-API-Key": marker}}},
+		{name: "query", opts: RequestOptions{QueryParams: map[string]string{"token": marker}}},
+		{name: "body", opts: RequestOptions{Body: map[string]any{"password": marker}}},
+		{name: "auth", opts: RequestOptions{Auth: &model.Auth{Type: "basic", Primary: "ada", Secondary: marker}}},
+		{name: "variable value", opts: RequestOptions{Auth: &model.Auth{Type: "bearer", Primary: "{{token}}"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Method, tt.opts.URL = "GET", server.URL
+			_, err := NewClient(time.Second).Do(tt.opts, false)
+			if !errors.Is(err, ErrRedactedValues) {
+				t.Errorf("Do() error = %v, want ErrRedactedValues", err)
+			}
+		})
+	}
+	if hits.Load() != 0 {
+		t.Fatalf("server received %d requests containing the marker", hits.Load())
+	}
+
+	// Auth that refers to a profile is loaded from it, so a leftover marker there is not sent
+	if err := SaveAuthProfile(&model.AuthProfile{Name: "api", Type: "bearer", Token: "t0k3n"}); err != nil {
+		t.Fatal(err)
+	}
+	auth := &model.Auth{Type: "bearer", Primary: marker, Profile: "api"}
+	if _, err := NewClient(time.Second).Do(RequestOptions{Method: "GET", URL: server.URL, Auth: auth}, false); err != nil {
+		t.Errorf("Do() with a profile reference error = %v", err)
+	}
+	if hits.Load() != 1 {
+		t.Errorf("server received %d requests, want 1", hits.Load())
+	}
+}
@@ -0,0 +1,99 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+// Credentials are sent only to the host a request names. When a redirect leaves that host,
+// stripCredentialsOnRedirect removes them unless the auth profile they come from lists the
+// new host. Do passes them to the policy in the request context.
+type credentialsKey struct{}
+
+// requestCredentials records where a request carries its auth credentials
+type requestCredentials struct {
+	hosts   []string // the hosts of the auth profile they come from, if it limits them
+	headers []string
+	query   []string
+	cookies []string
+	// digest auth answers challenges in the transport, so its credentials can't be removed
+	digest bool
+}
+
+// withCredentials returns ctx carrying the credentials of auth for the redirect policy
+func withCredentials(ctx context.Context, auth *model.Auth, hosts []string) context.Context {
+	credentials := requestCredentials{hosts: hosts}
+
+	switch auth.Type {
+	case "bearer", "basic":
+		credentials.headers = []string{"Authorization"}
+	case "digest":
+		credentials.headers = []string{"Authorization"}
+		credentials.digest = true
+	case "aws":
+		credentials.headers = []string{"Authorization", "X-Amz-Security-Token"}
+	case "hmac":
+		credentials.headers = []string{"Authorization"}
+		if auth.HMAC != nil && auth.HMAC.Header != "" {
+			credentials.headers = append(credentials.headers, auth.HMAC.Header)
+		}
+	case "apikey":
+		switch auth.Location {
+		case "query":
+			credentials.query = []string{auth.Primary}
+		case "cookie":
+			credentials.cookies = []string{auth.Primary}
+		default:
+			credentials.headers = []string{auth.Primary}
+		}
+	default:
+		return ctx
+	}
+	return context.WithValue(ctx, credentialsKey{}, &credentials)
+}
+
+// stripCredentialsOnRedirect is the redirect policy that keeps credentials from following
+// a redirect to another host. Each hop is compared with the original request, since the
+// http package copies the original headers onto every redirect.
+var stripCredentialsOnRedirect = resty.RedirectPolicyFunc(func(req *http.Request, via []*http.Request) error {
+	credentials, ok := req.Context().Value(credentialsKey{}).(*requestCredentials)
+	if !ok || len(via) == 0 || strings.EqualFold(req.URL.Host, via[0].URL.Host) {
+		return nil
+	}
+	if len(credentials.hosts) > 0 && ProfileAllowsHost(credentials.hosts, req.URL.String()) {
+		return nil
+	}
+	if credentials.digest {
+		// Stop at the redirect rather than answer the new host's challenge
+		return http.ErrUseLastResponse
+	}
+
+	for _, name := range credentials.headers {
+		req.Header.Del(name)
+	}
+
+	if len(credentials.query) > 0 {
+		query := req.URL.Query()
+		for _, name := range credentials.query {
+			query.Del(name)
+		}
+		req.URL.RawQuery = query.Encode()
+	}
+
+	if len(credentials.cookies) > 0 {
+		cookies := req.Cookies()
+		req.Header.Del("Cookie")
+		for _, cookie := range cookies {
+			if !slices.Contains(credentials.cookies, cookie.Name) {
+				req.AddCookie(cookie)
+			}
+		}
+	}
+	return nil
+})
@@ -0,0 +1,200 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+// recordingServer remembers the last request it received
+type recordingServer struct {
+	*httptest.Server
+	mu   sync.Mutex
+	last *http.Request
+}
+
+func newRecordingServer(t *testing.T) *recordingServer {
+	server := &recordingServer{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.mu.Lock()
+		server.last = r
+		server.mu.Unlock()
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (s *recordingServer) lastRequest() *http.Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last
+}
+
+// redirectServer redirects /elsewhere to target, keeping the query, and /here to its own /final
+func redirectServer(t *testing.T, target string) *recordingServer {
+	server := &recordingServer{}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/elsewhere":
+			http.Redirect(w, r, target+"/final?"+r.URL.RawQuery, http.StatusFound)
+		case "/here":
+			http.Redirect(w, r, "/final?"+r.URL.RawQuery, http.StatusFound)
+		default:
+			server.mu.Lock()
+			server.last = r
+			server.mu.Unlock()
+			w.Write([]byte("ok"))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// sentCredential reports whether r carried the credential of auth
+func sentCredential(r *http.Request, auth *model.Auth) bool {
+	switch {
+	case auth.Type == "apikey" && auth.Location == "query":
+		return r.URL.Query().Get(auth.Primary) != ""
+	case auth.Type == "apikey" && auth.Location == "cookie":
+		_, err := r.Cookie(auth.Primary)
+		return err == nil
+	case auth.Type == "apikey":
+		return r.Header.Get(auth.Primary) != ""
+	default:
+		return r.Header.Get("Authorization") != ""
+	}
+}
+
+func TestRedirectStripsCredentials(t *testing.T) {
+	other := newRecordingServer(t)
+	origin := redirectServer(t, other.URL)
+	originHost := strings.TrimPrefix(origin.URL, "http://")
+	otherHost := strings.TrimPrefix(other.URL, "http://")
+
+	tests := []struct {
+		name     string
+		auth     model.Auth
+		hosts    []string // saved as an auth profile with these hosts when set
+		path     string
+		wantSent bool
+	}{
+		{name: "api key header", auth: model.Auth{Type: "apikey", Primary: "X-API-Key", Secondary: "k"}, path: "/elsewhere"},
+		{name: "api key query", auth: model.Auth{Type: "apikey", Location: "query", Primary: "api_key", Secondary: "k"}, path: "/elsewhere"},
+		{name: "api key cookie", auth: model.Auth{Type: "apikey", Location: "cookie", Primary: "session", Secondary: "k"}, path: "/elsewhere"},
+		{name: "bearer", auth: model.Auth{Type: "bearer", Primary: "t"}, path: "/elsewhere"},
+		{name: "basic", auth: model.Auth{Type: "basic", Primary: "u", Secondary: "p"}, path: "/elsewhere"},
+		{name: "same host", auth: model.Auth{Type: "apikey", Primary: "X-API-Key", Secondary: "k"}, path: "/here", wantSent: true},
+		{name: "unscoped profile", auth: model.Auth{Type: "apikey", Primary: "X-API-Key", Secondary: "k"}, hosts: []string{}, path: "/elsewhere"},
+		{name: "profile scoped to the origin", auth: model.Auth{Type: "apikey", Primary: "X-API-Key", Secondary: "k"}, hosts: []string{originHost}, path: "/elsewhere"},
+		{name: "profile scoped to both hosts", auth: model.Auth{Type: "apikey", Primary: "X-API-Key", Secondary: "k"}, hosts: []string{originHost, otherHost}, path: "/elsewhere", wantSent: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestConfig(t)
+			useTestProfileKey(t)
+
+			auth := tt.auth
+			if tt.hosts != nil {
+				profile := &model.AuthProfile{Name: "scoped", Type: "apikey", Header: auth.Primary, APIKey: auth.Secondary, Hosts: tt.hosts}
+				if err := SaveAuthProfile(profile); err != nil {
+					t.Fatal(err)
+				}
+				auth = model.Auth{Profile: "scoped"}
+			}
+
+			response, err := NewClient(time.Second).Do(RequestOptions{Method: "GET", URL: origin.URL + tt.path, Auth: &auth}, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response.StatusCode() != http.StatusOK {
+				t.Fatalf("status = %s, want the redirect to be followed", response.Status())
+			}
+
+			final := other.lastRequest()
+			if tt.path == "/here" {
+				final = origin.lastRequest()
+			}
+			if final == nil {
+				t.Fatal("the redirect target received no request")
+			}
+			if got := sentCredential(final, &tt.auth); got != tt.wantSent {
+				t.Errorf("credential sent after redirect = %v, want %v", got, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestRedirectKeepsOtherValues(t *testing.T) {
+	useTestConfig(t)
+	other := newRecordingServer(t)
+	origin := redirectServer(t, other.URL)
+
+	_, err := NewClient(time.Second).Do(RequestOptions{
+		Method:      "GET",
+		URL:         origin.URL + "/elsewhere",
+		QueryParams: map[string]string{"page": "2"},
+		Headers:     map[string]string{"X-Trace": "abc"},
+		Cookies:     map[string]string{"theme": "dark"},
+		Auth:        &model.Auth{Type: "apikey", Location: "query", Primary: "api_key", Secondary: "k"},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	final := other.lastRequest()
+	if final.URL.Query().Get("page") != "2" || final.URL.Query().Has("api_key") {
+		t.Errorf("query after redirect = %s, want page kept and api_key removed", final.URL.RawQuery)
+	}
+	if final.Header.Get("X-Trace") != "abc" {
+		t.Errorf("X-Trace header was not kept")
+	}
+}
+
+func TestRedirectStopsForDigestAuth(t *testing.T) {
+	useTestConfig(t)
+	other := newRecordingServer(t)
+	origin := redirectServer(t, other.URL)
+
+	response, err := NewClient(time.Second).Do(RequestOptions{
+		Method: "GET",
+		URL:    origin.URL + "/elsewhere",
+		Auth:   &model.Auth{Type: "digest", Primary: "u", Secondary: "p"},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode() != http.StatusFound || other.lastRequest() != nil {
+		t.Errorf("status = %s, want the redirect to another host returned instead of followed", response.Status())
+	}
+}
+
+func TestAuthProfileNames(t *testing.T) {
+	useTestConfig(t)
+	useTestProfileKey(t)
+
+	for _, name := range []string{"", " ", ".", "..", "../x", "a/b", `a\b`, "/etc/passwd"} {
+		if _, err := GetAuthProfile(name); err == nil || strings.Contains(err.Error(), "not found") {
+			t.Errorf("GetAuthProfile(%q) err = %v, want an invalid name error", name, err)
+		}
+		if err := SaveAuthProfile(&model.AuthProfile{Name: name, Type: "bearer", Token: "t"}); err == nil {
+			t.Errorf("SaveAuthProfile(%q) should fail", name)
+		}
+		if err := DeleteAuthProfile(name); err == nil {
+			t.Errorf("DeleteAuthProfile(%q) should fail", name)
+		}
+	}
+
+	if err := SaveAuthProfile(&model.AuthProfile{Name: "my-api.prod", Type: "bearer", Token: "t"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetAuthProfile("my-api.prod"); err != nil {
+		t.Error(err)
+	}
+}
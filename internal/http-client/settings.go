@@ -0,0 +1,387 @@
+package httpclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+const defaultUserAgent = "Apix/1.0"
+
+// SettingsVersion is the settings file format written by this version of apix.
+// Version 1.0.0 files used Go field names as keys; 1.1.0 switched to snake_case.
+const SettingsVersion = "1.1.0"
+
+// Supported settings file formats
+const (
+	SettingsFormatJSON = "json"
+	SettingsFormatYAML = "yaml"
+)
+
+// Settings holds the active application settings, loaded from disk at startup
+var Settings = DefaultSettings()
+
+// DefaultSettings returns a new copy of the default settings
+func DefaultSettings() *model.GlobalSettings {
+	return &model.GlobalSettings{
+		Display: model.DisplaySettings{
+			ResponseFormat:  "pretty-json",
+			ColorOutput:     true,
+			ShowTiming:      true,
+			ShowHeaders:     false,
+			ShowStatusCode:  true,
+			MaxResponseSize: 1024, // 1MB
+			IndentSize:      2,
+			LineNumbers:     false,
+			SyntaxHighlight: true,
+		},
+		Behavior: model.BehaviorSettings{
+			AutoSaveRequests:       false,
+			ConfirmDeleteRequests:  true,
+			ConfirmDestructive:     true,
+			RequestTimeout:         30,
+			MaxRetries:             3,
+			RetryDelay:             1,
+			FollowRedirects:        true,
+			MaxRedirects:           5,
+			ValidateSSL:            true,
+			CacheResponses:         false,
+			CacheDuration:          10,
+			ShowProgressBar:        true,
+			VerboseMode:            false,
+			SaveFailedRequests:     true,
+			AutoAddHeaders:         true,
+			DefaultContentType:     "application/json",
+			PreserveSessionCookies: true,
+			HistoryBodyLimit:       16,
+			HistoryMaxEntries:      1000,
+			HistoryMaxAge:          90,
+		},
+		Network: model.NetworkSettings{
+			DefaultTimeout:     30,
+			ConnectTimeout:     10,
+			ReadTimeout:        30,
+			WriteTimeout:       30,
+			MaxConnections:     10,
+			UserAgent:          defaultUserAgent,
+			ProxyEnabled:       false,
+			KeepAlive:          true,
+			CompressionEnabled: true,
+		},
+		Logging: model.LoggingSettings{
+			EnableLogging:  false,
+			LogLevel:       "info",
+			LogFile:        "apix.log",
+			LogRequests:    true,
+			LogResponses:   true,
+			LogHeaders:     false,
+			LogTiming:      true,
+			RotateLogFiles: true,
+			MaxLogSize:     10,
+			MaxLogFiles:    5,
+		},
+		Redaction: model.RedactionSettings{
+			RedactHistory:   true,
+			RedactTemplates: false,
+			Marker:          "[REDACTED]",
+			Headers: []string{
+				"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie",
+				"X-API-Key", "Api-Key", "X-Auth-Token", "X-Access-Token",
+			},
+			QueryParams: []string{"api_key", "apikey", "access_token", "token", "client_secret", "password"},
+			BodyPaths:   []string{"password", "client_secret", "access_token", "refresh_token", "id_token"},
+		},
+		Version:   SettingsVersion,
+		LastSaved: time.Now(),
+	}
+}
+
+// settingsPath returns the location of the settings file
+func settingsPath() string {
+	return filepath.Join(ConfigPath, "settings.json")
+}
+
+// LoadSettings reads the settings file into Settings.
+// Values missing from the file keep their defaults.
+func LoadSettings() error {
+	data, err := os.ReadFile(settingsPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read settings file: %w", err)
+	}
+
+	loaded, err := ParseSettings(data, SettingsFormatJSON)
+	if err != nil {
+		return fmt.Errorf("invalid settings file: %w", err)
+	}
+
+	*Settings = *loaded
+	return nil
+}
+
+// SaveSettings writes the given settings to the settings file
+func SaveSettings(settings *model.GlobalSettings) error {
+	if err := os.MkdirAll(ConfigPath, 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	settings.Version = SettingsVersion
+	settings.LastSaved = time.Now()
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal settings: %w", err)
+	}
+
+	if err := os.WriteFile(settingsPath(), data, 0600); err != nil {
+		return fmt.Errorf("failed to write settings file: %w", err)
+	}
+	return nil
+}
+
+// RequestTimeout returns the configured request timeout
+func RequestTimeout() time.Duration {
+	if Settings.Behavior.RequestTimeout <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(Settings.Behavior.RequestTimeout) * time.Second
+}
+
+// SettingsFormatFromPath infers the settings format from a file extension
+func SettingsFormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return SettingsFormatYAML
+	default:
+		return SettingsFormatJSON
+	}
+}
+
+// MarshalSettings encodes settings in the given format
+func MarshalSettings(settings *model.GlobalSettings, format string) ([]byte, error) {
+	exported := *settings
+	exported.Version = SettingsVersion
+
+	switch format {
+	case SettingsFormatJSON:
+		data, err := json.MarshalIndent(exported, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal settings: %w", err)
+		}
+		return append(data, '\n'), nil
+	case SettingsFormatYAML:
+		data, err := yaml.Marshal(exported)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal settings: %w", err)
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unsupported settings format %q (use %s or %s)", format, SettingsFormatJSON, SettingsFormatYAML)
+	}
+}
+
+// ExportSettings writes settings to path. An empty format is inferred from the file extension.
+func ExportSettings(settings *model.GlobalSettings, path, format string) error {
+	if format == "" {
+		format = SettingsFormatFromPath(path)
+	}
+
+	data, err := MarshalSettings(settings, format)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write settings export: %w", err)
+	}
+	return nil
+}
+
+// ImportSettings reads, migrates and validates a settings file exported by ExportSettings.
+// The result is not applied; callers decide whether to replace Settings with it.
+func ImportSettings(path string) (*model.GlobalSettings, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings file: %w", err)
+	}
+
+	return ParseSettings(data, SettingsFormatFromPath(path))
+}
+
+// ParseSettings decodes settings in the given format, migrating older versions
+// to the current layout and validating every field
+func ParseSettings(data []byte, format string) (*model.GlobalSettings, error) {
+	var raw map[string]any
+
+	switch format {
+	case SettingsFormatJSON:
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse settings: %w", err)
+		}
+	case SettingsFormatYAML:
+		var doc any
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse settings: %w", err)
+		}
+		normalized, ok := normalizeYAML(doc).(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("failed to parse settings: expected a mapping at the top level")
+		}
+		raw = normalized
+	default:
+		return nil, fmt.Errorf("unsupported settings format %q (use %s or %s)", format, SettingsFormatJSON, SettingsFormatYAML)
+	}
+
+	if raw == nil {
+		raw = map[string]any{}
+	}
+
+	raw, err := migrateSettings(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	migrated, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse settings: %w", err)
+	}
+
+	settings := DefaultSettings()
+	decoder := json.NewDecoder(bytes.NewReader(migrated))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(settings); err != nil {
+		return nil, fmt.Errorf("failed to parse settings: %w", err)
+	}
+
+	if err := ValidateSettings(settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// settingsMigrations upgrades raw settings from the keyed version to the next one
+var settingsMigrations = []struct {
+	from, to string
+	migrate  func(map[string]any) map[string]any
+}{
+	{from: "1.0.0", to: "1.1.0", migrate: migrateSnakeCaseKeys},
+}
+
+// migrateSettings applies every migration needed to bring raw settings up to SettingsVersion
+func migrateSettings(raw map[string]any) (map[string]any, error) {
+	version := settingsVersion(raw)
+
+	if compareVersions(version, SettingsVersion) > 0 {
+		return nil, fmt.Errorf("settings version %s is newer than the supported version %s", version, SettingsVersion)
+	}
+
+	for _, migration := range settingsMigrations {
+		if compareVersions(version, migration.to) >= 0 {
+			continue
+		}
+		raw = migration.migrate(raw)
+		version = migration.to
+	}
+
+	raw["version"] = SettingsVersion
+	return raw, nil
+}
+
+// settingsVersion returns the version recorded in raw settings. Files without one predate versioning.
+func settingsVersion(raw map[string]any) string {
+	for _, key := range []string{"version", "Version"} {
+		if version, ok := raw[key].(string); ok && version != "" {
+			return version
+		}
+	}
+	return "1.0.0"
+}
+
+// migrateSnakeCaseKeys renames Go field name keys (ValidateSSL) to snake_case (validate_ssl)
+func migrateSnakeCaseKeys(raw map[string]any) map[string]any {
+	migrated := make(map[string]any, len(raw))
+	for key, value := range raw {
+		if nested, ok := value.(map[string]any); ok {
+			value = migrateSnakeCaseKeys(nested)
+		}
+		migrated[toSnakeCase(key)] = value
+	}
+	return migrated
+}
+
+// toSnakeCase converts a Go identifier to snake_case, keeping acronyms together
+func toSnakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				prev := runes[i-1]
+				nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+					b.WriteByte('_')
+				}
+			}
+			b.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// compareVersions compares two dotted version strings numerically
+func compareVersions(a, b string) int {
+	aParts := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bParts := strings.Split(strings.TrimPrefix(b, "v"), ".")
+
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var aNum, bNum int
+		if i < len(aParts) {
+			aNum, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			bNum, _ = strconv.Atoi(bParts[i])
+		}
+		if aNum != bNum {
+			if aNum < bNum {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// normalizeYAML converts the map[interface{}]interface{} values produced by
+// yaml.v2 into map[string]any so they can be re-encoded as JSON
+func normalizeYAML(value any) any {
+	switch v := value.(type) {
+	case map[any]any:
+		normalized := make(map[string]any, len(v))
+		for key, item := range v {
+			normalized[fmt.Sprint(key)] = normalizeYAML(item)
+		}
+		return normalized
+	case []any:
+		for i, item := range v {
+			v[i] = normalizeYAML(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,275 @@
+package httpclient
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+func TestSaveAndLoadSettings(t *testing.T) {
+	useTestConfig(t)
+
+	saved := DefaultSettings()
+	saved.Behavior.MaxRetries = 5
+	saved.Behavior.FollowRedirects = false
+	saved.Network.UserAgent = "apix-test"
+	if err := SaveSettings(saved); err != nil {
+		t.Fatal(err)
+	}
+
+	Settings = DefaultSettings()
+	if err := LoadSettings(); err != nil {
+		t.Fatal(err)
+	}
+	if Settings.Behavior.MaxRetries != 5 || Settings.Behavior.FollowRedirects || Settings.Network.UserAgent != "apix-test" {
+		t.Errorf("loaded settings do not match the saved ones: %+v", Settings.Behavior)
+	}
+}
+
+func TestLoadSettingsWithoutFile(t *testing.T) {
+	useTestConfig(t)
+
+	if err := LoadSettings(); err != nil {
+		t.Fatal(err)
+	}
+	if Settings.Behavior.MaxRetries != DefaultSettings().Behavior.MaxRetries {
+		t.Errorf("missing settings file should keep the defaults")
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	tests := []struct {
+		seconds int
+		want    time.Duration
+	}{
+		{seconds: 10, want: 10 * time.Second},
+		{seconds: 0, want: 30 * time.Second},
+		{seconds: -1, want: 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		useTestConfig(t)
+		Settings.Behavior.RequestTimeout = tt.seconds
+		if got := RequestTimeout(); got != tt.want {
+			t.Errorf("RequestTimeout() with %d seconds = %s, want %s", tt.seconds, got, tt.want)
+		}
+	}
+}
+
+func TestValidateSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*model.GlobalSettings)
+		wantErr string
+	}{
+		{name: "defaults", modify: func(s *model.GlobalSettings) {}},
+		{
+			name:    "unknown response format",
+			modify:  func(s *model.GlobalSettings) { s.Display.ResponseFormat = "xml" },
+			wantErr: "display.response_format",
+		},
+		{
+			name:    "indent too small",
+			modify:  func(s *model.GlobalSettings) { s.Display.IndentSize = 1 },
+			wantErr: "display.indent_size",
+		},
+		{
+			name:    "timeout out of range",
+			modify:  func(s *model.GlobalSettings) { s.Behavior.RequestTimeout = 301 },
+			wantErr: "behavior.request_timeout",
+		},
+		{
+			name:    "too many retries",
+			modify:  func(s *model.GlobalSettings) { s.Behavior.MaxRetries = 11 },
+			wantErr: "behavior.max_retries",
+		},
+		{
+			name:    "empty content type",
+			modify:  func(s *model.GlobalSettings) { s.Behavior.DefaultContentType = " " },
+			wantErr: "behavior.default_content_type",
+		},
+		{
+			name:    "negative connect timeout",
+			modify:  func(s *model.GlobalSettings) { s.Network.ConnectTimeout = -1 },
+			wantErr: "network.connect_timeout",
+		},
+		{
+			name: "relative proxy URL",
+			modify: func(s *model.GlobalSettings) {
+				s.Network.ProxyEnabled = true
+				s.Network.ProxyURL = "proxy:8080"
+			},
+			wantErr: "network.proxy_url",
+		},
+		{
+			name: "proxy URL",
+			modify: func(s *model.GlobalSettings) {
+				s.Network.ProxyEnabled = true
+				s.Network.ProxyURL = "http://proxy:8080"
+			},
+		},
+		{
+			name:    "unknown log level",
+			modify:  func(s *model.GlobalSettings) { s.Logging.LogLevel = "trace" },
+			wantErr: "logging.log_level",
+		},
+		{
+			name: "logging without a file",
+			modify: func(s *model.GlobalSettings) {
+				s.Logging.EnableLogging = true
+				s.Logging.LogFile = ""
+			},
+			wantErr: "logging.log_file",
+		},
+		{
+			name:    "empty body path segment",
+			modify:  func(s *model.GlobalSettings) { s.Redaction.BodyPaths = []string{"auth..token"} },
+			wantErr: "redaction.body_paths",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := DefaultSettings()
+			tt.modify(settings)
+			err := ValidateSettings(settings)
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to mention %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		data    string
+		check   func(*model.GlobalSettings) bool
+		wantErr string
+	}{
+		{
+			name:   "current json",
+			format: SettingsFormatJSON,
+			data:   `{"version":"1.1.0","behavior":{"max_retries":7}}`,
+			check:  func(s *model.GlobalSettings) bool { return s.Behavior.MaxRetries == 7 },
+		},
+		{
+			name:   "current yaml",
+			format: SettingsFormatYAML,
+			data:   "version: 1.1.0\ndisplay:\n  response_format: raw\n",
+			check:  func(s *model.GlobalSettings) bool { return s.Display.ResponseFormat == "raw" },
+		},
+		{
+			name:   "1.0.0 Go field names",
+			format: SettingsFormatJSON,
+			data:   `{"Behavior":{"ValidateSSL":false,"MaxRetries":2},"Network":{"UserAgent":"old"}}`,
+			check: func(s *model.GlobalSettings) bool {
+				return !s.Behavior.ValidateSSL && s.Behavior.MaxRetries == 2 && s.Network.UserAgent == "old"
+			},
+		},
+		{
+			name:    "newer version",
+			format:  SettingsFormatJSON,
+			data:    `{"version":"2.0.0"}`,
+			wantErr: "newer than the supported version",
+		},
+		{
+			name:    "unknown field",
+			format:  SettingsFormatJSON,
+			data:    `{"version":"1.1.0","display":{"font":"mono"}}`,
+			wantErr: "unknown field",
+		},
+		{
+			name:    "invalid value",
+			format:  SettingsFormatYAML,
+			data:    "version: 1.1.0\nlogging:\n  log_level: loud\n",
+			wantErr: "logging.log_level",
+		},
+		{
+			name:    "yaml list",
+			format:  SettingsFormatYAML,
+			data:    "- a\n- b\n",
+			wantErr: "expected a mapping",
+		},
+		{
+			name:    "unsupported format",
+			format:  "toml",
+			data:    `version = "1.1.0"`,
+			wantErr: "unsupported settings format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings, err := ParseSettings([]byte(tt.data), tt.format)
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if settings.Version != SettingsVersion {
+				t.Errorf("version = %s, want %s", settings.Version, SettingsVersion)
+			}
+			if !tt.check(settings) {
+				t.Errorf("parsed settings do not match %s", tt.data)
+			}
+		})
+	}
+}
+
+func TestExportImportSettingsRoundTrip(t *testing.T) {
+	for _, file := range []string{"team.json", "team.yaml"} {
+		t.Run(file, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), file)
+			exported := DefaultSettings()
+			exported.Display.IndentSize = 4
+			exported.Redaction.Headers = append(exported.Redaction.Headers, "X-Session")
+
+			if err := ExportSettings(exported, path, ""); err != nil {
+				t.Fatal(err)
+			}
+			imported, err := ImportSettings(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if imported.Display.IndentSize != 4 || !slices.Contains(imported.Redaction.Headers, "X-Session") {
+				t.Errorf("imported settings do not match the exported ones")
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "1.0.0", b: "1.1.0", want: -1},
+		{a: "1.10.0", b: "1.9.0", want: 1},
+		{a: "v1.1", b: "1.1.0", want: 0},
+		{a: "2", b: "1.9.9", want: 1},
+	}
+
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+// ResponseFormats lists the accepted values for DisplaySettings.ResponseFormat
+var ResponseFormats = []string{"pretty-json", "raw", "headers-only", "compact-json"}
+
+// LogLevels lists the accepted values for LoggingSettings.LogLevel
+var LogLevels = []string{"debug", "info", "warn", "error"}
+
+// ValidateSettings checks every settings field and reports all invalid values at once
+func ValidateSettings(settings *model.GlobalSettings) error {
+	var errs []error
+
+	check := func(ok bool, field, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
+		}
+	}
+	inRange := func(field string, value, min, max int) {
+		check(value >= min && value <= max, field, "must be between %d and %d, got %d", min, max, value)
+	}
+	oneOf := func(field, value string, allowed []string) {
+		check(slices.Contains(allowed, value), field, "must be one of %s, got %q", strings.Join(allowed, ", "), value)
+	}
+
+	display := settings.Display
+	oneOf("display.response_format", display.ResponseFormat, ResponseFormats)
+	inRange("display.max_response_size", display.MaxResponseSize, 0, 10240)
+	inRange("display.indent_size", display.IndentSize, 2, 8)
+
+	behavior := settings.Behavior
+	inRange("behavior.request_timeout", behavior.RequestTimeout, 5, 300)
+	inRange("behavior.max_retries", behavior.MaxRetries, 0, 10)
+	inRange("behavior.retry_delay", behavior.RetryDelay, 1, 30)
+	inRange("behavior.max_redirects", behavior.MaxRedirects, 1, 20)
+	inRange("behavior.cache_duration", behavior.CacheDuration, 1, 60)
+	inRange("behavior.history_body_limit", behavior.HistoryBodyLimit, 0, 1024)
+	inRange("behavior.history_max_entries", behavior.HistoryMaxEntries, 0, 100000)
+	inRange("behavior.history_max_age", behavior.HistoryMaxAge, 0, 3650)
+	check(strings.TrimSpace(behavior.DefaultContentType) != "", "behavior.default_content_type", "must not be empty")
+
+	network := settings.Network
+	check(network.DefaultTimeout >= 0, "network.default_timeout", "must not be negative")
+	check(network.ConnectTimeout >= 0, "network.connect_timeout", "must not be negative")
+	check(network.ReadTimeout >= 0, "network.read_timeout", "must not be negative")
+	check(network.WriteTimeout >= 0, "network.write_timeout", "must not be negative")
+	check(network.MaxConnections >= 0, "network.max_connections", "must not be negative")
+	if network.ProxyEnabled || network.ProxyURL != "" {
+		proxyURL, err := url.Parse(network.ProxyURL)
+		check(err == nil && proxyURL.Scheme != "" && proxyURL.Host != "", "network.proxy_url", "must be an absolute URL, got %q", network.ProxyURL)
+	}
+
+	logging := settings.Logging
+	oneOf("logging.log_level", logging.LogLevel, LogLevels)
+	check(logging.MaxLogSize >= 0, "logging.max_log_size", "must not be negative")
+	check(logging.MaxLogFiles >= 0, "logging.max_log_files", "must not be negative")
+	check(!logging.EnableLogging || logging.LogFile != "", "logging.log_file", "must be set when logging is enabled")
+
+	redaction := settings.Redaction
+	check(strings.TrimSpace(redaction.Marker) != "", "redaction.marker", "must not be empty")
+	check(!slices.Contains(redaction.Headers, ""), "redaction.headers", "must not contain empty names")
+	check(!slices.Contains(redaction.QueryParams, ""), "redaction.query_params", "must not contain empty names")
+	for _, path := range redaction.BodyPaths {
+		check(path != "" && !slices.Contains(strings.Split(path, "."), ""), "redaction.body_paths", "invalid path %q", path)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid settings:\n%w", errors.Join(errs...))
+	}
+	return nil
+}
@@ -0,0 +1,415 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+// Requests signed with "aws" or "hmac" auth are signed in resty's pre-request hook, once the
+// final URL, headers and body are known. Do passes the signer to the hook in the request context.
+type signerKey struct{}
+
+// requestSigner adds a signature to an outgoing request
+type requestSigner func(*http.Request) error
+
+const (
+	// defaultHMACCanonical is the string signed by "hmac" auth when it doesn't set its own
+	defaultHMACCanonical = `{method}\n{path}\n{timestamp}\n{body_sha256}`
+	// defaultHMACFormat is the signature header value when "hmac" auth doesn't set its own
+	defaultHMACFormat = "{signature}"
+)
+
+// defaultHMACSigning is used by "hmac" auth without settings, such as from the --auth flag,
+// and sends the signed timestamp so that the server can check the signature
+var defaultHMACSigning = model.HMACSigning{TimestampHeader: "X-Timestamp"}
+
+// signingPlaceholder matches a {placeholder} in an HMAC canonical string or header format
+var signingPlaceholder = regexp.MustCompile(`\{(header:[^}]+|[a-z0-9_]+)\}`)
+
+// hmacPlaceholders are the placeholders filled in from the request, besides {header:Name}
+var hmacPlaceholders = []string{"method", "url", "host", "path", "query", "timestamp", "date", "nonce", "body", "body_sha256", "body_md5", "key_id"}
+
+// signRequest is the pre-request hook that runs the signer of a request, if it has one
+func signRequest(_ *resty.Client, r *http.Request) error {
+	if sign, ok := r.Context().Value(signerKey{}).(requestSigner); ok {
+		return sign(r)
+	}
+	return nil
+}
+
+// withSigner returns ctx carrying the signer for auth, which must be "aws" or "hmac" auth
+func withSigner(ctx context.Context, auth *model.Auth) (context.Context, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var sign requestSigner
+	switch auth.Type {
+	case "aws":
+		if err := ValidateAWSSigning(auth); err != nil {
+			return nil, err
+		}
+		sign = func(r *http.Request) error { return signAWSV4(r, auth, time.Now()) }
+	case "hmac":
+		if auth.Secondary == "" {
+			return nil, errors.New("HMAC secret is empty")
+		}
+		if err := ValidateHMACSigning(auth.HMAC); err != nil {
+			return nil, err
+		}
+		sign = func(r *http.Request) error { return signHMAC(r, auth, time.Now()) }
+	default:
+		return nil, fmt.Errorf("authentication type '%s' doesn't sign requests", auth.Type)
+	}
+	return context.WithValue(ctx, signerKey{}, sign), nil
+}
+
+// ValidateAWSSigning checks that "aws" auth has the credentials and scope SigV4 needs
+func ValidateAWSSigning(auth *model.Auth) error {
+	if auth.Primary == "" || auth.Secondary == "" {
+		return errors.New("AWS access key ID and secret access key are required")
+	}
+	if auth.AWS == nil || auth.AWS.Region == "" || auth.AWS.Service == "" {
+		return errors.New("AWS region and service are required")
+	}
+	return nil
+}
+
+// ValidateHMACSigning checks the algorithm, encoding and placeholders of HMAC signing settings
+func ValidateHMACSigning(signing *model.HMACSigning) error {
+	if signing == nil {
+		return nil
+	}
+	if _, err := hmacHash(signing.Algorithm); err != nil {
+		return err
+	}
+	switch strings.ToLower(signing.Encoding) {
+	case "", "hex", "base64":
+	default:
+		return fmt.Errorf("unsupported HMAC encoding '%s': use hex or base64", signing.Encoding)
+	}
+
+	if err := checkPlaceholders(signing.Canonical, hmacPlaceholders); err != nil {
+		return fmt.Errorf("invalid canonical string: %w", err)
+	}
+	formatPlaceholders := []string{"signature", "key_id", "timestamp", "nonce", "date"}
+	if err := checkPlaceholders(signing.Format, formatPlaceholders); err != nil {
+		return fmt.Errorf("invalid header format: %w", err)
+	}
+	return nil
+}
+
+func checkPlaceholders(template string, allowed []string) error {
+	for _, match := range signingPlaceholder.FindAllStringSubmatch(template, -1) {
+		name := match[1]
+		if strings.HasPrefix(name, "header:") {
+			continue
+		}
+		known := false
+		for _, placeholder := range allowed {
+			known = known || placeholder == name
+		}
+		if !known {
+			return fmt.Errorf("unknown placeholder {%s}", name)
+		}
+	}
+	return nil
+}
+
+func hmacHash(algorithm string) (func() hash.Hash, error) {
+	switch strings.ToLower(strings.ReplaceAll(algorithm, "-", "")) {
+	case "", "sha256":
+		return sha256.New, nil
+	case "sha1":
+		return sha1.New, nil
+	case "sha512":
+		return sha512.New, nil
+	default:
+		return nil, fmt.Errorf("unsupported HMAC algorithm '%s': use sha256, sha1 or sha512", algorithm)
+	}
+}
+
+// signHMAC signs r by filling in the canonical string template of auth, computing its HMAC
+// with the secret and sending it in the configured header
+func signHMAC(r *http.Request, auth *model.Auth, now time.Time) error {
+	signing := auth.HMAC
+	if signing == nil {
+		signing = &defaultHMACSigning
+	}
+	newHash, err := hmacHash(signing.Algorithm)
+	if err != nil {
+		return err
+	}
+
+	body, err := requestBody(r)
+	if err != nil {
+		return err
+	}
+	nonce, err := randomURLString(16)
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	if signing.TimestampHeader != "" {
+		r.Header.Set(signing.TimestampHeader, timestamp)
+	}
+	if signing.NonceHeader != "" {
+		r.Header.Set(signing.NonceHeader, nonce)
+	}
+
+	bodyMD5 := md5.Sum(body)
+	values := map[string]string{
+		"method":      r.Method,
+		"url":         r.URL.String(),
+		"host":        requestHost(r),
+		"path":        canonicalPath(r.URL.EscapedPath()),
+		"query":       r.URL.RawQuery,
+		"timestamp":   timestamp,
+		"date":        now.UTC().Format(time.RFC3339),
+		"nonce":       nonce,
+		"body":        string(body),
+		"body_sha256": sha256Hex(body),
+		"body_md5":    hex.EncodeToString(bodyMD5[:]),
+		"key_id":      auth.Primary,
+	}
+
+	canonical := signing.Canonical
+	if canonical == "" {
+		canonical = defaultHMACCanonical
+	}
+	mac := hmac.New(newHash, []byte(auth.Secondary))
+	mac.Write([]byte(fillPlaceholders(strings.ReplaceAll(canonical, `\n`, "\n"), values, r.Header)))
+
+	if strings.EqualFold(signing.Encoding, "base64") {
+		values["signature"] = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	} else {
+		values["signature"] = hex.EncodeToString(mac.Sum(nil))
+	}
+
+	header := signing.Header
+	if header == "" {
+		header = "Authorization"
+	}
+	format := signing.Format
+	if format == "" {
+		format = defaultHMACFormat
+	}
+	r.Header.Set(header, fillPlaceholders(format, values, r.Header))
+	return nil
+}
+
+// fillPlaceholders replaces the {placeholders} in template with values and {header:Name} with request headers
+func fillPlaceholders(template string, values map[string]string, headers http.Header) string {
+	return signingPlaceholder.ReplaceAllStringFunc(template, func(match string) string {
+		name := match[1 : len(match)-1]
+		if header, ok := strings.CutPrefix(name, "header:"); ok {
+			return headers.Get(header)
+		}
+		if value, ok := values[name]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// signAWSV4 signs r with AWS Signature Version 4, adding the X-Amz-* and Authorization headers
+func signAWSV4(r *http.Request, auth *model.Auth, now time.Time) error {
+	signature, err := awsV4Sign(r, auth, now)
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Authorization", signature.authorization)
+	return nil
+}
+
+// awsV4Signature holds a SigV4 signature with the intermediate strings it was computed from
+type awsV4Signature struct {
+	canonicalRequest string
+	stringToSign     string
+	authorization    string
+}
+
+// awsV4Sign adds the X-Amz-* headers to r and computes its signature
+func awsV4Sign(r *http.Request, auth *model.Auth, now time.Time) (*awsV4Signature, error) {
+	body, err := requestBody(r)
+	if err != nil {
+		return nil, err
+	}
+
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	r.Header.Set("X-Amz-Date", amzDate)
+	if auth.AWS.Service == "s3" {
+		r.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+	if auth.AWS.SessionToken != "" {
+		r.Header.Set("X-Amz-Security-Token", auth.AWS.SessionToken)
+	}
+
+	// Sign the host, the content type and every x-amz-* header
+	headers := map[string]string{"host": requestHost(r)}
+	for name, values := range r.Header {
+		name = strings.ToLower(name)
+		if name != "content-type" && !strings.HasPrefix(name, "x-amz-") {
+			continue
+		}
+		trimmed := make([]string, len(values))
+		for i, value := range values {
+			trimmed[i] = strings.Join(strings.Fields(value), " ")
+		}
+		headers[name] = strings.Join(trimmed, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	// S3 signs the path as sent; other services encode it once more
+	path := awsURIEncode(r.URL.EscapedPath(), false)
+	if auth.AWS.Service == "s3" {
+		path = awsURIEncode(r.URL.Path, false)
+	}
+
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		canonicalPath(path),
+		awsCanonicalQuery(r.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{day, auth.AWS.Region, auth.AWS.Service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+auth.Secondary), day)
+	for _, part := range []string{auth.AWS.Region, auth.AWS.Service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	return &awsV4Signature{
+		canonicalRequest: canonicalRequest,
+		stringToSign:     stringToSign,
+		authorization: fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+			auth.Primary, scope, signedHeaders, signature),
+	}, nil
+}
+
+// awsCanonicalQuery sorts and encodes query parameters the way SigV4 expects
+func awsCanonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, awsURIEncode(key, true)+"="+awsURIEncode(value, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// awsURIEncode percent-encodes every byte except unreserved characters, and slashes unless encodeSlash is set
+func awsURIEncode(s string, encodeSlash bool) string {
+	var encoded strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			encoded.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			encoded.WriteByte(c)
+		default:
+			fmt.Fprintf(&encoded, "%%%02X", c)
+		}
+	}
+	return encoded.String()
+}
+
+func canonicalPath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+func requestHost(r *http.Request) string {
+	if r.Host != "" {
+		return r.Host
+	}
+	return r.URL.Host
+}
+
+// requestBody returns the body of r, leaving it in place to be sent
+func requestBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if r.GetBody != nil {
+		body, err := r.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body for signing: %w", err)
+		}
+		defer body.Close()
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body for signing: %w", err)
+		}
+		return data, nil
+	}
+
+	data, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body for signing: %w", err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	return data, nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
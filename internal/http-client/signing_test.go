@@ -0,0 +1,350 @@
+package httpclient
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+// The SigV4 cases below are from the AWS Signature Version 4 test suite, which signs
+// every request with these credentials at this time
+const (
+	awsTestAccessKey    = "AKIDEXAMPLE"
+	awsTestSecretKey    = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	awsTestSessionToken = "AQoDYXdzEPT//////////wEXAMPLEtc764bNrC9SAPBSM22wDOk4x4HIZ8j4FZTwdQWLWsKWHGBuFqwAeMicRXmxfpSPfIeoIYRqTflfKD8YUuwthAx7mSEI/qkPpKPi/kMcGdQrmGdeehM4IC1NtBmUpp2wUE8phUZampKsburEDy0KPkyQDYwT7WZ0wq5VSXDvp75YU9HFvlRd8Tx6q6fE8YQcHNVXAkiY9q6d+xo0rKwT38xVqr7ZD0u0iPPkUL64lIZbqBAz+scqKmlzm8FDrypNC9Yjc8fPOLn9FX9KSYvKTr4rvx3iSIlTJabIQwj2ICCR/oLxBA=="
+	emptyPayloadHash    = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+var awsTestTime = time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+func TestAWSV4Sign(t *testing.T) {
+	tests := []struct {
+		name             string
+		method           string
+		url              string
+		contentType      string
+		body             string
+		service          string
+		sessionToken     string
+		canonicalRequest string
+		stringToSign     string
+		authorization    string
+	}{
+		{
+			name:   "get-vanilla",
+			method: "GET",
+			url:    "https://example.amazonaws.com/",
+			canonicalRequest: "GET\n/\n\n" +
+				"host:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\n" +
+				"host;x-amz-date\n" + emptyPayloadHash,
+			stringToSign: "AWS4-HMAC-SHA256\n20150830T123600Z\n20150830/us-east-1/service/aws4_request\n" +
+				"bb579772317eb040ac9ed261061d46c1f17a8133879d6129b6e1c25292927e63",
+			authorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:   "get-vanilla-query-order-key-case",
+			method: "GET",
+			url:    "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			canonicalRequest: "GET\n/\nParam1=value1&Param2=value2\n" +
+				"host:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\n" +
+				"host;x-amz-date\n" + emptyPayloadHash,
+			stringToSign: "AWS4-HMAC-SHA256\n20150830T123600Z\n20150830/us-east-1/service/aws4_request\n" +
+				"816cd5b414d056048ba4f7c5386d6e0533120fb1fcfa93762cf0fc39e2cf19e0",
+			authorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=host;x-amz-date, Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name:   "get-vanilla-query-unreserved",
+			method: "GET",
+			url: "https://example.amazonaws.com/?-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz=" +
+				"-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz",
+			canonicalRequest: "GET\n/\n-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz=" +
+				"-._~0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz\n" +
+				"host:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\n" +
+				"host;x-amz-date\n" + emptyPayloadHash,
+			stringToSign: "AWS4-HMAC-SHA256\n20150830T123600Z\n20150830/us-east-1/service/aws4_request\n" +
+				"c30d4703d9f799439be92736156d47ccfb2d879ddf56f5befa6d1d6aab979177",
+			authorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=host;x-amz-date, Signature=9c3e54bfcdf0b19771a7f523ee5669cdf59bc7cc0884027167c21bb143a40197",
+		},
+		{
+			name:   "post-vanilla",
+			method: "POST",
+			url:    "https://example.amazonaws.com/",
+			canonicalRequest: "POST\n/\n\n" +
+				"host:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\n" +
+				"host;x-amz-date\n" + emptyPayloadHash,
+			stringToSign: "AWS4-HMAC-SHA256\n20150830T123600Z\n20150830/us-east-1/service/aws4_request\n" +
+				"553f88c9e4d10fc9e109e2aeb65f030801b70c2f6468faca261d401ae622fc87",
+			authorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=host;x-amz-date, Signature=5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		{
+			name:        "post-x-www-form-urlencoded",
+			method:      "POST",
+			url:         "https://example.amazonaws.com/",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "Param1=value1",
+			canonicalRequest: "POST\n/\n\n" +
+				"content-type:application/x-www-form-urlencoded\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\n" +
+				"content-type;host;x-amz-date\n9095672bbd1f56dfc5b65f3e153adc8731a4a654192329106275f4c7b24d0b6e",
+			stringToSign: "AWS4-HMAC-SHA256\n20150830T123600Z\n20150830/us-east-1/service/aws4_request\n" +
+				"42a5e5bb34198acb3e84da4f085bb7927f2bc277ca766e6d19c73c2154021281",
+			authorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=content-type;host;x-amz-date, Signature=ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+		{
+			name:        "post-x-www-form-urlencoded-parameters",
+			method:      "POST",
+			url:         "https://example.amazonaws.com/",
+			contentType: "application/x-www-form-urlencoded; charset=utf8",
+			body:        "Param1=value1",
+			canonicalRequest: "POST\n/\n\n" +
+				"content-type:application/x-www-form-urlencoded; charset=utf8\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\n" +
+				"content-type;host;x-amz-date\n9095672bbd1f56dfc5b65f3e153adc8731a4a654192329106275f4c7b24d0b6e",
+			stringToSign: "AWS4-HMAC-SHA256\n20150830T123600Z\n20150830/us-east-1/service/aws4_request\n" +
+				"2e1cf7ed91881a30569e46552437e4156c823447bf1781b921b5d486c568dd1c",
+			authorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=content-type;host;x-amz-date, Signature=1a72ec8f64bd914b0e42e42607c7fbce7fb2c7465f63e3092b3b0d39fa77a6fe",
+		},
+		{
+			name:         "post-sts-header-before",
+			method:       "POST",
+			url:          "https://example.amazonaws.com/",
+			sessionToken: awsTestSessionToken,
+			canonicalRequest: "POST\n/\n\n" +
+				"host:example.amazonaws.com\nx-amz-date:20150830T123600Z\nx-amz-security-token:" + awsTestSessionToken + "\n\n" +
+				"host;x-amz-date;x-amz-security-token\n" + emptyPayloadHash,
+			stringToSign: "AWS4-HMAC-SHA256\n20150830T123600Z\n20150830/us-east-1/service/aws4_request\n" +
+				"c237e1b440d4c63c32ca95b5b99481081cb7b13c7e40434868e71567c1a882f6",
+			authorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+				"SignedHeaders=host;x-amz-date;x-amz-security-token, Signature=85d96828115b5dc0cfc3bd16ad9e210dd772bbebba041836c64533a82be05ead",
+		},
+		{
+			// The example from the AWS documentation on creating a signed request
+			name:        "iam list users",
+			method:      "GET",
+			url:         "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08",
+			contentType: "application/x-www-form-urlencoded; charset=utf-8",
+			service:     "iam",
+			canonicalRequest: "GET\n/\nAction=ListUsers&Version=2010-05-08\n" +
+				"content-type:application/x-www-form-urlencoded; charset=utf-8\nhost:iam.amazonaws.com\nx-amz-date:20150830T123600Z\n\n" +
+				"content-type;host;x-amz-date\n" + emptyPayloadHash,
+			stringToSign: "AWS4-HMAC-SHA256\n20150830T123600Z\n20150830/us-east-1/iam/aws4_request\n" +
+				"f536975d06c0309214f805bb90ccff089219ecd68b2577efef23edd43b7e1a59",
+			authorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, " +
+				"SignedHeaders=content-type;host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			r, err := http.NewRequest(tt.method, tt.url, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			service := tt.service
+			if service == "" {
+				service = "service"
+			}
+			auth := &model.Auth{
+				Type:      "aws",
+				Primary:   awsTestAccessKey,
+				Secondary: awsTestSecretKey,
+				AWS:       &model.AWSSigning{Region: "us-east-1", Service: service, SessionToken: tt.sessionToken},
+			}
+
+			signature, err := awsV4Sign(r, auth, awsTestTime)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if signature.canonicalRequest != tt.canonicalRequest {
+				t.Errorf("canonical request:\n%s\nwant:\n%s", signature.canonicalRequest, tt.canonicalRequest)
+			}
+			if signature.stringToSign != tt.stringToSign {
+				t.Errorf("string to sign:\n%s\nwant:\n%s", signature.stringToSign, tt.stringToSign)
+			}
+			if signature.authorization != tt.authorization {
+				t.Errorf("authorization:\n%s\nwant:\n%s", signature.authorization, tt.authorization)
+			}
+		})
+	}
+}
+
+func TestAWSV4SignPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		service string
+		path    string
+		want    string
+	}{
+		{name: "other services encode the path twice", service: "execute-api", path: "/my%20file", want: "/my%2520file"},
+		{name: "s3 encodes the path once", service: "s3", path: "/my%20file", want: "/my%20file"},
+		{name: "empty path", service: "execute-api", path: "", want: "/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := http.NewRequest("GET", "https://example.amazonaws.com"+tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			auth := &model.Auth{
+				Type:      "aws",
+				Primary:   awsTestAccessKey,
+				Secondary: awsTestSecretKey,
+				AWS:       &model.AWSSigning{Region: "us-east-1", Service: tt.service},
+			}
+			signature, err := awsV4Sign(r, auth, awsTestTime)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Split(signature.canonicalRequest, "\n")[1]; got != tt.want {
+				t.Errorf("canonical path = %q, want %q", got, tt.want)
+			}
+			if hasHash := r.Header.Get("X-Amz-Content-Sha256") != ""; hasHash != (tt.service == "s3") {
+				t.Errorf("X-Amz-Content-Sha256 = %q for service %s", r.Header.Get("X-Amz-Content-Sha256"), tt.service)
+			}
+		})
+	}
+}
+
+func TestSignHMAC(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := `{"a":1}`
+	bodyHash := sha256.Sum256([]byte(body))
+
+	defaultMAC := hmac.New(sha256.New, []byte("secret"))
+	defaultMAC.Write([]byte("POST\n/orders\n1700000000\n" + hex.EncodeToString(bodyHash[:])))
+
+	customMAC := hmac.New(sha512.New, []byte("secret"))
+	customMAC.Write([]byte("POST example.com/orders?id=7 key"))
+
+	tests := []struct {
+		name    string
+		signing *model.HMACSigning
+		header  string
+		want    string
+	}{
+		{
+			name:   "default signing",
+			header: "Authorization",
+			want:   hex.EncodeToString(defaultMAC.Sum(nil)),
+		},
+		{
+			name: "custom canonical string and format",
+			signing: &model.HMACSigning{
+				Algorithm: "sha512",
+				Header:    "X-Signature",
+				Canonical: "{method} {host}{path}?{query} {key_id}",
+				Format:    "HMAC {key_id}:{signature}",
+				Encoding:  "base64",
+			},
+			header: "X-Signature",
+			want:   "HMAC key:" + base64.StdEncoding.EncodeToString(customMAC.Sum(nil)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := http.NewRequest("POST", "https://example.com/orders?id=7", strings.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			auth := &model.Auth{Type: "hmac", Primary: "key", Secondary: "secret", HMAC: tt.signing}
+			if err := signHMAC(r, auth, now); err != nil {
+				t.Fatal(err)
+			}
+			if got := r.Header.Get(tt.header); got != tt.want {
+				t.Errorf("%s = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+// digestParam matches a name=value or name="value" pair of a Digest Authorization header
+var digestParam = regexp.MustCompile(`(\w+)=("([^"]*)"|[^,\s]*)`)
+
+// digestServer answers 200 to requests with a valid RFC 7616 MD5 response for user and
+// password, and a Digest challenge to anything else
+func digestServer(t *testing.T, user, password string) *httptest.Server {
+	const realm, nonce = "apix", "dcd98b7102dd2f0e8b11d0f600bfb0c093"
+	md5Hex := func(s string) string {
+		sum := md5.Sum([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := map[string]string{}
+		if header, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Digest "); ok {
+			for _, match := range digestParam.FindAllStringSubmatch(header, -1) {
+				params[match[1]] = strings.Trim(match[2], `"`)
+			}
+		}
+
+		ha1 := md5Hex(user + ":" + realm + ":" + password)
+		ha2 := md5Hex(r.Method + ":" + params["uri"])
+		want := md5Hex(strings.Join([]string{ha1, nonce, params["nc"], params["cnonce"], "auth", ha2}, ":"))
+		if params["username"] == user && params["nonce"] == nonce && params["qop"] == "auth" &&
+			params["uri"] == r.URL.RequestURI() && params["response"] == want {
+			w.Write([]byte("ok"))
+			return
+		}
+
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Digest realm="%s", qop="auth", nonce="%s", algorithm=MD5`, realm, nonce))
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDigestAuth(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		want     int
+	}{
+		{name: "right password", password: "secret", want: http.StatusOK},
+		{name: "wrong password", password: "guess", want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestConfig(t)
+			server := digestServer(t, "alice", "secret")
+
+			response, err := NewClient(time.Second).Do(RequestOptions{
+				Method: "GET",
+				URL:    server.URL + "/private?page=2",
+				Auth:   &model.Auth{Type: "digest", Primary: "alice", Secondary: tt.password},
+			}, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response.StatusCode() != tt.want {
+				t.Errorf("status = %d, want %d", response.StatusCode(), tt.want)
+			}
+		})
+	}
+}
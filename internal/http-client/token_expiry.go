@@ -0,0 +1,153 @@
+package httpclient
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+
+	"github.com/Esa824/apix/internal/model"
+	"github.com/Esa824/apix/internal/utils"
+)
+
+const (
+	// tokenExpiryWarning is how close to its expiry a profile's token gets before requests warn about it
+	tokenExpiryWarning = 5 * time.Minute
+	// defaultLoginCapture is where a login template's response holds the new token
+	defaultLoginCapture = "body.access_token"
+)
+
+// Warn reports a problem that doesn't stop a request, such as a token about to expire.
+// Interactive mode replaces it to show warnings in its own style.
+var Warn = func(message string) {
+	fmt.Fprintf(os.Stderr, "Warning: %s\n", message)
+}
+
+// JWTExpiry returns the time in the exp claim of token if it is a JWT, or nil otherwise.
+// The signature isn't verified; the claim only tells apix when to stop using the token.
+func JWTExpiry(token string) *time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil
+	}
+	var claims struct {
+		Exp *json.Number `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == nil {
+		return nil
+	}
+
+	seconds, err := claims.Exp.Float64()
+	if err != nil || seconds <= 0 {
+		return nil
+	}
+	expiry := time.Unix(int64(seconds), 0)
+	return &expiry
+}
+
+// TokenExpiry returns when the token of profile expires: its Expiry, or else the exp claim of a JWT token
+func TokenExpiry(profile *model.AuthProfile) *time.Time {
+	if profile.Expiry != nil {
+		return profile.Expiry
+	}
+	return JWTExpiry(profile.Token)
+}
+
+// checkTokenExpiry runs before a profile's token is attached to a request. A missing or expired
+// token is replaced by running the profile's login template if it has one; a token about to
+// expire is renewed the same way, or else reported with Warn.
+func checkTokenExpiry(profile *model.AuthProfile) error {
+	canLogin := profile.LoginTemplate != "" && profile.Type == "bearer"
+	if profile.Token == "" && canLogin {
+		return RunLoginTemplate(profile)
+	}
+
+	expiry := TokenExpiry(profile)
+	if expiry == nil {
+		return nil
+	}
+
+	remaining := time.Until(*expiry)
+	if remaining > tokenExpiryWarning {
+		return nil
+	}
+
+	if !canLogin {
+		if remaining > 0 {
+			Warn(fmt.Sprintf("the token of auth profile '%s' expires in %s", profile.Name, remaining.Round(time.Second)))
+		}
+		// Expired tokens are rejected by ProfileAuth
+		return nil
+	}
+
+	if err := RunLoginTemplate(profile); err != nil {
+		if remaining > 0 {
+			Warn(fmt.Sprintf("the token of auth profile '%s' expires in %s and could not be renewed: %v",
+				profile.Name, remaining.Round(time.Second), err))
+			return nil
+		}
+		return fmt.Errorf("the token of auth profile '%s' has expired and could not be renewed: %w", profile.Name, err)
+	}
+	return nil
+}
+
+// RunLoginTemplate sends the login template of profile and stores the token it returns in the
+// profile, taking the expiry from the token's exp claim or the response's expires_in field
+func RunLoginTemplate(profile *model.AuthProfile) error {
+	if profile.LoginTemplate == "" || profile.Type != "bearer" {
+		return fmt.Errorf("auth profile '%s' has no login template", profile.Name)
+	}
+
+	template, err := GetTemplateByName(profile.LoginTemplate)
+	if err != nil {
+		return err
+	}
+	if template.Auth != nil && template.Auth.Profile == profile.Name {
+		return fmt.Errorf("login template '%s' can't use the auth profile it logs in", template.Name)
+	}
+
+	options := TemplateRequestOptions(*template)
+	if options.Auth == nil {
+		// The active profile may be the one logging in
+		options.Auth = &model.Auth{Type: "none"}
+	}
+	response, err := NewClient(RequestTimeout()).Do(options, false)
+	if err != nil {
+		return fmt.Errorf("login template '%s' failed: %w", template.Name, err)
+	}
+	if response.IsError() {
+		return fmt.Errorf("login template '%s' returned %s", template.Name, response.Status())
+	}
+
+	capture := profile.LoginCapture
+	if capture == "" {
+		capture = defaultLoginCapture
+	}
+	token, err := utils.ExtractCapture(utils.ParseResponse(response), capture)
+	if err != nil {
+		return fmt.Errorf("no token in the response of login template '%s': %w", template.Name, err)
+	}
+	if token == "" {
+		return fmt.Errorf("login template '%s' returned an empty token", template.Name)
+	}
+
+	profile.Token = token
+	profile.Expiry = JWTExpiry(token)
+	if profile.Expiry == nil {
+		if seconds := gjson.GetBytes(response.Body(), "expires_in"); seconds.Type == gjson.Number && seconds.Int() > 0 {
+			expiry := time.Now().Add(time.Duration(seconds.Int()) * time.Second)
+			profile.Expiry = &expiry
+		}
+	}
+
+	return SaveAuthProfile(profile)
+}
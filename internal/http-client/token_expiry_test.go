@@ -0,0 +1,245 @@
+package httpclient
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+// testJWT returns an unsigned JWT with the given payload
+func testJWT(payload string) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	return encode([]byte(`{"alg":"none"}`)) + "." + encode([]byte(payload)) + ".sig"
+}
+
+func TestJWTExpiry(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		want  int64 // Unix seconds, 0 for no expiry
+	}{
+		{name: "expired", token: testJWT(`{"sub":"ada","exp":1500000000}`), want: 1500000000},
+		{name: "future", token: testJWT(`{"exp":4102444800}`), want: 4102444800},
+		{name: "fractional exp", token: testJWT(`{"exp":1700000000.5}`), want: 1700000000},
+		{name: "padded payload", token: strings.Replace(testJWT(`{"exp":1700000000}`), ".sig", "", 1) + "==.sig", want: 1700000000},
+		{name: "no exp", token: testJWT(`{"sub":"ada"}`)},
+		{name: "zero exp", token: testJWT(`{"exp":0}`)},
+		{name: "string exp", token: testJWT(`{"exp":"tomorrow"}`)},
+		{name: "payload not JSON", token: testJWT(`not json`)},
+		{name: "payload not base64", token: "header.!!!.sig"},
+		{name: "two parts", token: "header.payload"},
+		{name: "opaque token", token: "d2f8a9c1e4b7"},
+		{name: "empty", token: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := JWTExpiry(tt.token)
+			if tt.want == 0 {
+				if got != nil {
+					t.Errorf("JWTExpiry() = %v, want nil", got)
+				}
+				return
+			}
+			if got == nil || got.Unix() != tt.want {
+				t.Errorf("JWTExpiry() = %v, want %v", got, time.Unix(tt.want, 0))
+			}
+		})
+	}
+}
+
+func TestTokenExpiryPrefersProfileExpiry(t *testing.T) {
+	expiry := time.Unix(1600000000, 0)
+	profile := &model.AuthProfile{Token: testJWT(`{"exp":1500000000}`), Expiry: &expiry}
+	if got := TokenExpiry(profile); got == nil || !got.Equal(expiry) {
+		t.Errorf("TokenExpiry() = %v, want %v", got, expiry)
+	}
+}
+
+// loginServer issues a new token on /login and serves /data only with an issued or already accepted token
+func loginServer(t *testing.T, loginBody func() string, accepted ...string) (*httptest.Server, *atomic.Int32) {
+	var logins atomic.Int32
+	var issued atomic.Value
+	issued.Store("")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			logins.Add(1)
+			body := loginBody()
+			issued.Store(body)
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, body)
+		case "/data":
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if token == "" || !slices.Contains(accepted, token) && !strings.Contains(issued.Load().(string), token) {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"ok":true}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &logins
+}
+
+func TestLoginTemplateRenewsToken(t *testing.T) {
+	freshJWT := testJWT(fmt.Sprintf(`{"exp":%d}`, time.Now().Add(time.Hour).Unix()))
+
+	tests := []struct {
+		name       string
+		token      string
+		loginBody  string
+		capture    string
+		wantToken  string
+		wantLogins int32
+	}{
+		{
+			name:       "expired JWT",
+			token:      testJWT(`{"exp":1500000000}`),
+			loginBody:  fmt.Sprintf(`{"access_token":%q}`, freshJWT),
+			wantToken:  freshJWT,
+			wantLogins: 1,
+		},
+		{
+			name:       "JWT about to expire",
+			token:      testJWT(fmt.Sprintf(`{"exp":%d}`, time.Now().Add(time.Minute).Unix())),
+			loginBody:  fmt.Sprintf(`{"access_token":%q}`, freshJWT),
+			wantToken:  freshJWT,
+			wantLogins: 1,
+		},
+		{
+			name:       "no token yet, custom capture and expires_in",
+			loginBody:  `{"data":{"token":"opaque-token"},"expires_in":600}`,
+			capture:    "body.data.token",
+			wantToken:  "opaque-token",
+			wantLogins: 1,
+		},
+		{
+			name:       "valid token",
+			token:      freshJWT,
+			loginBody:  `{"access_token":"unused"}`,
+			wantToken:  freshJWT,
+			wantLogins: 0,
+		},
+		{
+			name:       "token without exp",
+			token:      "opaque-token",
+			loginBody:  `{"access_token":"unused"}`,
+			wantToken:  "opaque-token",
+			wantLogins: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestConfig(t)
+			useTestProfileKey(t)
+			server, logins := loginServer(t, func() string { return tt.loginBody }, tt.token)
+
+			if err := SaveTemplate(model.Template{Name: "login", Method: "POST", URL: server.URL + "/login"}); err != nil {
+				t.Fatal(err)
+			}
+			profile := &model.AuthProfile{Name: "api", Type: "bearer", Token: tt.token, LoginTemplate: "login", LoginCapture: tt.capture}
+			if err := SaveAuthProfile(profile); err != nil {
+				t.Fatal(err)
+			}
+
+			response, err := NewClient(time.Second).Do(RequestOptions{
+				Method: "GET",
+				URL:    server.URL + "/data",
+				Auth:   &model.Auth{Profile: "api"},
+			}, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response.StatusCode() != http.StatusOK {
+				t.Errorf("request returned %s", response.Status())
+			}
+			if got := logins.Load(); got != tt.wantLogins {
+				t.Errorf("login template ran %d times, want %d", got, tt.wantLogins)
+			}
+
+			saved, err := GetAuthProfile("api")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if saved.Token != tt.wantToken {
+				t.Errorf("saved token = %q, want %q", saved.Token, tt.wantToken)
+			}
+			if tt.wantLogins > 0 && (saved.Expiry == nil || time.Until(*saved.Expiry) <= 0) {
+				t.Errorf("saved expiry = %v, want a time in the future", saved.Expiry)
+			}
+		})
+	}
+}
+
+func TestExpiredTokenWithoutLoginTemplate(t *testing.T) {
+	useTestConfig(t)
+	useTestProfileKey(t)
+
+	var warnings []string
+	oldWarn := Warn
+	t.Cleanup(func() { Warn = oldWarn })
+	Warn = func(message string) { warnings = append(warnings, message) }
+
+	expired := &model.AuthProfile{Name: "expired", Type: "bearer", Token: testJWT(`{"exp":1500000000}`)}
+	if err := checkTokenExpiry(expired); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ProfileAuth(expired); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("ProfileAuth() err = %v, want an expiry error", err)
+	}
+
+	soon := &model.AuthProfile{Name: "soon", Type: "bearer", Token: testJWT(fmt.Sprintf(`{"exp":%d}`, time.Now().Add(time.Minute).Unix()))}
+	if err := checkTokenExpiry(soon); err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "'soon' expires in") {
+		t.Errorf("warnings = %v, want one about profile 'soon'", warnings)
+	}
+}
+
+func TestRunLoginTemplateErrors(t *testing.T) {
+	useTestConfig(t)
+	useTestProfileKey(t)
+	server, _ := loginServer(t, func() string { return `{"token":"x"}` })
+
+	for _, template := range []model.Template{
+		{Name: "no-token", Method: "POST", URL: server.URL + "/login"},
+		{Name: "not-found", Method: "POST", URL: server.URL + "/missing"},
+		{Name: "self", Method: "POST", URL: server.URL + "/login", Auth: &model.Auth{Profile: "api"}},
+	} {
+		if err := SaveTemplate(template); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		template string
+		wantErr  string
+	}{
+		{template: "no-token", wantErr: "no token in the response"},
+		{template: "not-found", wantErr: "returned 404"},
+		{template: "self", wantErr: "can't use the auth profile it logs in"},
+		{template: "unknown", wantErr: "unknown"},
+	}
+
+	for _, tt := range tests {
+		profile := &model.AuthProfile{Name: "api", Type: "bearer", LoginTemplate: tt.template}
+		err := RunLoginTemplate(profile)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: err = %v, want it to contain %q", tt.template, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// variablePattern matches {{name}} placeholders, allowing spaces inside the braces
+var variablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.\-]+)\s*\}\}`)
+
+// UndefinedVariablesError is returned when a request references variables that have no value
+type UndefinedVariablesError struct {
+	Names []string
+}
+
+func (e *UndefinedVariablesError) Error() string {
+	return fmt.Sprintf("undefined variables: %s", strings.Join(e.Names, ", "))
+}
+
+// runtimeVariables holds values captured from responses or set on the command line during this run
+var runtimeVariables = make(map[string]string)
+
+// Variables returns the variables available to requests: the active environment
+// overlaid with values captured during this run
+func Variables() (map[string]string, error) {
+	environment, err := GetActiveEnvironment()
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make(map[string]string)
+	if environment != nil {
+		maps.Copy(vars, environment.Variables)
+	}
+	maps.Copy(vars, runtimeVariables)
+	return vars, nil
+}
+
+// ErrNoActiveEnvironment is returned by SetVariables when no environment is active, so the
+// values are only available for the rest of this run
+var ErrNoActiveEnvironment = errors.New("no active environment, captured values are kept for this run only (activate one with 'apix env use <name>')")
+
+// SetVariables stores values in the active variable scope. They are available for the rest
+// of this run and, when an environment is active, saved to its file as plain text for later
+// runs. Without an active environment it returns ErrNoActiveEnvironment.
+func SetVariables(values map[string]string) error {
+	maps.Copy(runtimeVariables, values)
+
+	environment, err := GetActiveEnvironment()
+	if err != nil {
+		return err
+	}
+	if environment == nil {
+		return ErrNoActiveEnvironment
+	}
+
+	if environment.Variables == nil {
+		environment.Variables = make(map[string]string)
+	}
+	maps.Copy(environment.Variables, values)
+	return SaveEnvironment(*environment)
+}
+
+// OverrideVariables sets values for the rest of this run only, taking precedence over the
+// active environment without being saved to it
+func OverrideVariables(values map[string]string) {
+	maps.Copy(runtimeVariables, values)
+}
+
+// Interpolate replaces {{name}} placeholders in s and returns the names that had no value
+func Interpolate(s string, vars map[string]string) (string, []string) {
+	var missing []string
+	result := variablePattern.ReplaceAllStringFunc(s, func(match string) string {
+		name := variablePattern.FindStringSubmatch(match)[1]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		missing = append(missing, name)
+		return match
+	})
+	return result, missing
+}
+
+// ResolveVariables returns a copy of opts with placeholders in the URL, headers,
+// query parameters, files, form data, cookies, body and auth replaced by variable values
+func ResolveVariables(opts RequestOptions) (RequestOptions, error) {
+	vars, err := Variables()
+	if err != nil {
+		return opts, fmt.Errorf("failed to load variables: %w", err)
+	}
+	return ApplyVariables(opts, vars)
+}
+
+// ApplyVariables substitutes vars into opts, failing if any placeholder is undefined
+func ApplyVariables(opts RequestOptions, vars map[string]string) (RequestOptions, error) {
+	r := &variableResolver{vars: vars, missing: make(map[string]bool)}
+
+	opts.URL = r.string(opts.URL)
+	opts.Headers = r.stringMap(opts.Headers)
+	opts.QueryParams = r.stringMap(opts.QueryParams)
+	opts.Files = r.stringMap(opts.Files)
+	opts.FormData = r.stringMap(opts.FormData)
+	opts.Cookies = r.stringMap(opts.Cookies)
+	opts.Body = r.value(opts.Body)
+
+	if opts.Auth != nil {
+		auth := *opts.Auth
+		auth.Primary = r.string(auth.Primary)
+		auth.Secondary = r.string(auth.Secondary)
+		if auth.AWS != nil {
+			aws := *auth.AWS
+			aws.Region = r.string(aws.Region)
+			aws.Service = r.string(aws.Service)
+			aws.SessionToken = r.string(aws.SessionToken)
+			auth.AWS = &aws
+		}
+		opts.Auth = &auth
+	}
+
+	if len(r.missing) > 0 {
+		return opts, &UndefinedVariablesError{Names: slices.Sorted(maps.Keys(r.missing))}
+	}
+	return opts, nil
+}
+
+type variableResolver struct {
+	vars    map[string]string
+	missing map[string]bool
+}
+
+func (r *variableResolver) string(s string) string {
+	result, missing := Interpolate(s, r.vars)
+	for _, name := range missing {
+		r.missing[name] = true
+	}
+	return result
+}
+
+func (r *variableResolver) stringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+
+	resolved := make(map[string]string, len(m))
+	for key, value := range m {
+		resolved[r.string(key)] = r.string(value)
+	}
+	return resolved
+}
+
+// value resolves placeholders in string bodies and in the strings of decoded JSON bodies
+func (r *variableResolver) value(v any) any {
+	switch v := v.(type) {
+	case string:
+		return r.string(v)
+	case []byte:
+		return []byte(r.string(string(v)))
+	case map[string]string:
+		return r.stringMap(v)
+	case map[string]any:
+		resolved := make(map[string]any, len(v))
+		for key, item := range v {
+			resolved[r.string(key)] = r.value(item)
+		}
+		return resolved
+	case []any:
+		resolved := make([]any, len(v))
+		for i, item := range v {
+			resolved[i] = r.value(item)
+		}
+		return resolved
+	default:
+		return v
+	}
+}
@@ -0,0 +1,219 @@
+package httpclient
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+func TestInterpolate(t *testing.T) {
+	vars := map[string]string{"host": "api.example.com", "api.version": "v2", "empty": ""}
+
+	tests := []struct {
+		input   string
+		want    string
+		missing []string
+	}{
+		{input: "https://{{host}}/users", want: "https://api.example.com/users"},
+		{input: "https://{{ host }}/{{api.version}}", want: "https://api.example.com/v2"},
+		{input: "a{{empty}}b", want: "ab"},
+		{input: "{{token}} and {{host}}", want: "{{token}} and api.example.com", missing: []string{"token"}},
+		{input: "{{not valid}}", want: "{{not valid}}"},
+		{input: "no placeholders", want: "no placeholders"},
+	}
+
+	for _, tt := range tests {
+		got, missing := Interpolate(tt.input, vars)
+		if got != tt.want || !reflect.DeepEqual(missing, tt.missing) {
+			t.Errorf("Interpolate(%q) = %q, %v, want %q, %v", tt.input, got, missing, tt.want, tt.missing)
+		}
+	}
+}
+
+func TestApplyVariables(t *testing.T) {
+	vars := map[string]string{"base": "http://localhost", "token": "secret", "id": "42", "key": "X-Trace"}
+
+	opts := RequestOptions{
+		URL:         "{{base}}/items/{{id}}",
+		Headers:     map[string]string{"{{key}}": "{{id}}"},
+		QueryParams: map[string]string{"q": "{{id}}"},
+		Cookies:     map[string]string{"session": "{{token}}"},
+		Body:        map[string]any{"id": "{{id}}", "tags": []any{"{{token}}", 1.5}},
+		Auth:        &model.Auth{Type: "bearer", Primary: "{{token}}"},
+	}
+
+	resolved, err := ApplyVariables(opts, vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resolved.URL != "http://localhost/items/42" {
+		t.Errorf("URL = %q", resolved.URL)
+	}
+	if resolved.Headers["X-Trace"] != "42" || resolved.QueryParams["q"] != "42" || resolved.Cookies["session"] != "secret" {
+		t.Errorf("maps not resolved: %v %v %v", resolved.Headers, resolved.QueryParams, resolved.Cookies)
+	}
+	wantBody := map[string]any{"id": "42", "tags": []any{"secret", 1.5}}
+	if !reflect.DeepEqual(resolved.Body, wantBody) {
+		t.Errorf("Body = %v, want %v", resolved.Body, wantBody)
+	}
+	if resolved.Auth.Primary != "secret" {
+		t.Errorf("Auth.Primary = %q", resolved.Auth.Primary)
+	}
+	if opts.Auth.Primary != "{{token}}" || opts.Headers["{{key}}"] != "{{id}}" {
+		t.Errorf("ApplyVariables modified the original options")
+	}
+}
+
+func TestApplyVariablesUndefined(t *testing.T) {
+	opts := RequestOptions{
+		URL:     "{{base}}/items",
+		Headers: map[string]string{"Authorization": "Bearer {{token}}"},
+		Body:    "{{base}}",
+	}
+
+	_, err := ApplyVariables(opts, map[string]string{})
+	var undefined *UndefinedVariablesError
+	if !errors.As(err, &undefined) {
+		t.Fatalf("error = %v, want *UndefinedVariablesError", err)
+	}
+	if !reflect.DeepEqual(undefined.Names, []string{"base", "token"}) {
+		t.Errorf("Names = %v, want each undefined name once, sorted", undefined.Names)
+	}
+}
+
+func TestVariablesPrecedence(t *testing.T) {
+	tests := []struct {
+		name     string
+		active   string
+		override string
+		runtime  map[string]string
+		want     map[string]string
+	}{
+		{
+			name: "no environment",
+			want: map[string]string{},
+		},
+		{
+			name:   "active environment",
+			active: "dev",
+			want:   map[string]string{"host": "dev.local", "user": "dev"},
+		},
+		{
+			name:     "--env overrides the active environment",
+			active:   "dev",
+			override: "prod",
+			want:     map[string]string{"host": "prod.example.com"},
+		},
+		{
+			name:    "runtime values override the environment",
+			active:  "dev",
+			runtime: map[string]string{"host": "cli.local"},
+			want:    map[string]string{"host": "cli.local", "user": "dev"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTestConfig(t)
+			for _, environment := range []model.Environment{
+				{Name: "dev", Variables: map[string]string{"host": "dev.local", "user": "dev"}},
+				{Name: "prod", Variables: map[string]string{"host": "prod.example.com"}},
+			} {
+				if err := SaveEnvironment(environment); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.active != "" {
+				if err := SetActiveEnvironment(tt.active); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := UseEnvironment(tt.override); err != nil {
+				t.Fatal(err)
+			}
+			OverrideVariables(tt.runtime)
+
+			got, err := Variables()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Variables() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetVariablesSavesToActiveEnvironment(t *testing.T) {
+	useTestConfig(t)
+	if err := SaveEnvironment(model.Environment{Name: "dev", Active: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetVariables(map[string]string{"token": "abc"}); err != nil {
+		t.Fatal(err)
+	}
+
+	environment, err := GetEnvironment("dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if environment.Variables["token"] != "abc" {
+		t.Errorf("token was not saved to the active environment: %v", environment.Variables)
+	}
+}
+
+func TestSetVariablesWithoutActiveEnvironment(t *testing.T) {
+	useTestConfig(t)
+
+	err := SetVariables(map[string]string{"token": "abc"})
+	if !errors.Is(err, ErrNoActiveEnvironment) {
+		t.Fatalf("SetVariables() error = %v, want ErrNoActiveEnvironment", err)
+	}
+
+	vars, err := Variables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vars["token"] != "abc" {
+		t.Errorf("token is not available for the rest of the run: %v", vars)
+	}
+}
+
+func TestEnvironmentNotFound(t *testing.T) {
+	useTestConfig(t)
+
+	if err := UseEnvironment("missing"); err == nil {
+		t.Error("UseEnvironment accepted an unknown environment")
+	}
+	if err := SetActiveEnvironment("missing"); err == nil {
+		t.Error("SetActiveEnvironment accepted an unknown environment")
+	}
+	if err := DeleteEnvironment("missing"); err == nil {
+		t.Error("DeleteEnvironment accepted an unknown environment")
+	}
+}
+
+func TestEnvironmentNamesStayInEnvironmentsDir(t *testing.T) {
+	for _, name := range []string{"", " ", ".", "..", "../../x", "../settings", `..\x`, "a/b"} {
+		t.Run(name, func(t *testing.T) {
+			useTestConfig(t)
+			writeTestFile(t, filepath.Join(ConfigPath, "settings.json"), `{"version":"1.1.0"}`)
+
+			if err := SaveEnvironment(model.Environment{Name: name, Variables: map[string]string{"k": "v"}}); err == nil {
+				t.Errorf("SaveEnvironment accepted %q", name)
+			}
+			if err := DeleteEnvironment(name); err == nil {
+				t.Errorf("DeleteEnvironment accepted %q", name)
+			}
+			if _, err := os.Stat(filepath.Join(ConfigPath, "settings.json")); err != nil {
+				t.Errorf("a file outside the environments directory was removed: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+package model
+
+// Assertion is a check evaluated against the response of a template
+type Assertion struct {
+	Type     string `json:"type"`             // "status", "header", "json", "response_time", "body"
+	Target   string `json:"target,omitempty"` // header name for "header", gjson path for "json"
+	Operator string `json:"operator"`         // "equals", "in", "exists", "matches", "contains", "less_than"
+	Value    string `json:"value,omitempty"`
+}
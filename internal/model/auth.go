@@ -1,7 +1,31 @@
 package model
 
 type Auth struct {
-	Type      string // "bearer", "apikey", "basic"
-	Primary   string // token, api key, or username
-	Secondary string // password if needed
+	Type      string // "bearer", "apikey", "basic", "digest", "aws" or "hmac"
+	Primary   string // token, api key, username, AWS access key ID or HMAC key ID
+	Secondary string // password, AWS secret access key or HMAC secret
+	Location  string `json:",omitempty"` // where "apikey" auth sends the key: "header" (default), "query" or "cookie"
+	Profile   string `json:",omitempty"` // auth profile that supplies the credentials when they are left empty
+
+	AWS  *AWSSigning  `json:",omitempty"` // where "aws" requests are sent
+	HMAC *HMACSigning `json:",omitempty"` // how "hmac" requests are signed
+}
+
+// AWSSigning holds the AWS Signature Version 4 settings besides the access key
+type AWSSigning struct {
+	Region       string `json:"region"`
+	Service      string `json:"service"`                 // e.g. execute-api or s3
+	SessionToken string `json:"session_token,omitempty"` // for temporary credentials
+}
+
+// HMACSigning describes a custom HMAC request signature. Canonical and Format are templates
+// whose {placeholders} are filled in from the request; see the README for the list.
+type HMACSigning struct {
+	Algorithm       string `json:"algorithm,omitempty"`        // sha256 (default), sha1 or sha512
+	Header          string `json:"header,omitempty"`           // header the signature is sent in, default Authorization
+	Canonical       string `json:"canonical,omitempty"`        // string to sign
+	Format          string `json:"format,omitempty"`           // header value, default {signature}
+	Encoding        string `json:"encoding,omitempty"`         // hex (default) or base64
+	TimestampHeader string `json:"timestamp_header,omitempty"` // header that sends the signed {timestamp}, if any
+	NonceHeader     string `json:"nonce_header,omitempty"`     // header that sends the signed {nonce}, if any
 }
@@ -4,13 +4,42 @@ import "time"
 
 // AuthProfile represents an authentication profile
 type AuthProfile struct {
-	Name     string     `json:"name"`
-	Type     string     `json:"type"`
-	Token    string     `json:"token"`
-	Username string     `json:"username"`
-	Password string     `json:"password"`
-	APIKey   string     `json:"api_key"`
-	Header   string     `json:"header"`
-	Expiry   *time.Time `json:"expiry"`
-	Active   bool       `json:"active"`
+	Name     string       `json:"name"`
+	Type     string       `json:"type"`
+	Token    string       `json:"token"`
+	Username string       `json:"username"`
+	Password string       `json:"password"`
+	APIKey   string       `json:"api_key"`
+	Header   string       `json:"header"`             // name of the header, query parameter or cookie holding the API key
+	Location string       `json:"location,omitempty"` // where the API key is sent: "header" (default), "query" or "cookie"
+	Expiry   *time.Time   `json:"expiry"`
+	Active   bool         `json:"active"`
+	OAuth    *OAuthConfig `json:"oauth,omitempty"`
+	Hosts    []string     `json:"hosts,omitempty"` // host patterns the profile is sent to, such as *.example.com; empty for any host
+
+	// AccessKey and SecretKey are the key ID and secret of "aws" and "hmac" profiles
+	AccessKey string       `json:"access_key,omitempty"`
+	SecretKey string       `json:"secret_key,omitempty"`
+	AWS       *AWSSigning  `json:"aws,omitempty"`
+	HMAC      *HMACSigning `json:"hmac,omitempty"`
+
+	// LoginTemplate is run to obtain a new token when the token expires, and LoginCapture
+	// extracts it from the response (default body.access_token)
+	LoginTemplate string `json:"login_template,omitempty"`
+	LoginCapture  string `json:"login_capture,omitempty"`
+}
+
+// OAuthConfig describes how an OAuth 2.0 profile obtains its access token, which is
+// cached in the profile's Token and Expiry
+type OAuthConfig struct {
+	Grant        string   `json:"grant"` // "client_credentials", "password" or "authorization_code"
+	TokenURL     string   `json:"token_url"`
+	AuthURL      string   `json:"auth_url,omitempty"` // authorization endpoint, for the authorization code grant
+	ClientID     string   `json:"client_id"`
+	ClientSecret string   `json:"client_secret,omitempty"`
+	ClientAuth   string   `json:"client_auth,omitempty"` // "basic" (default) or "body"
+	Scopes       []string `json:"scopes,omitempty"`
+	Audience     string   `json:"audience,omitempty"`
+	RedirectPort int      `json:"redirect_port,omitempty"` // loopback port for the authorization code grant, 0 for any
+	RefreshToken string   `json:"refresh_token,omitempty"`
 }
@@ -0,0 +1,14 @@
+package model
+
+// Collection is a folder of templates run in a fixed order. Its base URL, headers and
+// auth are inherited by the templates and sub-collections it contains.
+type Collection struct {
+	Name    string            `json:"name"`
+	Order   []string          `json:"order,omitempty"` // template and folder names; unlisted items run after, by name
+	BaseURL string            `json:"base_url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Auth    *Auth             `json:"auth,omitempty"`
+
+	Templates []Template   `json:"-"`
+	Folders   []Collection `json:"-"`
+}
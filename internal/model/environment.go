@@ -0,0 +1,8 @@
+package model
+
+// Environment is a named set of variables substituted into requests as {{name}}
+type Environment struct {
+	Name      string            `json:"name"`
+	Variables map[string]string `json:"variables"`
+	Active    bool              `json:"active"`
+}
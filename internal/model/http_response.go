@@ -1,11 +1,17 @@
 package model
 
+import "time"
+
 // HTTPResponse holds the response data and provides querying capabilities
 type HTTPResponse struct {
 	Status     string
 	StatusCode int
 	Headers    map[string]string
 	Body       []byte
+	// RawBody is the body exactly as received; Body is pretty-printed when it is JSON
+	RawBody    []byte
 	IsJSON     bool
 	ParsedJSON any
+	Duration   time.Duration
+	Size       int64
 }
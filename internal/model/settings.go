@@ -4,72 +4,86 @@ import "time"
 
 // DisplaySettings manages output formatting preferences
 type DisplaySettings struct {
-	ResponseFormat  string // "pretty-json", "raw", "headers-only", "compact-json"
-	ColorOutput     bool
-	ShowTiming      bool
-	ShowHeaders     bool
-	ShowStatusCode  bool
-	MaxResponseSize int // in KB, 0 = unlimited
-	IndentSize      int // for pretty formatting
-	LineNumbers     bool
-	SyntaxHighlight bool
+	ResponseFormat  string `json:"response_format" yaml:"response_format"` // "pretty-json", "raw", "headers-only", "compact-json"
+	ColorOutput     bool   `json:"color_output" yaml:"color_output"`
+	ShowTiming      bool   `json:"show_timing" yaml:"show_timing"`
+	ShowHeaders     bool   `json:"show_headers" yaml:"show_headers"`
+	ShowStatusCode  bool   `json:"show_status_code" yaml:"show_status_code"`
+	MaxResponseSize int    `json:"max_response_size" yaml:"max_response_size"` // in KB, 0 = unlimited
+	IndentSize      int    `json:"indent_size" yaml:"indent_size"`             // for pretty formatting
+	LineNumbers     bool   `json:"line_numbers" yaml:"line_numbers"`
+	SyntaxHighlight bool   `json:"syntax_highlight" yaml:"syntax_highlight"`
 }
 
 // BehaviorSettings manages application behavior
 type BehaviorSettings struct {
-	AutoSaveRequests       bool
-	ConfirmDeleteRequests  bool
-	ConfirmDestructive     bool
-	RequestTimeout         int // in seconds
-	MaxRetries             int
-	RetryDelay             int // in seconds
-	FollowRedirects        bool
-	MaxRedirects           int
-	ValidateSSL            bool
-	CacheResponses         bool
-	CacheDuration          int // in minutes
-	ShowProgressBar        bool
-	VerboseMode            bool
-	SaveFailedRequests     bool
-	AutoAddHeaders         bool
-	DefaultContentType     string
-	PreserveSessionCookies bool
+	AutoSaveRequests       bool   `json:"auto_save_requests" yaml:"auto_save_requests"`
+	ConfirmDeleteRequests  bool   `json:"confirm_delete_requests" yaml:"confirm_delete_requests"`
+	ConfirmDestructive     bool   `json:"confirm_destructive" yaml:"confirm_destructive"`
+	RequestTimeout         int    `json:"request_timeout" yaml:"request_timeout"` // in seconds
+	MaxRetries             int    `json:"max_retries" yaml:"max_retries"`
+	RetryDelay             int    `json:"retry_delay" yaml:"retry_delay"` // in seconds
+	FollowRedirects        bool   `json:"follow_redirects" yaml:"follow_redirects"`
+	MaxRedirects           int    `json:"max_redirects" yaml:"max_redirects"`
+	ValidateSSL            bool   `json:"validate_ssl" yaml:"validate_ssl"`
+	CacheResponses         bool   `json:"cache_responses" yaml:"cache_responses"`
+	CacheDuration          int    `json:"cache_duration" yaml:"cache_duration"` // in minutes
+	ShowProgressBar        bool   `json:"show_progress_bar" yaml:"show_progress_bar"`
+	VerboseMode            bool   `json:"verbose_mode" yaml:"verbose_mode"`
+	SaveFailedRequests     bool   `json:"save_failed_requests" yaml:"save_failed_requests"`
+	AutoAddHeaders         bool   `json:"auto_add_headers" yaml:"auto_add_headers"`
+	DefaultContentType     string `json:"default_content_type" yaml:"default_content_type"`
+	PreserveSessionCookies bool   `json:"preserve_session_cookies" yaml:"preserve_session_cookies"`
+	HistoryBodyLimit       int    `json:"history_body_limit" yaml:"history_body_limit"`   // in KB, 0 = no body snapshot
+	HistoryMaxEntries      int    `json:"history_max_entries" yaml:"history_max_entries"` // 0 = unlimited
+	HistoryMaxAge          int    `json:"history_max_age" yaml:"history_max_age"`         // in days, 0 = forever
 }
 
 // NetworkSettings manages connection preferences
 type NetworkSettings struct {
-	DefaultTimeout     int // in seconds
-	ConnectTimeout     int // in seconds
-	ReadTimeout        int // in seconds
-	WriteTimeout       int // in seconds
-	MaxConnections     int
-	UserAgent          string
-	ProxyURL           string
-	ProxyEnabled       bool
-	KeepAlive          bool
-	CompressionEnabled bool
+	DefaultTimeout     int    `json:"default_timeout" yaml:"default_timeout"` // in seconds
+	ConnectTimeout     int    `json:"connect_timeout" yaml:"connect_timeout"` // in seconds
+	ReadTimeout        int    `json:"read_timeout" yaml:"read_timeout"`       // in seconds
+	WriteTimeout       int    `json:"write_timeout" yaml:"write_timeout"`     // in seconds
+	MaxConnections     int    `json:"max_connections" yaml:"max_connections"`
+	UserAgent          string `json:"user_agent" yaml:"user_agent"`
+	ProxyURL           string `json:"proxy_url" yaml:"proxy_url"`
+	ProxyEnabled       bool   `json:"proxy_enabled" yaml:"proxy_enabled"`
+	KeepAlive          bool   `json:"keep_alive" yaml:"keep_alive"`
+	CompressionEnabled bool   `json:"compression_enabled" yaml:"compression_enabled"`
 }
 
 // LoggingSettings manages request/response logging
 type LoggingSettings struct {
-	EnableLogging  bool
-	LogLevel       string // "debug", "info", "warn", "error"
-	LogFile        string
-	LogRequests    bool
-	LogResponses   bool
-	LogHeaders     bool
-	LogTiming      bool
-	RotateLogFiles bool
-	MaxLogSize     int // in MB
-	MaxLogFiles    int
+	EnableLogging  bool   `json:"enable_logging" yaml:"enable_logging"`
+	LogLevel       string `json:"log_level" yaml:"log_level"` // "debug", "info", "warn", "error"
+	LogFile        string `json:"log_file" yaml:"log_file"`
+	LogRequests    bool   `json:"log_requests" yaml:"log_requests"`
+	LogResponses   bool   `json:"log_responses" yaml:"log_responses"`
+	LogHeaders     bool   `json:"log_headers" yaml:"log_headers"`
+	LogTiming      bool   `json:"log_timing" yaml:"log_timing"`
+	RotateLogFiles bool   `json:"rotate_log_files" yaml:"rotate_log_files"`
+	MaxLogSize     int    `json:"max_log_size" yaml:"max_log_size"` // in MB
+	MaxLogFiles    int    `json:"max_log_files" yaml:"max_log_files"`
+}
+
+// RedactionSettings controls which secrets are masked before requests are saved to history or templates
+type RedactionSettings struct {
+	RedactHistory   bool     `json:"redact_history" yaml:"redact_history"`
+	RedactTemplates bool     `json:"redact_templates" yaml:"redact_templates"`
+	Marker          string   `json:"marker" yaml:"marker"`
+	Headers         []string `json:"headers" yaml:"headers"`           // case-insensitive
+	QueryParams     []string `json:"query_params" yaml:"query_params"` // case-insensitive
+	BodyPaths       []string `json:"body_paths" yaml:"body_paths"`     // dotted JSON paths, "*" matches any key or index
 }
 
 // GlobalSettings holds all application settings
 type GlobalSettings struct {
-	Display   DisplaySettings
-	Behavior  BehaviorSettings
-	Network   NetworkSettings
-	Logging   LoggingSettings
-	Version   string
-	LastSaved time.Time
+	Display   DisplaySettings   `json:"display" yaml:"display"`
+	Behavior  BehaviorSettings  `json:"behavior" yaml:"behavior"`
+	Network   NetworkSettings   `json:"network" yaml:"network"`
+	Logging   LoggingSettings   `json:"logging" yaml:"logging"`
+	Redaction RedactionSettings `json:"redaction" yaml:"redaction"`
+	Version   string            `json:"version" yaml:"version"`
+	LastSaved time.Time         `json:"last_saved" yaml:"last_saved"`
 }
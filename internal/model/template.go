@@ -8,6 +8,9 @@ type Template struct {
 	Headers     map[string]string `json:"headers,omitempty"`
 	QueryParams map[string]string `json:"query_params,omitempty"`
 	Files       map[string]string `json:"files,omitempty"`
+	FormData    map[string]string `json:"form_data,omitempty"` // multipart text fields, sent alongside Files
 	Auth        *Auth             `json:"auth,omitempty"`
 	Body        any               `json:"body,omitempty"`
+	Captures    map[string]string `json:"captures,omitempty"` // variable name -> status, body.<path> or header.<name>
+	Assertions  []Assertion       `json:"assertions,omitempty"`
 }
@@ -0,0 +1,178 @@
+package runner
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Esa824/apix/internal/model"
+	"github.com/Esa824/apix/internal/utils"
+)
+
+// AssertionResult is the outcome of evaluating one assertion
+type AssertionResult struct {
+	Assertion model.Assertion
+	Passed    bool
+	Message   string
+}
+
+// Describe returns a readable form of an assertion, e.g. "status in 200-299"
+func Describe(assertion model.Assertion) string {
+	parts := []string{assertion.Type}
+	if assertion.Target != "" {
+		parts = append(parts, assertion.Target)
+	}
+	parts = append(parts, assertion.Operator)
+	if assertion.Value != "" {
+		parts = append(parts, assertion.Value)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Evaluate checks an assertion against a response
+func Evaluate(assertion model.Assertion, response *model.HTTPResponse) AssertionResult {
+	result := AssertionResult{Assertion: assertion}
+
+	passed, message := evaluate(assertion, response)
+	result.Passed = passed
+	if !passed {
+		result.Message = fmt.Sprintf("%s: %s", Describe(assertion), message)
+	}
+	return result
+}
+
+func evaluate(assertion model.Assertion, response *model.HTTPResponse) (bool, string) {
+	switch assertion.Type {
+	case "status":
+		return compare(assertion, strconv.Itoa(response.StatusCode), true, "status")
+	case "header":
+		value, found := findHeader(response.Headers, assertion.Target)
+		return compare(assertion, value, found, "header")
+	case "json":
+		value, err := utils.ExtractCapture(response, "body."+assertion.Target)
+		return compare(assertion, value, err == nil, "value")
+	case "body":
+		return compare(assertion, string(response.RawBody), true, "body")
+	case "response_time":
+		if assertion.Operator != "less_than" {
+			return false, fmt.Sprintf("unsupported operator %q for response_time (use less_than)", assertion.Operator)
+		}
+		limit, err := strconv.Atoi(assertion.Value)
+		if err != nil {
+			return false, fmt.Sprintf("invalid limit %q, expected milliseconds", assertion.Value)
+		}
+		if response.Duration < time.Duration(limit)*time.Millisecond {
+			return true, ""
+		}
+		return false, fmt.Sprintf("took %dms", response.Duration.Milliseconds())
+	default:
+		return false, fmt.Sprintf("unknown assertion type %q", assertion.Type)
+	}
+}
+
+// compare applies the assertion operator to an actual value
+func compare(assertion model.Assertion, actual string, found bool, subject string) (bool, string) {
+	if assertion.Operator == "exists" {
+		if found {
+			return true, ""
+		}
+		return false, fmt.Sprintf("%s not found", subject)
+	}
+
+	if !found {
+		return false, fmt.Sprintf("%s not found", subject)
+	}
+
+	switch assertion.Operator {
+	case "equals":
+		if actual == assertion.Value {
+			return true, ""
+		}
+	case "contains":
+		if strings.Contains(actual, assertion.Value) {
+			return true, ""
+		}
+	case "matches":
+		re, err := regexp.Compile(assertion.Value)
+		if err != nil {
+			return false, fmt.Sprintf("invalid pattern: %v", err)
+		}
+		if re.MatchString(actual) {
+			return true, ""
+		}
+	case "in":
+		code, err := strconv.Atoi(actual)
+		if err != nil {
+			return false, fmt.Sprintf("%s %q is not a number", subject, actual)
+		}
+		ok, err := inRange(code, assertion.Value)
+		if err != nil {
+			return false, err.Error()
+		}
+		if ok {
+			return true, ""
+		}
+	default:
+		return false, fmt.Sprintf("unsupported operator %q", assertion.Operator)
+	}
+
+	return false, fmt.Sprintf("got %s", truncate(actual, 200))
+}
+
+// inRange reports whether value is in a set such as "200-299", "2xx" or "200,201,204"
+func inRange(value int, set string) (bool, error) {
+	for _, part := range strings.Split(set, ",") {
+		part = strings.TrimSpace(part)
+
+		if len(part) == 3 && strings.HasSuffix(strings.ToLower(part), "xx") {
+			class, err := strconv.Atoi(part[:1])
+			if err != nil {
+				return false, fmt.Errorf("invalid range %q", part)
+			}
+			if value/100 == class {
+				return true, nil
+			}
+			continue
+		}
+
+		if low, high, found := strings.Cut(part, "-"); found {
+			lowValue, lowErr := strconv.Atoi(strings.TrimSpace(low))
+			highValue, highErr := strconv.Atoi(strings.TrimSpace(high))
+			if lowErr != nil || highErr != nil {
+				return false, fmt.Errorf("invalid range %q", part)
+			}
+			if value >= lowValue && value <= highValue {
+				return true, nil
+			}
+			continue
+		}
+
+		exact, err := strconv.Atoi(part)
+		if err != nil {
+			return false, fmt.Errorf("invalid range %q", part)
+		}
+		if value == exact {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// findHeader looks up a response header ignoring case
+func findHeader(headers map[string]string, name string) (string, bool) {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "..."
+}
@@ -0,0 +1,74 @@
+package runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+func TestEvaluate(t *testing.T) {
+	response := &model.HTTPResponse{
+		StatusCode: 201,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       []byte("{\n  \"id\": 9007199254740993,\n  \"name\": \"Ada\"\n}"),
+		RawBody:    []byte(`{"id":9007199254740993,"name":"Ada"}`),
+		IsJSON:     true,
+		Duration:   120 * time.Millisecond,
+	}
+
+	tests := []struct {
+		assertion model.Assertion
+		passed    bool
+	}{
+		{assertion: model.Assertion{Type: "status", Operator: "equals", Value: "201"}, passed: true},
+		{assertion: model.Assertion{Type: "status", Operator: "in", Value: "2xx"}, passed: true},
+		{assertion: model.Assertion{Type: "status", Operator: "in", Value: "200,204"}, passed: false},
+		{assertion: model.Assertion{Type: "status", Operator: "in", Value: "200-299"}, passed: true},
+		{assertion: model.Assertion{Type: "header", Target: "content-type", Operator: "contains", Value: "json"}, passed: true},
+		{assertion: model.Assertion{Type: "header", Target: "X-Missing", Operator: "exists"}, passed: false},
+		{assertion: model.Assertion{Type: "json", Target: "id", Operator: "equals", Value: "9007199254740993"}, passed: true},
+		{assertion: model.Assertion{Type: "json", Target: "name", Operator: "matches", Value: "^A"}, passed: true},
+		{assertion: model.Assertion{Type: "json", Target: "email", Operator: "exists"}, passed: false},
+		{assertion: model.Assertion{Type: "body", Operator: "contains", Value: `"name":"Ada"`}, passed: true},
+		{assertion: model.Assertion{Type: "body", Operator: "equals", Value: `{"id":9007199254740993,"name":"Ada"}`}, passed: true},
+		{assertion: model.Assertion{Type: "body", Operator: "matches", Value: `^\{"id"`}, passed: true},
+		{assertion: model.Assertion{Type: "response_time", Operator: "less_than", Value: "500"}, passed: true},
+		{assertion: model.Assertion{Type: "response_time", Operator: "less_than", Value: "100"}, passed: false},
+		{assertion: model.Assertion{Type: "response_time", Operator: "equals", Value: "100"}, passed: false},
+		{assertion: model.Assertion{Type: "cookie", Operator: "exists"}, passed: false},
+	}
+
+	for _, tt := range tests {
+		result := Evaluate(tt.assertion, response)
+		if result.Passed != tt.passed {
+			t.Errorf("%s: passed = %v, want %v (%s)", Describe(tt.assertion), result.Passed, tt.passed, result.Message)
+		}
+		if !result.Passed && result.Message == "" {
+			t.Errorf("%s: failed without a message", Describe(tt.assertion))
+		}
+	}
+}
+
+func TestInRange(t *testing.T) {
+	tests := []struct {
+		value   int
+		set     string
+		want    bool
+		wantErr bool
+	}{
+		{value: 204, set: "2xx", want: true},
+		{value: 404, set: "2xx, 3xx", want: false},
+		{value: 301, set: "200-299,300-399", want: true},
+		{value: 201, set: "200, 201", want: true},
+		{value: 200, set: "abc", wantErr: true},
+		{value: 200, set: "200-x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := inRange(tt.value, tt.set)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("inRange(%d, %q) = %v, %v, want %v", tt.value, tt.set, got, err, tt.want)
+		}
+	}
+}
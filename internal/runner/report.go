@@ -0,0 +1,293 @@
+package runner
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Supported report formats
+const (
+	ReporterText  = "text"
+	ReporterJUnit = "junit"
+	ReporterTAP   = "tap"
+	ReporterJSON  = "json"
+)
+
+// Reporters lists the accepted values for --reporter
+var Reporters = []string{ReporterText, ReporterJUnit, ReporterTAP, ReporterJSON}
+
+// WriteReport writes the summary in the given report format
+func WriteReport(w io.Writer, reporter string, summary Summary) error {
+	switch reporter {
+	case ReporterText:
+		WriteSummary(w, summary)
+		return nil
+	case ReporterJUnit:
+		return writeJUnit(w, summary)
+	case ReporterTAP:
+		return writeTAP(w, summary)
+	case ReporterJSON:
+		return writeJSON(w, summary)
+	default:
+		return fmt.Errorf("unknown reporter %q (use %s)", reporter, strings.Join(Reporters, ", "))
+	}
+}
+
+// WriteSummary prints a pass/fail line per template followed by the totals
+func WriteSummary(w io.Writer, summary Summary) {
+	for _, result := range summary.Results {
+		label := "PASS"
+		if !result.Passed() {
+			label = "FAIL"
+		}
+
+		line := fmt.Sprintf("%s  %s (%s %s)", label, result.Name, result.Method, result.URL)
+		if result.Status != "" {
+			line += fmt.Sprintf(" %s in %s", result.Status, formatDuration(result.Duration))
+		}
+		fmt.Fprintln(w, line)
+
+		for _, failure := range result.Failures() {
+			fmt.Fprintf(w, "      %s\n", strings.ReplaceAll(failure, "\n", "\n      "))
+		}
+	}
+
+	fmt.Fprintf(w, "\n%d passed, %d failed, %d total in %s\n",
+		summary.Passed, summary.Failed, len(summary.Results), formatDuration(summary.Duration))
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return fmt.Sprintf("%.2fs", d.Seconds())
+}
+
+// JUnit XML
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func writeJUnit(w io.Writer, summary Summary) error {
+	suite := junitTestSuite{
+		Name:      "apix",
+		Tests:     len(summary.Results),
+		Time:      junitSeconds(summary.Duration),
+		Timestamp: summary.StartedAt.Format("2006-01-02T15:04:05"),
+	}
+
+	for _, result := range summary.Results {
+		testCase := junitTestCase{
+			Name:      result.Name,
+			Classname: fmt.Sprintf("%s %s", result.Method, result.URL),
+			Time:      junitSeconds(result.Duration),
+		}
+		if result.Status != "" {
+			testCase.SystemOut = fmt.Sprintf("Status: %s", result.Status)
+		}
+
+		failures := result.Failures()
+		switch {
+		case result.Error != "":
+			suite.Errors++
+			testCase.Error = &junitFailure{Message: result.Error, Type: "RequestError", Text: result.Error}
+		case len(failures) > 0:
+			suite.Failures++
+			testCase.Failure = &junitFailure{
+				Message: fmt.Sprintf("%d assertion(s) failed", len(failures)),
+				Type:    "AssertionError",
+				Text:    strings.Join(failures, "\n"),
+			}
+		}
+
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	report := junitTestSuites{
+		Name:     "apix",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JUnit report: %w", err)
+	}
+
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, data)
+	return err
+}
+
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// TAP version 13
+
+type tapDiagnostics struct {
+	Method     string   `yaml:"method"`
+	URL        string   `yaml:"url"`
+	Status     string   `yaml:"status,omitempty"`
+	DurationMs int64    `yaml:"duration_ms"`
+	Failures   []string `yaml:"failures,omitempty"`
+}
+
+func writeTAP(w io.Writer, summary Summary) error {
+	fmt.Fprintln(w, "TAP version 13")
+	fmt.Fprintf(w, "1..%d\n", len(summary.Results))
+
+	for i, result := range summary.Results {
+		status := "ok"
+		if !result.Passed() {
+			status = "not ok"
+		}
+		fmt.Fprintf(w, "%s %d - %s\n", status, i+1, result.Name)
+
+		diagnostics, err := yaml.Marshal(tapDiagnostics{
+			Method:     result.Method,
+			URL:        result.URL,
+			Status:     result.Status,
+			DurationMs: result.Duration.Milliseconds(),
+			Failures:   result.Failures(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal TAP diagnostics: %w", err)
+		}
+
+		fmt.Fprintln(w, "  ---")
+		for _, line := range strings.Split(strings.TrimRight(string(diagnostics), "\n"), "\n") {
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+		fmt.Fprintln(w, "  ...")
+	}
+
+	fmt.Fprintf(w, "# pass %d\n# fail %d\n", summary.Passed, summary.Failed)
+	return nil
+}
+
+// JSON
+
+type jsonReport struct {
+	Summary jsonSummary  `json:"summary"`
+	Results []jsonResult `json:"results"`
+}
+
+type jsonSummary struct {
+	Total      int       `json:"total"`
+	Passed     int       `json:"passed"`
+	Failed     int       `json:"failed"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs int64     `json:"duration_ms"`
+}
+
+type jsonResult struct {
+	Name       string          `json:"name"`
+	Method     string          `json:"method"`
+	URL        string          `json:"url"`
+	Status     string          `json:"status,omitempty"`
+	StatusCode int             `json:"status_code,omitempty"`
+	DurationMs int64           `json:"duration_ms"`
+	Passed     bool            `json:"passed"`
+	Error      string          `json:"error,omitempty"`
+	Assertions []jsonAssertion `json:"assertions"`
+	Failures   []string        `json:"failures,omitempty"`
+}
+
+type jsonAssertion struct {
+	Type     string `json:"type"`
+	Target   string `json:"target,omitempty"`
+	Operator string `json:"operator,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Passed   bool   `json:"passed"`
+	Message  string `json:"message,omitempty"`
+}
+
+func writeJSON(w io.Writer, summary Summary) error {
+	report := jsonReport{
+		Summary: jsonSummary{
+			Total:      len(summary.Results),
+			Passed:     summary.Passed,
+			Failed:     summary.Failed,
+			StartedAt:  summary.StartedAt,
+			DurationMs: summary.Duration.Milliseconds(),
+		},
+		Results: make([]jsonResult, 0, len(summary.Results)),
+	}
+
+	for _, result := range summary.Results {
+		entry := jsonResult{
+			Name:       result.Name,
+			Method:     result.Method,
+			URL:        result.URL,
+			Status:     result.Status,
+			StatusCode: result.StatusCode,
+			DurationMs: result.Duration.Milliseconds(),
+			Passed:     result.Passed(),
+			Error:      result.Error,
+			Assertions: make([]jsonAssertion, 0, len(result.Assertions)),
+			Failures:   result.Failures(),
+		}
+
+		for _, assertion := range result.Assertions {
+			entry.Assertions = append(entry.Assertions, jsonAssertion{
+				Type:     assertion.Assertion.Type,
+				Target:   assertion.Assertion.Target,
+				Operator: assertion.Assertion.Operator,
+				Value:    assertion.Assertion.Value,
+				Passed:   assertion.Passed,
+				Message:  assertion.Message,
+			})
+		}
+
+		report.Results = append(report.Results, entry)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON report: %w", err)
+	}
+
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
@@ -0,0 +1,232 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/Esa824/apix/internal/model"
+)
+
+// testSummary has one passing template, one with a failed assertion and one whose request failed
+func testSummary() Summary {
+	return Summary{
+		Results: []Result{
+			{
+				Name: "list users", Method: "GET", URL: "http://api/users",
+				Status: "200 OK", StatusCode: 200, Duration: 120 * time.Millisecond,
+				Assertions: []AssertionResult{
+					{Assertion: model.Assertion{Type: "status", Operator: "equals", Value: "200"}, Passed: true},
+				},
+			},
+			{
+				Name: "create user", Method: "POST", URL: "http://api/users",
+				Status: "500 Internal Server Error", StatusCode: 500, Duration: 80 * time.Millisecond,
+				Assertions: []AssertionResult{
+					{Assertion: model.Assertion{Type: "status", Operator: "in", Value: "2xx"}, Message: "status: expected 2xx, got 500"},
+					{Assertion: model.Assertion{Type: "header", Target: "Location", Operator: "exists"}, Passed: true},
+				},
+			},
+			{
+				Name: "delete user", Method: "DELETE", URL: "http://api/users/1",
+				Error: "request failed: connection refused",
+			},
+		},
+		Passed:    1,
+		Failed:    2,
+		StartedAt: time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC),
+		Duration:  1500 * time.Millisecond,
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteReport(&out, ReporterJUnit, testSummary()); err != nil {
+		t.Fatal(err)
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("report is not valid XML: %v\n%s", err, out.String())
+	}
+	if report.Tests != 3 || report.Failures != 1 || report.Errors != 1 || report.Time != "1.500" {
+		t.Errorf("testsuites tests=%d failures=%d errors=%d time=%s, want 3, 1, 1 and 1.500",
+			report.Tests, report.Failures, report.Errors, report.Time)
+	}
+	if len(report.Suites) != 1 {
+		t.Fatalf("got %d test suites, want 1", len(report.Suites))
+	}
+
+	suite := report.Suites[0]
+	if suite.Tests != 3 || suite.Failures != 1 || suite.Errors != 1 || len(suite.Cases) != 3 {
+		t.Fatalf("testsuite tests=%d failures=%d errors=%d cases=%d, want 3, 1, 1 and 3",
+			suite.Tests, suite.Failures, suite.Errors, len(suite.Cases))
+	}
+	if suite.Timestamp != "2024-05-01T09:30:00" {
+		t.Errorf("timestamp = %q", suite.Timestamp)
+	}
+
+	tests := []struct {
+		name      string
+		classname string
+		failure   string
+		error     string
+	}{
+		{name: "list users", classname: "GET http://api/users"},
+		{name: "create user", classname: "POST http://api/users", failure: "1 assertion(s) failed"},
+		{name: "delete user", classname: "DELETE http://api/users/1", error: "request failed: connection refused"},
+	}
+	for i, tt := range tests {
+		testCase := suite.Cases[i]
+		if testCase.Name != tt.name || testCase.Classname != tt.classname {
+			t.Errorf("case %d = %q (%q), want %q (%q)", i, testCase.Name, testCase.Classname, tt.name, tt.classname)
+		}
+		if got := failureMessage(testCase.Failure); got != tt.failure {
+			t.Errorf("%s: failure = %q, want %q", tt.name, got, tt.failure)
+		}
+		if got := failureMessage(testCase.Error); got != tt.error {
+			t.Errorf("%s: error = %q, want %q", tt.name, got, tt.error)
+		}
+	}
+	if text := suite.Cases[1].Failure.Text; text != "status: expected 2xx, got 500" {
+		t.Errorf("failure text = %q", text)
+	}
+}
+
+func failureMessage(failure *junitFailure) string {
+	if failure == nil {
+		return ""
+	}
+	return failure.Message
+}
+
+func TestWriteTAP(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteReport(&out, ReporterTAP, testSummary()); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+
+	if lines[0] != "TAP version 13" || lines[1] != "1..3" {
+		t.Fatalf("report starts with %q, want the version and the plan 1..3", lines[:2])
+	}
+	if tail := lines[len(lines)-2:]; !slices.Equal(tail, []string{"# pass 1", "# fail 2"}) {
+		t.Errorf("report ends with %q", tail)
+	}
+
+	// Each test line is followed by a YAML block indented by two spaces between --- and ...
+	var points []string
+	var diagnostics []tapDiagnostics
+	for i := 2; i < len(lines)-2; i++ {
+		points = append(points, lines[i])
+		if lines[i+1] != "  ---" {
+			t.Fatalf("line %d is not followed by a YAML block: %q", i+1, lines[i+1])
+		}
+		end := slices.Index(lines[i+1:], "  ...")
+		if end < 0 {
+			t.Fatalf("YAML block after line %d is not closed", i+1)
+		}
+
+		var block strings.Builder
+		for _, line := range lines[i+2 : i+1+end] {
+			block.WriteString(strings.TrimPrefix(line, "  ") + "\n")
+		}
+		var parsed tapDiagnostics
+		if err := yaml.Unmarshal([]byte(block.String()), &parsed); err != nil {
+			t.Fatalf("invalid YAML diagnostics for %q: %v", lines[i], err)
+		}
+		diagnostics = append(diagnostics, parsed)
+		i += 1 + end
+	}
+
+	wantPoints := []string{"ok 1 - list users", "not ok 2 - create user", "not ok 3 - delete user"}
+	if !slices.Equal(points, wantPoints) {
+		t.Errorf("test points = %q, want %q", points, wantPoints)
+	}
+	wantDiagnostics := []tapDiagnostics{
+		{Method: "GET", URL: "http://api/users", Status: "200 OK", DurationMs: 120},
+		{Method: "POST", URL: "http://api/users", Status: "500 Internal Server Error", DurationMs: 80, Failures: []string{"status: expected 2xx, got 500"}},
+		{Method: "DELETE", URL: "http://api/users/1", Failures: []string{"request failed: connection refused"}},
+	}
+	for i, want := range wantDiagnostics {
+		if i >= len(diagnostics) {
+			break
+		}
+		got := diagnostics[i]
+		if got.Method != want.Method || got.URL != want.URL || got.Status != want.Status ||
+			got.DurationMs != want.DurationMs || !slices.Equal(got.Failures, want.Failures) {
+			t.Errorf("diagnostics %d = %+v, want %+v", i+1, got, want)
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteReport(&out, ReporterJSON, testSummary()); err != nil {
+		t.Fatal(err)
+	}
+
+	var report map[string]any
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("report is not valid JSON: %v", err)
+	}
+	assertKeys(t, "report", report, "summary", "results")
+
+	summary := report["summary"].(map[string]any)
+	assertKeys(t, "summary", summary, "total", "passed", "failed", "started_at", "duration_ms")
+	if summary["total"] != 3.0 || summary["passed"] != 1.0 || summary["failed"] != 2.0 ||
+		summary["duration_ms"] != 1500.0 || summary["started_at"] != "2024-05-01T09:30:00Z" {
+		t.Errorf("summary = %v", summary)
+	}
+
+	results := report["results"].([]any)
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+
+	passed := results[0].(map[string]any)
+	assertKeys(t, "passing result", passed, "name", "method", "url", "status", "status_code", "duration_ms", "passed", "assertions")
+	if passed["passed"] != true || passed["status_code"] != 200.0 {
+		t.Errorf("passing result = %v", passed)
+	}
+	assertion := passed["assertions"].([]any)[0].(map[string]any)
+	assertKeys(t, "assertion", assertion, "type", "operator", "value", "passed")
+
+	failed := results[1].(map[string]any)
+	assertKeys(t, "failing result", failed, "name", "method", "url", "status", "status_code", "duration_ms", "passed", "assertions", "failures")
+	if failed["passed"] != false || len(failed["assertions"].([]any)) != 2 {
+		t.Errorf("failing result = %v", failed)
+	}
+
+	errored := results[2].(map[string]any)
+	assertKeys(t, "errored result", errored, "name", "method", "url", "duration_ms", "passed", "error", "assertions", "failures")
+	if assertions, ok := errored["assertions"].([]any); !ok || len(assertions) != 0 {
+		t.Errorf("assertions of a failed request = %v, want an empty list", errored["assertions"])
+	}
+}
+
+// assertKeys checks that object has exactly the given keys
+func assertKeys(t *testing.T, name string, object map[string]any, keys ...string) {
+	t.Helper()
+	var got []string
+	for key := range object {
+		got = append(got, key)
+	}
+	slices.Sort(got)
+	slices.Sort(keys)
+	if !slices.Equal(got, keys) {
+		t.Errorf("%s has keys %v, want %v", name, got, keys)
+	}
+}
+
+func TestWriteReportUnknownReporter(t *testing.T) {
+	if err := WriteReport(&bytes.Buffer{}, "xunit", testSummary()); err == nil {
+		t.Error("WriteReport accepted an unknown reporter")
+	}
+}
@@ -0,0 +1,128 @@
+package runner
+
+import (
+	"errors"
+	"time"
+
+	hc "github.com/Esa824/apix/internal/http-client"
+	"github.com/Esa824/apix/internal/model"
+	"github.com/Esa824/apix/internal/utils"
+)
+
+// Result is the outcome of running one template
+type Result struct {
+	Name       string
+	Method     string
+	URL        string
+	Status     string
+	StatusCode int
+	Duration   time.Duration
+	Assertions []AssertionResult
+	Error      string
+}
+
+// Passed reports whether the request succeeded and every assertion held
+func (r Result) Passed() bool {
+	if r.Error != "" {
+		return false
+	}
+	for _, assertion := range r.Assertions {
+		if !assertion.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// Failures returns the messages of the failed assertions, or the request error
+func (r Result) Failures() []string {
+	if r.Error != "" {
+		return []string{r.Error}
+	}
+
+	var failures []string
+	for _, assertion := range r.Assertions {
+		if !assertion.Passed {
+			failures = append(failures, assertion.Message)
+		}
+	}
+	return failures
+}
+
+// Summary collects the results of a test run
+type Summary struct {
+	Results   []Result
+	Passed    int
+	Failed    int
+	StartedAt time.Time
+	Duration  time.Duration
+}
+
+// Run executes templates in order. With failFast it stops after the first failure.
+func Run(client *hc.Client, templates []model.Template, failFast bool) Summary {
+	start := time.Now()
+	summary := Summary{StartedAt: start}
+
+	for _, template := range templates {
+		result := RunTemplate(client, template)
+		summary.Results = append(summary.Results, result)
+
+		if result.Passed() {
+			summary.Passed++
+		} else {
+			summary.Failed++
+			if failFast {
+				break
+			}
+		}
+	}
+
+	summary.Duration = time.Since(start)
+	return summary
+}
+
+// RunTemplate executes a template, stores its captures and evaluates its assertions
+func RunTemplate(client *hc.Client, template model.Template) Result {
+	options := hc.TemplateRequestOptions(template)
+	result := Result{
+		Name:   template.Name,
+		Method: template.Method,
+		URL:    template.URL,
+	}
+
+	if resolved, err := hc.ResolveVariables(options); err == nil {
+		result.URL = resolved.URL
+	}
+
+	response, err := client.Do(options, false)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	httpResp := utils.ParseResponse(response)
+	result.Status = httpResp.Status
+	result.StatusCode = httpResp.StatusCode
+	result.Duration = httpResp.Duration
+
+	for _, assertion := range template.Assertions {
+		result.Assertions = append(result.Assertions, Evaluate(assertion, httpResp))
+	}
+
+	// A failed capture would break the requests that depend on it, so it fails this one
+	if len(template.Captures) > 0 {
+		values, captureErr := utils.ExtractCaptures(httpResp, template.Captures)
+		// Without an active environment the values still reach the rest of this run
+		if err := hc.SetVariables(values); err != nil && !errors.Is(err, hc.ErrNoActiveEnvironment) && captureErr == nil {
+			captureErr = err
+		}
+		if captureErr != nil {
+			result.Assertions = append(result.Assertions, AssertionResult{
+				Assertion: model.Assertion{Type: "capture"},
+				Message:   "capture: " + captureErr.Error(),
+			})
+		}
+	}
+
+	return result
+}
@@ -3,8 +3,14 @@ package utils
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -367,6 +373,21 @@ func ValidateURL(url string) error {
 	return nil
 }
 
+// ValidateHTTPMethod checks that a method is a valid HTTP token (RFC 9110)
+func ValidateHTTPMethod(method string) error {
+	if method == "" {
+		return fmt.Errorf("HTTP method cannot be empty")
+	}
+
+	for _, r := range method {
+		if r > 127 || !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || strings.ContainsRune("!#$%&'*+-.^_`|~", r)) {
+			return fmt.Errorf("invalid character %q in HTTP method %q", r, method)
+		}
+	}
+
+	return nil
+}
+
 // ValidateEmail checks if a string is a valid email format
 func ValidateEmail(email string) error {
 	if email == "" {
@@ -451,6 +472,50 @@ func DisplayFormattedText(title, content string) {
 	form.Run()
 }
 
+// clipboardCommands are tried in order to copy text to the system clipboard
+var clipboardCommands = [][]string{
+	{"pbcopy"},
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+	{"clip.exe"},
+}
+
+// CopyToClipboard copies text using the first available clipboard tool
+func CopyToClipboard(text string) error {
+	for _, command := range clipboardCommands {
+		if _, err := exec.LookPath(command[0]); err != nil {
+			continue
+		}
+
+		cmd := exec.Command(command[0], command[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to copy to clipboard: %w", err)
+		}
+		return nil
+	}
+	return errors.New("no clipboard tool found (install pbcopy, wl-copy, xclip or xsel)")
+}
+
+// OpenBrowser opens url in the default web browser
+func OpenBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open browser: %w", err)
+	}
+	go cmd.Wait()
+	return nil
+}
+
 // =============================================================================
 // TIME UTILITIES - Time input and formatting helpers
 // =============================================================================
@@ -603,6 +668,74 @@ func ExecuteJSONQuery(data any, query string) any {
 	}
 }
 
+// ExtractCapture evaluates a capture expression against a response. Supported expressions are
+// "status", "body", "body.<gjson path>" and "header.<Name>".
+func ExtractCapture(response *model.HTTPResponse, expression string) (string, error) {
+	source, path, _ := strings.Cut(strings.TrimSpace(expression), ".")
+
+	switch source {
+	case "status":
+		return strconv.Itoa(response.StatusCode), nil
+	case "header", "headers":
+		if path == "" {
+			return "", fmt.Errorf("header capture needs a name, e.g. header.Location")
+		}
+		for key, value := range response.Headers {
+			if strings.EqualFold(key, path) {
+				return value, nil
+			}
+		}
+		return "", fmt.Errorf("header %s not found in response", path)
+	case "body":
+		if path == "" {
+			return string(response.RawBody), nil
+		}
+		if !response.IsJSON {
+			return "", fmt.Errorf("cannot evaluate %s: response body is not JSON", expression)
+		}
+		// Queried on the raw body so numbers keep their exact digits (IDs above 2^53 lose precision as float64)
+		result := gjson.GetBytes(response.RawBody, path)
+		if !result.Exists() || result.Type == gjson.Null {
+			return "", fmt.Errorf("no value at %s", expression)
+		}
+		return captureString(result), nil
+	default:
+		return "", fmt.Errorf("invalid capture %q (use status, body.<path> or header.<name>)", expression)
+	}
+}
+
+// ExtractCaptures evaluates each name = expression capture, returning the values that
+// could be extracted along with an error describing the ones that could not
+func ExtractCaptures(response *model.HTTPResponse, captures map[string]string) (map[string]string, error) {
+	values := make(map[string]string, len(captures))
+	var errs []error
+
+	for name, expression := range captures {
+		value, err := ExtractCapture(response, expression)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		values[name] = value
+	}
+
+	return values, errors.Join(errs...)
+}
+
+// captureString converts a JSON query result into a variable value. Strings are unquoted;
+// numbers, booleans, objects and arrays keep their JSON text.
+func captureString(result gjson.Result) string {
+	if result.Type == gjson.String {
+		return result.String()
+	}
+
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, []byte(result.Raw)); err != nil {
+		return result.Raw
+	}
+	return compacted.String()
+}
+
 func handleFieldAccess(data any, field string) any {
 	switch obj := data.(type) {
 	case map[string]any:
@@ -633,10 +766,29 @@ func ParseResponse(response any) *model.HTTPResponse {
 	httpResp := &model.HTTPResponse{
 		Status:  status,
 		Body:    formatted,
+		RawBody: body,
 		IsJSON:  isJSON,
 		Headers: make(map[string]string),
 	}
 
+	if resp, ok := response.(interface{ StatusCode() int }); ok {
+		httpResp.StatusCode = resp.StatusCode()
+	}
+
+	if resp, ok := response.(interface{ Header() http.Header }); ok {
+		for key, values := range resp.Header() {
+			httpResp.Headers[key] = strings.Join(values, ", ")
+		}
+	}
+
+	if resp, ok := response.(interface{ Time() time.Duration }); ok {
+		httpResp.Duration = resp.Time()
+	}
+
+	if resp, ok := response.(interface{ Size() int64 }); ok {
+		httpResp.Size = resp.Size()
+	}
+
 	if isJSON {
 		json.Unmarshal(formatted, &httpResp.ParsedJSON)
 	}
@@ -645,15 +797,23 @@ func ParseResponse(response any) *model.HTTPResponse {
 }
 
 // Display Utilities
-func DisplayResponse(response *model.HTTPResponse) {
-	if string(response.Body) == "" {
-		response.Body = []byte("Not set")
+
+// FormatResponseText renders a response as plain text for display
+func FormatResponseText(response *model.HTTPResponse) string {
+	body := string(response.Body)
+	if body == "" {
+		body = "Not set"
 	}
-	responseText := fmt.Sprintf("Status: %s\n\nBody:\n%s",
-		response.Status,
-		string(response.Body))
+	return fmt.Sprintf("Status: %s\n\nBody:\n%s", response.Status, body)
+}
+
+func DisplayResponse(response *model.HTTPResponse) {
+	DisplayFormattedText("🌐 HTTP Response", FormatResponseText(response))
+}
 
-	DisplayFormattedText("🌐 HTTP Response", responseText)
+// WriteResponse prints a response to w using the same layout as DisplayResponse
+func WriteResponse(w io.Writer, response *model.HTTPResponse) {
+	fmt.Fprintln(w, FormatResponseText(response))
 }
 
 func DisplayQueryResult(query string, result any) {
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"net/http"
+	"testing"
+)
+
+// fakeResponse implements the accessors ParseResponse reads from a resty response
+type fakeResponse struct {
+	status int
+	header http.Header
+	body   string
+}
+
+func (r fakeResponse) Body() []byte        { return []byte(r.body) }
+func (r fakeResponse) Status() string      { return http.StatusText(r.status) }
+func (r fakeResponse) StatusCode() int     { return r.status }
+func (r fakeResponse) Header() http.Header { return r.header }
+
+func TestExtractCapture(t *testing.T) {
+	response := ParseResponse(fakeResponse{
+		status: 201,
+		header: http.Header{"Location": {"/users/9007199254740993"}},
+		body:   `{"id": 9007199254740993, "price": 19.99, "name": "Ada", "admin": false, "tags": ["a", "b"], "owner": {"id": 1}, "deleted": null}`,
+	})
+
+	tests := []struct {
+		expression string
+		want       string
+		wantErr    bool
+	}{
+		{expression: "status", want: "201"},
+		{expression: "header.location", want: "/users/9007199254740993"},
+		{expression: "body.id", want: "9007199254740993"},
+		{expression: "body.price", want: "19.99"},
+		{expression: "body.name", want: "Ada"},
+		{expression: "body.admin", want: "false"},
+		{expression: "body.tags", want: `["a","b"]`},
+		{expression: "body.tags.1", want: "b"},
+		{expression: "body.owner", want: `{"id":1}`},
+		{expression: "body.owner.id", want: "1"},
+		{expression: "body.deleted", wantErr: true},
+		{expression: "body.missing", wantErr: true},
+		{expression: "header.X-Missing", wantErr: true},
+		{expression: "header", wantErr: true},
+		{expression: "cookie.session", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ExtractCapture(response, tt.expression)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ExtractCapture(%q) = %q, want an error", tt.expression, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ExtractCapture(%q) = %q, %v, want %q", tt.expression, got, err, tt.want)
+		}
+	}
+}
+
+func TestExtractCaptureNonJSON(t *testing.T) {
+	response := ParseResponse(fakeResponse{status: 200, body: "plain text"})
+
+	if got, err := ExtractCapture(response, "body"); err != nil || got != "plain text" {
+		t.Errorf("ExtractCapture(body) = %q, %v", got, err)
+	}
+	if _, err := ExtractCapture(response, "body.id"); err == nil {
+		t.Error("ExtractCapture(body.id) on a text body should fail")
+	}
+}
+
+func TestExtractCaptures(t *testing.T) {
+	response := ParseResponse(fakeResponse{status: 200, body: `{"token": "abc"}`})
+
+	values, err := ExtractCaptures(response, map[string]string{"token": "body.token", "user": "body.user"})
+	if values["token"] != "abc" {
+		t.Errorf("token = %q, want abc", values["token"])
+	}
+	if _, ok := values["user"]; ok || err == nil {
+		t.Errorf("a missing capture should be reported and left out, got %v, %v", values, err)
+	}
+}